@@ -0,0 +1,29 @@
+package cloudns
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// API is the part of the ClouDNS API a Provider works with. *Client is the
+// implementation talking to ClouDNS, and WithAPI replaces it, e.g. with a
+// mock to unit test the staging and retry logic of the Provider.
+type API interface {
+	GetClouDNSRecords(ctx context.Context, zone string) ([]ApiDnsRecord, error)
+	GetFilteredClouDNSRecords(ctx context.Context, zone string, filter RecordFilter) ([]ApiDnsRecord, error)
+	GetAvailableTTLs(ctx context.Context, zone string) ([]int, error)
+	GetAvailableRecordTypes(ctx context.Context, zone string) ([]string, error)
+	AddClouDNSRecord(ctx context.Context, zone string, record ApiDnsRecord) (ApiDnsRecord, error)
+	UpdateRecord(ctx context.Context, zone string, record ApiDnsRecord) (libdns.Record, error)
+	DeleteRecord(ctx context.Context, zone string, recordId string) error
+	ChangeRecordStatus(ctx context.Context, zone string, recordId string, active bool) error
+
+	ListZones(ctx context.Context, opts ListZonesOptions) ([]Zone, error)
+	FindZone(ctx context.Context, fqdn string) (string, error)
+	CreateZone(ctx context.Context, zone string, opts CreateZoneOptions) error
+	IsUpdated(ctx context.Context, zone string) (bool, error)
+}
+
+// Interface guards
+var _ API = (*Client)(nil)
@@ -0,0 +1,85 @@
+package cloudns
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// OperationKind is the kind of change applied to a record.
+type OperationKind string
+
+const (
+	OperationAdd        OperationKind = "add"
+	OperationModify     OperationKind = "modify"
+	OperationDelete     OperationKind = "delete"
+	OperationActivate   OperationKind = "activate"
+	OperationDeactivate OperationKind = "deactivate"
+)
+
+// AuditEntry describes a single change SetRecordsWithAudit attempted on the
+// zone, to be used for logging an accurate change trail.
+type AuditEntry struct {
+	Kind OperationKind
+
+	// Before is the record as it was before the change, nil for additions
+	Before libdns.Record
+
+	// After is the record as it is after the change, nil for deletions and
+	// failed operations
+	After libdns.Record
+
+	// Err is the error the operation failed with, if any
+	Err error
+}
+
+// newAuditEntry creates the audit entry of an executed operation. existing
+// maps the IDs of the records in the zone to their state before the change.
+func newAuditEntry(op operationEntry, existing map[string]ApiDnsRecord, after libdns.Record, err error) AuditEntry {
+	entry := AuditEntry{After: after, Err: err}
+
+	switch op.op {
+	case addRecord:
+		entry.Kind = OperationAdd
+	case modifyRecord:
+		entry.Kind = OperationModify
+	case deleteRecord:
+		entry.Kind = OperationDelete
+	default:
+		entry.Kind = OperationDeactivate
+		if op.status != nil && *op.status {
+			entry.Kind = OperationActivate
+		}
+	}
+
+	if before, ok := existing[op.record.Id]; ok && op.op != addRecord {
+		if rec, err := before.toLibdnsRecord(); err == nil {
+			entry.Before = rec
+		}
+	}
+
+	return entry
+}
+
+// notifyChange calls the hook of the provider matching a successful change
+// described by the audit entry, if set.
+func (p *Provider) notifyChange(ctx context.Context, zone string, entry AuditEntry) {
+	if p.DryRun {
+		return
+	}
+
+	switch entry.Kind {
+	case OperationAdd:
+		if p.OnRecordAdded != nil {
+			p.OnRecordAdded(ctx, zone, entry.After)
+		}
+	case OperationDelete:
+		if p.OnRecordDeleted != nil {
+			p.OnRecordDeleted(ctx, zone, entry.Before)
+		}
+	default:
+		if p.OnRecordModified != nil {
+			p.OnRecordModified(ctx, zone, entry.Before, entry.After)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cloudns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestSetRecordsWithAudit(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{
+				"1": {"id": "1", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "60", "status": 1},
+				"2": {"id": "2", "type": "A", "host": "www", "record": "192.0.2.2", "ttl": "60", "status": 1}
+			}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","AAAA","CNAME","TXT"]`)
+		case "/dns/mod-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was modified successfully."}`)
+		case "/dns/add-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":3}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", OperationRetries: 1}
+	_, audit, err := provider.SetRecordsWithAudit(t.Context(), "example.com", []libdns.Record{
+		libdns.RR{Name: "www", TTL: time.Minute, Type: "A", Data: "192.0.2.1"},
+		libdns.RR{Name: "www", TTL: time.Minute, Type: "A", Data: "192.0.2.5"},
+		libdns.RR{Name: "new", TTL: time.Minute, Type: "TXT", Data: "hello"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+
+	kinds := map[OperationKind]AuditEntry{}
+	for _, entry := range audit {
+		kinds[entry.Kind] = entry
+	}
+	if len(audit) != 2 || len(kinds) != 2 {
+		t.Fatalf("Expected one modification and one addition, got %+v", audit)
+	}
+
+	modify := kinds[OperationModify]
+	if modify.Before == nil || modify.Before.RR().Data != "192.0.2.2" || modify.After.RR().Data != "192.0.2.5" {
+		t.Errorf("Unexpected modification entry %+v", modify)
+	}
+
+	add := kinds[OperationAdd]
+	if add.Before != nil || add.After.RR().Data != "hello" || add.Err != nil {
+		t.Errorf("Unexpected addition entry %+v", add)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{"1": {"id": "1", "type": "TXT", "host": "old", "record": "bye", "ttl": "60", "status": 1}}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","TXT"]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/add-record.json":
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Invalid record."}`)
+		case "/dns/delete-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was deleted successfully."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	var log bytes.Buffer
+	provider, err := NewProvider(WithCredentials("id", "password"), WithRetries(1), WithAuditLog(&log))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	ctx := WithOperationID(t.Context(), "op-1")
+	if _, err := provider.AppendRecords(ctx, "example.com", []libdns.Record{
+		libdns.TXT{Name: "new", TTL: time.Minute, Text: "hello"},
+	}); err == nil {
+		t.Fatalf("Expected the addition to fail")
+	}
+	if _, err := provider.DeleteRecords(ctx, "example.com", []libdns.Record{
+		libdns.TXT{Name: "old", Text: "bye"},
+	}); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+
+	var events []AuditEvent
+	for _, line := range strings.Split(strings.TrimSpace(log.String()), "\n") {
+		var event AuditEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Failed to decode audit line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 audit events, got:\n%s", log.String())
+	}
+
+	add := events[0]
+	if add.Op != OperationAdd || add.Result != "failure" || add.Error == "" || add.OperationID != "op-1" || add.Zone != "example.com" {
+		t.Errorf("Unexpected addition event %+v", add)
+	}
+	if add.Before != nil || add.After == nil || *add.After != (AuditRecord{Name: "new", Type: "TXT", TTL: 60, Data: "hello"}) {
+		t.Errorf("Expected the attempted record in the addition event, got %+v", add)
+	}
+
+	del := events[1]
+	if del.Op != OperationDelete || del.Result != "success" || del.After != nil || del.Time.IsZero() {
+		t.Errorf("Unexpected deletion event %+v", del)
+	}
+	if del.Before == nil || *del.Before != (AuditRecord{Name: "old", Type: "TXT", TTL: 60, Data: "bye"}) {
+		t.Errorf("Expected the deleted record in the deletion event, got %+v", del)
+	}
+}
@@ -0,0 +1,103 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// AuditSink receives an AuditEvent for every change AppendRecords,
+// SetRecords and DeleteRecords attempt, whether it succeeded or not.
+// Implementations must be safe for concurrent use.
+type AuditSink interface {
+	Audit(ctx context.Context, event AuditEvent)
+}
+
+// AuditEvent describes an attempted change to a zone, suitable for shipping
+// to an audit trail or SIEM system.
+type AuditEvent struct {
+	Time        time.Time     `json:"time"`
+	OperationID string        `json:"operation_id,omitempty"`
+	Zone        string        `json:"zone"`
+	Op          OperationKind `json:"op"`
+
+	// Before is the record before the change, nil for additions, and After
+	// the record after the change, or the one that was attempted to be
+	// written if the change failed. After is nil for deletions.
+	Before *AuditRecord `json:"before,omitempty"`
+	After  *AuditRecord `json:"after,omitempty"`
+
+	// Result is "success" or "failure", and Error the reason of a failure
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditRecord is a record in an AuditEvent, in zone file presentation.
+type AuditRecord struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	TTL  int64  `json:"ttl"`
+	Data string `json:"data"`
+}
+
+// jsonAuditSink writes audit events as JSON lines.
+type jsonAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAuditSink returns an AuditSink writing every event as a line of JSON
+// to w. Write errors are ignored, so that a broken audit log does not fail
+// the changes it records.
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	return &jsonAuditSink{enc: json.NewEncoder(w)}
+}
+
+func (s *jsonAuditSink) Audit(ctx context.Context, event AuditEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.enc.Encode(event)
+}
+
+// newAuditRecord converts a record for an AuditEvent, nil for nil.
+func newAuditRecord(record libdns.Record) *AuditRecord {
+	if record == nil {
+		return nil
+	}
+
+	rr := record.RR()
+	return &AuditRecord{Name: rr.Name, Type: rr.Type, TTL: int64(rr.TTL.Seconds()), Data: rr.Data}
+}
+
+// audit sends the change described by the audit entry to the AuditSink of
+// the provider, if set and not in a dry run. attempted is the record that
+// was to be written, reported in place of After if the change failed.
+func (p *Provider) audit(ctx context.Context, zone string, entry AuditEntry, attempted libdns.Record) {
+	if p.auditSink == nil || p.DryRun {
+		return
+	}
+
+	event := AuditEvent{
+		Time:        time.Now().UTC(),
+		OperationID: OperationID(ctx),
+		Zone:        zone,
+		Op:          entry.Kind,
+		Before:      newAuditRecord(entry.Before),
+		After:       newAuditRecord(entry.After),
+		Result:      "success",
+	}
+	if entry.Err != nil {
+		event.Result = "failure"
+		event.Error = entry.Err.Error()
+		if entry.Kind != OperationDelete {
+			event.After = newAuditRecord(attempted)
+		}
+	}
+
+	p.auditSink.Audit(ctx, event)
+}
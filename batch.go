@@ -0,0 +1,83 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/libdns/libdns"
+)
+
+// ZoneResult holds the outcome of setting the records of a single zone in ApplyMany.
+type ZoneResult struct {
+	// Records are the records that were successfully set in the zone
+	Records []libdns.Record
+
+	// Err is the error encountered while setting the records, if any
+	Err error
+}
+
+// ApplyMany sets the records of several zones at once, with the same
+// semantics as SetRecords for each zone. It is meant for accounts managing a
+// large number of zones.
+//
+// The changes are planned before any of them is made: zone names are
+// normalized, merging the records of names given in several forms like
+// "example.com" and "example.com.", and the records of all zones are
+// validated. A zone that is not allowed or a record that is invalid fails
+// ApplyMany without changing any zone.
+//
+// Zones are then processed one after the other, in lexical order, through the
+// clients of the provider, so that they share its rate limit (see
+// WithRateLimit), and retries are handled the same way as for a single zone.
+// A failing zone does not prevent the others from being processed, but zones
+// not yet started when the context is cancelled are skipped.
+//
+// The result holds an entry for each zone that was processed, by normalized
+// zone name. The returned error joins the errors of all zones, each
+// annotated with its zone name.
+func (p *Provider) ApplyMany(ctx context.Context, zones map[string][]libdns.Record) (map[string]ZoneResult, error) {
+	if p.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	merged := make(map[string][]libdns.Record, len(zones))
+	for _, zone := range slices.Sorted(maps.Keys(zones)) {
+		name := normalizeZone(zone)
+		merged[name] = append(merged[name], zones[zone]...)
+	}
+
+	var errs []error
+	for _, zone := range slices.Sorted(maps.Keys(merged)) {
+		if err := p.checkZone(zone); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := validateRecords(merged[zone]); err != nil {
+			errs = append(errs, fmt.Errorf("zone %q: %w", zone, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	results := make(map[string]ZoneResult, len(merged))
+	for _, zone := range slices.Sorted(maps.Keys(merged)) {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		records, err := p.SetRecords(ctx, zone, merged[zone])
+		if err != nil {
+			err = fmt.Errorf("zone %q: %w", zone, err)
+			errs = append(errs, err)
+		}
+
+		results[zone] = ZoneResult{Records: records, Err: err}
+	}
+
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestApplyMany(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		zone := r.URL.Query().Get("domain-name")
+		switch r.URL.Path {
+		case "/dns/records.json":
+			if zone == "broken.com" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","AAAA","CNAME","TXT"]`)
+		case "/dns/add-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":1}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", OperationRetries: 1}
+	record := libdns.TXT{Name: "test", TTL: 300 * time.Second, Text: "hello"}
+	results, err := provider.ApplyMany(t.Context(), map[string][]libdns.Record{
+		"example.com": {record},
+		"broken.com":  {record},
+	})
+	if err == nil {
+		t.Fatalf("Expected an error for the broken zone")
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected results for 2 zones, got %d", len(results))
+	}
+	if res := results["example.com"]; res.Err != nil || len(res.Records) != 1 {
+		t.Errorf("Unexpected result for example.com: %+v", res)
+	}
+	if res := results["broken.com"]; res.Err == nil || !errors.Is(err, res.Err) {
+		t.Errorf("Expected error for broken.com to be part of the returned error, got %+v", res)
+	}
+}
+
+func TestApplyManyPlanning(t *testing.T) {
+	var added []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","TXT"]`)
+		case "/dns/add-record.json":
+			added = append(added, r.URL.Query().Get("domain-name")+" "+r.URL.Query().Get("record"))
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":1}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", AllowedZones: []string{"example.com", "example.net"}}
+	zones := map[string][]libdns.Record{
+		"example.com":  {libdns.TXT{Name: "test", TTL: time.Minute, Text: "one"}},
+		"example.com.": {libdns.TXT{Name: "test", TTL: time.Minute, Text: "two"}},
+	}
+
+	// Zones after the first one that fail the checks prevent any change
+	for name, invalid := range map[string]map[string][]libdns.Record{
+		"not allowed":    {"example.org": {libdns.TXT{Name: "test", TTL: time.Minute, Text: "hello"}}},
+		"invalid record": {"example.net": {libdns.RR{Name: "test", TTL: time.Minute, Type: "A", Data: "foo"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			all := maps.Clone(zones)
+			maps.Copy(all, invalid)
+			results, err := provider.ApplyMany(t.Context(), all)
+			if err == nil || results != nil {
+				t.Errorf("Expected the plan to fail, got %v, %+v", err, results)
+			}
+			if len(added) != 0 {
+				t.Errorf("Expected no changes, got %v", added)
+			}
+		})
+	}
+
+	results, err := provider.ApplyMany(t.Context(), zones)
+	if err != nil {
+		t.Fatalf("Failed to apply records: %v", err)
+	}
+	if len(results) != 1 || len(results["example.com"].Records) != 2 {
+		t.Errorf("Expected the records of both spellings of example.com to be merged, got %+v", results)
+	}
+	slices.Sort(added)
+	if expected := []string{"example.com one", "example.com two"}; !slices.Equal(added, expected) {
+		t.Errorf("Expected additions %v, got %v", expected, added)
+	}
+}
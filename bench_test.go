@@ -0,0 +1,94 @@
+package cloudns
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// benchmarkZoneSize is the number of records of the synthetic zones the
+// benchmarks work on.
+const benchmarkZoneSize = 10000
+
+// benchmarkZone returns the records of a synthetic zone, keyed by ID like in
+// records.json responses.
+func benchmarkZone(b *testing.B) map[string]ApiDnsRecord {
+	b.Helper()
+
+	zone := make(map[string]ApiDnsRecord, benchmarkZoneSize)
+	for _, record := range generateApiRecords(1, benchmarkZoneSize) {
+		zone[record.Id] = record
+	}
+
+	return zone
+}
+
+func BenchmarkGetRecords(b *testing.B) {
+	body, err := json.Marshal(benchmarkZone(b))
+	if err != nil {
+		b.Fatalf("Failed to encode zone: %v", err)
+	}
+	useTestServer(b, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		records, err := provider.GetRecords(b.Context(), "example.com")
+		if err != nil || len(records) != benchmarkZoneSize {
+			b.Fatalf("Failed to get records: got %d, %v", len(records), err)
+		}
+	}
+}
+
+func BenchmarkDecodeRecords(b *testing.B) {
+	body, err := json.Marshal(benchmarkZone(b))
+	if err != nil {
+		b.Fatalf("Failed to encode zone: %v", err)
+	}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := decodeRecords(body, RecordFilter{}); err != nil {
+			b.Fatalf("Failed to decode records: %v", err)
+		}
+	}
+}
+
+func BenchmarkSetRecords(b *testing.B) {
+	zone := benchmarkZone(b)
+	existing := make([]ApiDnsRecord, 0, len(zone))
+	for _, record := range zone {
+		existing = append(existing, record)
+	}
+
+	// Change a hundred of the records and leave the others as they are
+	desired := make([]libdns.Record, 0, 100)
+	for _, record := range existing[:100] {
+		rec, err := record.toLibdnsRecord()
+		if err != nil {
+			b.Fatalf("Failed to convert record: %v", err)
+		}
+		rr := rec.RR()
+		desired = append(desired, libdns.TXT{Name: rr.Name, TTL: rr.TTL, Text: fmt.Sprintf("changed %s", record.Id)})
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		api := &mockAPI{records: existing}
+		provider, err := NewProvider(WithAPI(api))
+		if err != nil {
+			b.Fatalf("Failed to create provider: %v", err)
+		}
+		if _, err := provider.SetRecords(b.Context(), "example.com", desired); err != nil {
+			b.Fatalf("Failed to set records: %v", err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package cloudns
+
+import "strings"
+
+// caaCriticalFlag is the issuer critical flag of CAA records. A CA that does
+// not understand the tag of a critical record must refuse to issue.
+const caaCriticalFlag = 128
+
+// normalizeCAATag brings a CAA property tag into the lower case form used by
+// ClouDNS. Tags are case insensitive.
+func normalizeCAATag(tag string) string {
+	return strings.ToLower(tag)
+}
+
+// unquoteCAAValue strips the quotes around a CAA value in zone file format,
+// like `"letsencrypt.org"`, which ClouDNS takes without them. Escaped quotes
+// and backslashes within the value are unescaped.
+func unquoteCAAValue(value string) string {
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return value
+	}
+
+	chunks, ok := splitTXTStrings(value)
+	if !ok || len(chunks) != 1 {
+		return value
+	}
+
+	return chunks[0]
+}
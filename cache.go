@@ -0,0 +1,97 @@
+package cloudns
+
+import (
+	"sync"
+	"time"
+)
+
+// zoneCache holds the per-zone settings that are looked up before records are
+// changed, namely the accepted TTLs and record types. Every Client has its
+// own cache by default, and a Provider shares one across its operations,
+// which WithCache makes expire.
+//
+// The mutexes only guard the maps and are not held while entries are fetched,
+// so that a slow lookup for one zone does not hold up those of other zones.
+type zoneCache struct {
+	// expiry is how long entries are kept, forever if zero
+	expiry time.Duration
+
+	ttlMu sync.Mutex
+	ttls  map[string]cacheEntry[[]int]
+
+	recordTypesMu sync.Mutex
+	recordTypes   map[string]cacheEntry[[]string]
+}
+
+// clear drops all entries.
+func (c *zoneCache) clear() {
+	c.ttlMu.Lock()
+	c.ttls = nil
+	c.ttlMu.Unlock()
+
+	c.recordTypesMu.Lock()
+	c.recordTypes = nil
+	c.recordTypesMu.Unlock()
+}
+
+// lookupTTLs returns the cached TTLs of the zone, unless they expired.
+func (c *zoneCache) lookupTTLs(zone string) ([]int, bool) {
+	c.ttlMu.Lock()
+	defer c.ttlMu.Unlock()
+
+	return lookupCache(c.ttls, zone)
+}
+
+// storeTTLs caches the TTLs of the zone.
+func (c *zoneCache) storeTTLs(zone string, ttls []int) {
+	c.ttlMu.Lock()
+	defer c.ttlMu.Unlock()
+
+	storeCache(&c.ttls, zone, ttls, c.expiry)
+}
+
+// lookupRecordTypes returns the cached record types of the zone, unless they
+// expired.
+func (c *zoneCache) lookupRecordTypes(zone string) ([]string, bool) {
+	c.recordTypesMu.Lock()
+	defer c.recordTypesMu.Unlock()
+
+	return lookupCache(c.recordTypes, zone)
+}
+
+// storeRecordTypes caches the record types of the zone.
+func (c *zoneCache) storeRecordTypes(zone string, types []string) {
+	c.recordTypesMu.Lock()
+	defer c.recordTypesMu.Unlock()
+
+	storeCache(&c.recordTypes, zone, types, c.expiry)
+}
+
+type cacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// lookupCache returns the entry for key, unless it expired.
+func lookupCache[V any](entries map[string]cacheEntry[V], key string) (V, bool) {
+	entry, ok := entries[key]
+	if !ok || (!entry.expires.IsZero() && time.Now().After(entry.expires)) {
+		var zero V
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+// storeCache adds an entry for key to the map, creating it if needed.
+func storeCache[V any](entries *map[string]cacheEntry[V], key string, value V, expiry time.Duration) {
+	if *entries == nil {
+		*entries = make(map[string]cacheEntry[V])
+	}
+
+	entry := cacheEntry[V]{value: value}
+	if expiry > 0 {
+		entry.expires = time.Now().Add(expiry)
+	}
+	(*entries)[key] = entry
+}
@@ -1,14 +1,20 @@
 package cloudns
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"maps"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/libdns/libdns"
 )
@@ -19,6 +25,55 @@ type Client struct {
 	AuthId       string `json:"auth_id"`
 	SubAuthId    string `json:"sub_auth_id"`
 	AuthPassword string `json:"auth_password"`
+
+	// FallbackAuthId, FallbackSubAuthId and FallbackAuthPassword are
+	// credentials used for requests the API rejects with an authentication
+	// error, e.g. while a password rotation is in progress. They are unused
+	// if FallbackAuthPassword is empty.
+	FallbackAuthId       string `json:"fallback_auth_id,omitempty"`
+	FallbackSubAuthId    string `json:"fallback_sub_auth_id,omitempty"`
+	FallbackAuthPassword string `json:"fallback_auth_password,omitempty"`
+
+	// ReadOnly makes the client reject any request changing the account
+	// with ErrReadOnly, before it is sent.
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// StrictDecoding makes the client reject responses with fields it does
+	// not know with ErrUnknownField, and log them as warnings, so that
+	// changes of the API are noticed. By default they are ignored.
+	StrictDecoding bool `json:"strict_decoding,omitempty"`
+
+	// HTTPClient is used to send the requests, http.DefaultClient if nil
+	HTTPClient *http.Client `json:"-"`
+
+	// Logger receives a debug message for every request sent, if set
+	Logger *slog.Logger `json:"-"`
+
+	// TraceRequests makes the client log the DNS lookup, connection reuse,
+	// TLS handshake and time to first byte of every request to Logger, at
+	// debug level, to diagnose slow requests.
+	TraceRequests bool `json:"-"`
+
+	// Metrics observes every request sent, if set
+	Metrics Metrics `json:"-"`
+
+	// RecordsPerPage makes GetClouDNSRecords fetch the records of a zone in
+	// pages of the given size, as huge zones require. ClouDNS accepts 10, 20,
+	// 30, 50 and 100, and other sizes fail with ErrInvalidConfig. All records
+	// are fetched with a single request if zero.
+	RecordsPerPage int `json:"records_per_page,omitempty"`
+
+	// PageConcurrency is the number of pages of records fetched at the same
+	// time with RecordsPerPage, 1 if zero.
+	PageConcurrency int `json:"page_concurrency,omitempty"`
+
+	// cache holds the accepted TTL values and record types per zone, as
+	// returned by get-available-ttl.json and get-available-record-types.json.
+	cacheOnce sync.Once
+	cache     *zoneCache
+
+	// limiter paces the requests, if set
+	limiter *rateLimiter
 }
 
 var apiBaseUrl, _ = url.Parse("https://api.cloudns.net/dns/")
@@ -32,6 +87,18 @@ func UseClient(authId, subAuthId, authPassword string) *Client {
 	}
 }
 
+// zoneCache returns the cache of the client, creating it on first use unless
+// a shared one was set.
+func (c *Client) zoneCache() *zoneCache {
+	c.cacheOnce.Do(func() {
+		if c.cache == nil {
+			c.cache = &zoneCache{}
+		}
+	})
+
+	return c.cache
+}
+
 // GetClouDNSRecords returns the raw upstream results from ClouDNS.
 // For use when the IDs of the individual records needs to be preserved, which
 // cannot be done with the generic libdns.Record interface.
@@ -44,10 +111,69 @@ func UseClient(authId, subAuthId, authPassword string) *Client {
 //   - []ApiDnsRecord: Slice of all DNS records in the zone
 //   - error: Any error that occurred during the operation
 func (c *Client) GetClouDNSRecords(ctx context.Context, zone string) ([]ApiDnsRecord, error) {
-	recordsEndpoint := apiBaseUrl.JoinPath("records.json")
+	return c.GetFilteredClouDNSRecords(ctx, zone, RecordFilter{})
+}
+
+// RecordFilter selects records by name and type. Empty fields match any value.
+type RecordFilter struct {
+	// Host is the name of the records relative to the zone, "@" for the apex
+	Host string
+	Type string
+}
+
+func (f RecordFilter) matches(record ApiDnsRecord) bool {
+	if f == (RecordFilter{}) {
+		return true
+	}
+
+	key := newNameAndType(record.Host, record.Type)
+	if f.Host != "" {
+		host := f.Host
+		if host == "@" {
+			host = ""
+		}
+		if key.name != newNameAndType(host, "").name {
+			return false
+		}
+	}
+
+	return f.Type == "" || key.type_ == strings.ToUpper(f.Type)
+}
+
+// GetFilteredClouDNSRecords returns the raw upstream results from ClouDNS for
+// the records matching the filter only. The filter is applied by ClouDNS, so
+// only the selected records are transferred.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to retrieve records from
+//   - filter: The name and type of the records to retrieve
+//
+// Returns:
+//   - []ApiDnsRecord: Slice of the matching DNS records in the zone
+//   - error: Any error that occurred during the operation
+func (c *Client) GetFilteredClouDNSRecords(ctx context.Context, zone string, filter RecordFilter) ([]ApiDnsRecord, error) {
 	params := map[string]string{
 		"domain-name": zone,
 	}
+	if filter.Host != "" && filter.Host != "@" {
+		params["host"] = normalizeHost(filter.Host)
+	}
+	if filter.Type != "" {
+		params["type"] = strings.ToUpper(filter.Type)
+	}
+
+	if c.RecordsPerPage > 0 {
+		return c.getRecordPages(ctx, params, filter)
+	}
+
+	return c.getRecords(ctx, params, filter)
+}
+
+// getRecords requests the records matching the filter with the parameters
+// from records.json.
+func (c *Client) getRecords(ctx context.Context, params map[string]string, filter RecordFilter) ([]ApiDnsRecord, error) {
+	recordsEndpoint := apiBaseUrl.JoinPath("records.json")
 
 	// Perform the API request
 	resp, err := c.performGetRequest(ctx, recordsEndpoint, params)
@@ -58,17 +184,260 @@ func (c *Client) GetClouDNSRecords(ctx context.Context, zone string) ([]ApiDnsRe
 
 	// Check HTTP status code
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned non-OK status code %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newHTTPError(resp)
 	}
 
-	// Parse the API response
-	var apiResult map[string]ApiDnsRecord
-	if err := json.NewDecoder(resp.Body).Decode(&apiResult); err != nil {
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	if err := c.checkRecordFields(ctx, recordsEndpoint.Path, bodyBytes); err != nil {
+		return nil, err
+	}
+
+	records, err := decodeRecords(bodyBytes, filter)
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return nil, apiErr.from(resp)
+	}
+
+	return records, err
+}
+
+// decodeRecords decodes a records.json response, returning the records
+// matching the filter.
+func decodeRecords(bodyBytes []byte, filter RecordFilter) ([]ApiDnsRecord, error) {
+	// Zones without records are answered with an empty array instead of an
+	// object, so arrays of records are accepted as well
+	if trimmed := bytes.TrimSpace(bodyBytes); len(trimmed) > 0 && trimmed[0] == '[' {
+		var apiResult []ApiDnsRecord
+		if err := json.Unmarshal(trimmed, &apiResult); err != nil {
+			return nil, fmt.Errorf("failed to decode API response: %w", err)
+		}
+
+		return slices.DeleteFunc(apiResult, func(record ApiDnsRecord) bool {
+			return !filter.matches(record)
+		}), nil
+	}
+
+	// Records are keyed by ID, about 150 bytes each
+	apiResult := make(map[string]ApiDnsRecord, len(bodyBytes)/150)
+	if err := json.Unmarshal(bodyBytes, &apiResult); err != nil {
+		// The endpoint returns a status object instead of the records on failure
+		var resultModel ApiResponse
+		if json.Unmarshal(bodyBytes, &resultModel) == nil && resultModel.Status != "" && resultModel.Status != success {
+			return nil, newAPIError(resultModel.StatusDescription)
+		}
+
+		return nil, fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	// The host filter of ClouDNS also matches names containing the host, so
+	// the exact matches are picked here
+	records := make([]ApiDnsRecord, 0, len(apiResult))
+	for _, record := range apiResult {
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// GetAvailableTTLs returns the TTL values (in seconds) ClouDNS accepts for
+// records in the given zone, sorted in ascending order. The list depends on
+// the account's plan, so it is fetched from the API on first use and cached
+// on the client for subsequent calls.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to retrieve the TTL list for
+//
+// Returns:
+//   - []int: The accepted TTL values in seconds
+//   - error: Any error that occurred during the operation
+func (c *Client) GetAvailableTTLs(ctx context.Context, zone string) ([]int, error) {
+	cache := c.zoneCache()
+	if ttls, ok := cache.lookupTTLs(zone); ok {
+		return ttls, nil
+	}
+
+	endpoint := apiBaseUrl.JoinPath("get-available-ttl.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	// Perform the API request
+	resp, err := c.performGetRequest(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check HTTP status code
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp)
+	}
+
+	// The endpoint returns a plain array on success and a status object on failure
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	var ttls []int
+	if err := c.decodeJSON(ctx, endpoint.Path, bodyBytes, &ttls); err != nil {
+		var resultModel ApiResponse
+		if json.Unmarshal(bodyBytes, &resultModel) == nil && resultModel.Status != "" {
+			return nil, newAPIError(resultModel.StatusDescription).from(resp)
+		}
 		return nil, fmt.Errorf("failed to decode API response: %w", err)
 	}
 
-	return slices.Collect(maps.Values(apiResult)), nil
+	if len(ttls) == 0 {
+		return nil, fmt.Errorf("API returned an empty TTL list for zone %q", zone)
+	}
+
+	slices.Sort(ttls)
+	cache.storeTTLs(zone, ttls)
+
+	return ttls, nil
+}
+
+// availableTTLs returns the accepted TTL values for the zone, or nil if they
+// could not be retrieved. A nil list makes the TTL rounding fall back to the
+// default ClouDNS values, so a failure here does not block record changes,
+// unless the zone does not exist at all.
+func availableTTLs(ctx context.Context, api API, zone string) ([]int, error) {
+	ttls, err := api.GetAvailableTTLs(ctx, zone)
+	if errors.Is(err, ErrZoneNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, nil
+	}
+
+	return ttls, nil
+}
+
+// recordTypesZoneTypes maps zone types to the zone-type parameter of
+// get-available-record-types.json.
+var recordTypesZoneTypes = map[ZoneType]string{
+	ZoneTypeMaster:  "domain",
+	ZoneTypeReverse: "reverse",
+	ZoneTypeParked:  "parked",
+	ZoneTypeGeoDNS:  "geodns",
+}
+
+// GetAvailableRecordTypes returns the record types ClouDNS accepts in the
+// given zone, which depend on the type of the zone. The list is fetched from
+// the API on first use and cached on the client for subsequent calls.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to retrieve the record types for
+//
+// Returns:
+//   - []string: The accepted record types, in upper case
+//   - error: Any error that occurred during the operation
+func (c *Client) GetAvailableRecordTypes(ctx context.Context, zone string) ([]string, error) {
+	cache := c.zoneCache()
+	if types, ok := cache.lookupRecordTypes(zone); ok {
+		return types, nil
+	}
+
+	info, err := c.GetZoneInfo(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	zoneType, ok := recordTypesZoneTypes[info.Type]
+	if !ok {
+		return nil, fmt.Errorf("records cannot be managed in %s zones", info.Type)
+	}
+
+	endpoint := apiBaseUrl.JoinPath("get-available-record-types.json")
+	params := map[string]string{
+		"zone-type": zoneType,
+	}
+
+	var types []string
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &types); err != nil {
+		return nil, err
+	}
+	for idx, type_ := range types {
+		types[idx] = strings.ToUpper(type_)
+	}
+
+	cache.storeRecordTypes(zone, types)
+
+	return types, nil
+}
+
+// checkRecordTypes rejects the records whose type is not accepted in the
+// zone, so that they are reported before any change is made. Like for the
+// TTLs, a failure to retrieve the accepted types does not block record
+// changes, and leaves the check to ClouDNS.
+func checkRecordTypes(ctx context.Context, api API, zone string, records []libdns.Record) error {
+	types, err := api.GetAvailableRecordTypes(ctx, zone)
+	if err != nil || len(types) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, rec := range records {
+		rr := rec.RR()
+		type_ := canonicalRecordType(rr.Type)
+		if !slices.ContainsFunc(types, func(accepted string) bool {
+			return canonicalRecordType(accepted) == type_
+		}) {
+			errs = append(errs, fmt.Errorf("%w %s %q: record type not supported in zone %q, expected one of %v", ErrInvalidRecord, rr.Type, rr.Name, zone, types))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetAvailableNameServers returns the ClouDNS nameservers available to the
+// account, to be used when delegating zones or probing for propagation.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//
+// Returns:
+//   - []NameServer: The available nameservers
+//   - error: Any error that occurred during the operation
+func (c *Client) GetAvailableNameServers(ctx context.Context) ([]NameServer, error) {
+	endpoint := apiBaseUrl.JoinPath("available-name-servers.json")
+
+	// Perform the API request
+	resp, err := c.performGetRequest(ctx, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check HTTP status code
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp)
+	}
+
+	// The endpoint returns a plain array on success and a status object on failure
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	var nameServers []NameServer
+	if err := c.decodeJSON(ctx, endpoint.Path, bodyBytes, &nameServers); err != nil {
+		var resultModel ApiResponse
+		if json.Unmarshal(bodyBytes, &resultModel) == nil && resultModel.Status != "" {
+			return nil, newAPIError(resultModel.StatusDescription).from(resp)
+		}
+		return nil, fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	return nameServers, nil
 }
 
 // GetRecords retrieves DNS records for the specified zone.
@@ -79,6 +448,11 @@ func (c *Client) GetRecords(ctx context.Context, zone string) ([]libdns.Record,
 		return nil, err
 	}
 
+	return recordsToLibdns(apiResult)
+}
+
+// recordsToLibdns converts raw upstream records into libdns records.
+func recordsToLibdns(apiResult []ApiDnsRecord) ([]libdns.Record, error) {
 	records := make([]libdns.Record, 0, len(apiResult))
 	for _, recordData := range apiResult {
 		record, err := recordData.toLibdnsRecord()
@@ -89,9 +463,6 @@ func (c *Client) GetRecords(ctx context.Context, zone string) ([]libdns.Record,
 		records = append(records, record)
 	}
 
-	// Log the number of records found
-	fmt.Printf("Found %d records in zone %s\n", len(records), zone)
-
 	return records, nil
 }
 
@@ -107,34 +478,40 @@ func (c *Client) GetRecords(ctx context.Context, zone string) ([]libdns.Record,
 //   - libdns.Record: The created record
 //   - error: Any error that occurred during the operation
 func (c *Client) AddRecord(ctx context.Context, zone string, record ApiDnsRecord) (libdns.Record, error) {
+	created, err := c.AddClouDNSRecord(ctx, zone, record)
+	if err != nil {
+		return nil, err
+	}
+
+	return created.toLibdnsRecord()
+}
+
+// AddClouDNSRecord creates a new DNS record and returns it as an upstream API
+// object, with the ID assigned by ClouDNS.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to add the record to
+//   - record: The DNS record to add
+//
+// Returns:
+//   - ApiDnsRecord: The created record
+//   - error: Any error that occurred during the operation
+func (c *Client) AddClouDNSRecord(ctx context.Context, zone string, record ApiDnsRecord) (ApiDnsRecord, error) {
 	endpoint := apiBaseUrl.JoinPath("add-record.json")
 
 	params := record.toParameters()
 	params["domain-name"] = zone
-	resp, err := c.performPostRequest(ctx, endpoint, params)
+	id, err := c.performCreateRequest(ctx, endpoint, params)
 	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check HTTP status code
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned non-OK status code %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Parse the API response
-	var resultModel ApiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&resultModel); err != nil {
-		return nil, fmt.Errorf("failed to decode API response: %w", err)
+		return ApiDnsRecord{}, err
 	}
 
-	// Check if the operation was successful
-	if resultModel.Status != success {
-		return nil, fmt.Errorf("API operation failed: %s", resultModel.StatusDescription)
-	}
+	// Newly created records are always active
+	record.Id = id
+	record.Status = 1
 
-	return record.toLibdnsRecord()
+	return record, nil
 }
 
 // UpdateRecord updates an existing DNS record in the specified zone with the provided values and returns the updated record.
@@ -161,19 +538,18 @@ func (c *Client) UpdateRecord(ctx context.Context, zone string, record ApiDnsRec
 
 	// Check HTTP status code
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned non-OK status code %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newHTTPError(resp)
 	}
 
 	// Parse the API response
 	var resultModel ApiResponse
-	if err = json.NewDecoder(resp.Body).Decode(&resultModel); err != nil {
+	if err = c.decodeResponse(ctx, resp, &resultModel); err != nil {
 		return nil, fmt.Errorf("failed to decode API response: %w", err)
 	}
 
 	// Check if the operation was successful
 	if resultModel.Status != success {
-		return nil, fmt.Errorf("API operation failed: %s", resultModel.StatusDescription)
+		return nil, newAPIError(resultModel.StatusDescription).from(resp)
 	}
 
 	ret, err := record.toLibdnsRecord()
@@ -210,26 +586,85 @@ func (c *Client) DeleteRecord(ctx context.Context, zone string, recordId string)
 
 	// Check HTTP status code
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API returned non-OK status code %d: %s", resp.StatusCode, string(bodyBytes))
+		return newHTTPError(resp)
 	}
 
 	// Parse the API response
 	var resultModel ApiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&resultModel); err != nil {
+	if err := c.decodeResponse(ctx, resp, &resultModel); err != nil {
 		return fmt.Errorf("failed to decode API response: %w", err)
 	}
 
 	// Check if the operation was successful
 	if resultModel.Status != success {
-		return fmt.Errorf("API operation failed: %s", resultModel.StatusDescription)
+		return newAPIError(resultModel.StatusDescription).from(resp)
 	}
 
 	return nil
 }
 
-// performPostRequest sends a POST request to the specified URL with query parameters and returns the HTTP response or an error.
-// It adds authentication parameters and builds the request with the provided context.
+// ChangeRecordStatus enables or disables a DNS record identified by its ID in the specified zone.
+// Disabled records stay in the zone but are not served, so they can be re-enabled later without
+// being recreated.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the record to change
+//   - active: Whether the record should be enabled
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) ChangeRecordStatus(ctx context.Context, zone string, recordId string, active bool) error {
+	endpoint := apiBaseUrl.JoinPath("change-record-status.json")
+	status := "0"
+	if active {
+		status = "1"
+	}
+	params := map[string]string{
+		"domain-name": zone,
+		"record-id":   recordId,
+		"status":      status,
+	}
+
+	// Perform the API request
+	resp, err := c.performPostRequest(ctx, endpoint, params)
+	if err != nil {
+		return fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check HTTP status code
+	if resp.StatusCode != http.StatusOK {
+		return newHTTPError(resp)
+	}
+
+	// Parse the API response
+	var resultModel ApiResponse
+	if err := c.decodeResponse(ctx, resp, &resultModel); err != nil {
+		return fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	// Check if the operation was successful
+	if resultModel.Status != success {
+		return newAPIError(resultModel.StatusDescription).from(resp)
+	}
+
+	return nil
+}
+
+// ActivateRecord enables a previously disabled DNS record identified by its ID in the specified zone.
+func (c *Client) ActivateRecord(ctx context.Context, zone string, recordId string) error {
+	return c.ChangeRecordStatus(ctx, zone, recordId, true)
+}
+
+// DeactivateRecord disables a DNS record identified by its ID in the specified zone without deleting it.
+func (c *Client) DeactivateRecord(ctx context.Context, zone string, recordId string) error {
+	return c.ChangeRecordStatus(ctx, zone, recordId, false)
+}
+
+// performStatusRequest sends a POST request to an endpoint that answers with
+// a status object only, and returns an error unless the operation succeeded.
 //
 // Parameters:
 //   - ctx: Context for timeout and cancellation
@@ -237,53 +672,164 @@ func (c *Client) DeleteRecord(ctx context.Context, zone string, recordId string)
 //   - params: Map of query parameters to include in the request
 //
 // Returns:
-//   - *http.Response: The HTTP response from the API
-//   - error: Any error that occurred during the request
-func (c *Client) performPostRequest(ctx context.Context, targetURL *url.URL, params map[string]string) (*http.Response, error) {
-	// Create a copy of the URL to avoid modifying the original
-	requestURL := *targetURL
+//   - error: Any error that occurred during the request or reported by the API
+func (c *Client) performStatusRequest(ctx context.Context, targetURL *url.URL, params map[string]string) error {
+	// Perform the API request
+	resp, err := c.performPostRequest(ctx, targetURL, params)
+	return c.checkStatusResponse(ctx, resp, err)
+}
 
-	// Get query parameters and add authentication
-	queries := requestURL.Query()
-	c.addAuthParams(queries)
+// performFormStatusRequest works like performStatusRequest, but sends the
+// parameters and the credentials as a form in the request body instead of the
+// URL, for parameters too large for a URL, like the contents of a zone file.
+// This also keeps them out of the access logs of proxies.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - targetURL: The API endpoint URL
+//   - params: Map of form parameters to include in the request
+//
+// Returns:
+//   - error: Any error that occurred during the request or reported by the API
+func (c *Client) performFormStatusRequest(ctx context.Context, targetURL *url.URL, params map[string]string) error {
+	if c.ReadOnly {
+		return fmt.Errorf("API request failed: %w", ErrReadOnly)
+	}
 
-	// Add all provided parameters to the query
-	for k, v := range params {
-		queries.Set(k, v)
+	resp, err := c.sendRequest(ctx, http.MethodPost, targetURL, params, true)
+	return c.checkStatusResponse(ctx, resp, err)
+}
+
+// checkStatusResponse checks the response to a request answered with a
+// status object, consuming its body.
+func (c *Client) checkStatusResponse(ctx context.Context, resp *http.Response, err error) error {
+	if err != nil {
+		return fmt.Errorf("API request failed: %w", err)
 	}
+	defer resp.Body.Close()
 
-	// Encode the query parameters and set them on the URL
-	requestURL.RawQuery = queries.Encode()
+	// Check HTTP status code
+	if resp.StatusCode != http.StatusOK {
+		return newHTTPError(resp)
+	}
 
-	// Create a new HTTP request with the provided context
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL.String(), nil)
+	// Parse the API response
+	var resultModel ApiResponse
+	if err := c.decodeResponse(ctx, resp, &resultModel); err != nil {
+		return fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	// Check if the operation was successful
+	if resultModel.Status != success {
+		return newAPIError(resultModel.StatusDescription).from(resp)
+	}
+
+	return nil
+}
+
+// performCreateRequest sends a POST request creating an object and returns
+// the ID ClouDNS assigned to it.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - targetURL: The API endpoint URL
+//   - params: Map of query parameters to include in the request
+//
+// Returns:
+//   - string: The ID of the created object
+//   - error: Any error that occurred during the request or reported by the API
+func (c *Client) performCreateRequest(ctx context.Context, targetURL *url.URL, params map[string]string) (string, error) {
+	// Perform the API request
+	resp, err := c.performPostRequest(ctx, targetURL, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		return "", fmt.Errorf("API request failed: %w", err)
 	}
+	defer resp.Body.Close()
 
-	// Set appropriate headers
-	req.Header.Set("User-Agent", "cloudns-go-client/1.0")
-	req.Header.Set("Accept", "application/json")
+	// Check HTTP status code
+	if resp.StatusCode != http.StatusOK {
+		return "", newHTTPError(resp)
+	}
 
-	// Execute the request
-	return http.DefaultClient.Do(req)
+	// Parse the API response
+	var resultModel ApiResponse
+	if err := c.decodeResponse(ctx, resp, &resultModel); err != nil {
+		return "", fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	// Check if the operation was successful
+	if resultModel.Status != success {
+		return "", newAPIError(resultModel.StatusDescription).from(resp)
+	}
+
+	return strconv.Itoa(resultModel.Data.Id), nil
 }
 
-// addAuthParams adds authentication parameters to the provided query values based on the client's credentials.
-// It selects between auth-id and sub-auth-id based on which is provided.
+// performGetJSONRequest sends a GET request and decodes the response into
+// result. Endpoints answering with data on success reply with a status object
+// on failure instead, which is detected and turned into an error.
 //
 // Parameters:
-//   - queries: The url.Values to add authentication parameters to
-func (c *Client) addAuthParams(queries url.Values) {
-	// CloudNS API requires either auth-id or sub-auth-id, but not both
-	if c.SubAuthId != "" {
-		queries.Set("sub-auth-id", c.SubAuthId)
-	} else {
-		queries.Set("auth-id", c.AuthId)
+//   - ctx: Context for timeout and cancellation
+//   - targetURL: The API endpoint URL
+//   - params: Map of query parameters to include in the request
+//   - result: Pointer to the value to decode the response into
+//
+// Returns:
+//   - error: Any error that occurred during the request or reported by the API
+func (c *Client) performGetJSONRequest(ctx context.Context, targetURL *url.URL, params map[string]string, result any) error {
+	// Perform the API request
+	resp, err := c.performGetRequest(ctx, targetURL, params)
+	if err != nil {
+		return fmt.Errorf("API request failed: %w", err)
 	}
+	defer resp.Body.Close()
 
-	// Always include the auth password
-	queries.Set("auth-password", c.AuthPassword)
+	// Check HTTP status code
+	if resp.StatusCode != http.StatusOK {
+		return newHTTPError(resp)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	// A failed operation is reported as a status object with a description
+	var resultModel struct {
+		Status            string `json:"status"`
+		StatusDescription string `json:"statusDescription"`
+	}
+	if json.Unmarshal(bodyBytes, &resultModel) == nil && resultModel.Status == "Failed" {
+		return newAPIError(resultModel.StatusDescription).from(resp)
+	}
+
+	// Parse the API response
+	if err := c.decodeJSON(ctx, targetURL.Path, bodyBytes, result); err != nil {
+		return fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	return nil
+}
+
+// performPostRequest sends a POST request to the specified URL with query parameters and returns the HTTP response or an error.
+// It adds authentication parameters and builds the request with the provided context.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - targetURL: The API endpoint URL
+//   - params: Map of query parameters to include in the request
+//
+// Returns:
+//   - *http.Response: The HTTP response from the API
+//   - error: Any error that occurred during the request
+func (c *Client) performPostRequest(ctx context.Context, targetURL *url.URL, params map[string]string) (*http.Response, error) {
+	// All requests changing the account are sent as POST requests
+	if c.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	return c.sendRequest(ctx, http.MethodPost, targetURL, params, false)
 }
 
 // performGetRequest sends a GET request to the specified URL with query parameters and returns the HTTP response or an error.
@@ -298,6 +844,26 @@ func (c *Client) addAuthParams(queries url.Values) {
 //   - *http.Response: The HTTP response from the API
 //   - error: Any error that occurred during the request
 func (c *Client) performGetRequest(ctx context.Context, targetURL *url.URL, params map[string]string) (*http.Response, error) {
+	return c.sendRequest(ctx, http.MethodGet, targetURL, params, false)
+}
+
+// sendRequest sends a request with the given method to the specified URL with
+// query parameters and returns the HTTP response or an error. If the API
+// rejects the credentials of the client and fallback credentials are set, the
+// request is sent again with the fallback credentials.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - method: The HTTP method of the request
+//   - targetURL: The API endpoint URL
+//   - params: Map of query parameters to include in the request
+//   - form: Whether to send the parameters and credentials as a form in the
+//     request body instead of the query
+//
+// Returns:
+//   - *http.Response: The HTTP response from the API
+//   - error: Any error that occurred during the request
+func (c *Client) sendRequest(ctx context.Context, method string, targetURL *url.URL, params map[string]string, form bool) (*http.Response, error) {
 	// Create a copy of the URL to avoid modifying the original
 	requestURL := *targetURL
 
@@ -310,11 +876,16 @@ func (c *Client) performGetRequest(ctx context.Context, targetURL *url.URL, para
 		queries.Set(k, v)
 	}
 
-	// Encode the query parameters and set them on the URL
-	requestURL.RawQuery = queries.Encode()
+	// Encode the parameters and set them on the URL, or in the body
+	var body io.Reader
+	if form {
+		body = strings.NewReader(queries.Encode())
+	} else {
+		requestURL.RawQuery = queries.Encode()
+	}
 
 	// Create a new HTTP request with the provided context
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
@@ -322,7 +893,116 @@ func (c *Client) performGetRequest(ctx context.Context, targetURL *url.URL, para
 	// Set appropriate headers
 	req.Header.Set("User-Agent", "cloudns-go-client/1.0")
 	req.Header.Set("Accept", "application/json")
+	if form {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 
 	// Execute the request
-	return http.DefaultClient.Do(req)
+	req, trace := c.withTrace(req)
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	c.logTrace(ctx, targetURL.Path, trace)
+	zone := params["domain-name"]
+	trackRequest(ctx, targetURL.Path, zone)
+	if c.Metrics != nil {
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+		}
+		c.Metrics.ObserveRequest(targetURL.Path, zone, status, time.Since(start))
+	}
+	if c.Logger != nil {
+		attrs := []any{"method", method, "endpoint", targetURL.Path, "duration", time.Since(start)}
+		if id := OperationID(ctx); id != "" {
+			attrs = append(attrs, "operation", id)
+		}
+		if err != nil {
+			c.Logger.DebugContext(ctx, "ClouDNS API request failed", append(attrs, "error", err)...)
+		} else {
+			c.Logger.DebugContext(ctx, "ClouDNS API request", append(attrs, "status", resp.StatusCode)...)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, (&ApiError{Err: ErrRateLimited}).from(resp)
+	}
+	// Responses that are not announced as JSON are checked to be JSON, as
+	// proxies answer with HTML pages during maintenance
+	if resp.StatusCode == http.StatusOK && !isJSONContentType(resp.Header.Get("Content-Type")) {
+		bodyBytes, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API response: %w", err)
+		}
+		if !json.Valid(bodyBytes) {
+			return nil, newUnexpectedResponseError(resp, bodyBytes)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	}
+	if c.FallbackAuthPassword == "" || resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	// Peek at the response to detect rejected credentials, and hand the
+	// body on to the caller otherwise
+	bodyBytes, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+	var resultModel struct {
+		Status            string `json:"status"`
+		StatusDescription string `json:"statusDescription"`
+	}
+	if json.Unmarshal(bodyBytes, &resultModel) != nil || resultModel.Status != "Failed" || !isAuthenticationFailure(resultModel.StatusDescription) {
+		return resp, nil
+	}
+
+	if c.Logger != nil {
+		c.Logger.WarnContext(ctx, "ClouDNS rejected the credentials, retrying with the fallback credentials", "endpoint", targetURL.Path, "operation", OperationID(ctx))
+	}
+
+	fallback := UseClient(c.FallbackAuthId, c.FallbackSubAuthId, c.FallbackAuthPassword)
+	fallback.HTTPClient = c.HTTPClient
+	fallback.Logger = c.Logger
+	fallback.TraceRequests = c.TraceRequests
+	fallback.StrictDecoding = c.StrictDecoding
+	fallback.RecordsPerPage = c.RecordsPerPage
+	fallback.PageConcurrency = c.PageConcurrency
+	fallback.Metrics = c.Metrics
+	fallback.limiter = c.limiter
+	return fallback.sendRequest(ctx, method, targetURL, params, form)
+}
+
+// addAuthParams adds authentication parameters to the provided query values based on the client's credentials.
+// It selects between auth-id and sub-auth-id based on which is provided.
+//
+// Parameters:
+//   - queries: The url.Values to add authentication parameters to
+func (c *Client) addAuthParams(queries url.Values) {
+	// CloudNS API requires either auth-id or sub-auth-id, but not both
+	if c.SubAuthId != "" {
+		queries.Set("sub-auth-id", c.SubAuthId)
+	} else {
+		queries.Set("auth-id", c.AuthId)
+	}
+
+	// Always include the auth password
+	queries.Set("auth-password", c.AuthPassword)
 }
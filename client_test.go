@@ -0,0 +1,538 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// useTestServer points the API base URL at a local server running handler
+// for the duration of the test.
+func useTestServer(t testing.TB, handler http.HandlerFunc) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	original := apiBaseUrl
+	u, err := url.Parse(server.URL + "/dns/")
+	if err != nil {
+		t.Fatalf("Failed to parse test server URL: %v", err)
+	}
+	apiBaseUrl = u
+	t.Cleanup(func() { apiBaseUrl = original })
+}
+
+func TestGetAvailableTTLs(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/dns/get-available-ttl.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if zone := r.URL.Query().Get("domain-name"); zone != "example.com" {
+			t.Errorf("Unexpected zone %q", zone)
+		}
+		fmt.Fprint(w, `[300,60,3600]`)
+	})
+
+	c := UseClient("id", "", "password")
+	for range 2 {
+		ttls, err := c.GetAvailableTTLs(t.Context(), "example.com")
+		if err != nil {
+			t.Fatalf("Failed to get TTLs: %v", err)
+		}
+		if expected := []int{60, 300, 3600}; !reflect.DeepEqual(ttls, expected) {
+			t.Errorf("Expected %v, got %v", expected, ttls)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected TTL list to be fetched once, got %d requests", calls)
+	}
+}
+
+func TestStalledZoneLookup(t *testing.T) {
+	release := make(chan struct{})
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("domain-name") == "slow.com" {
+			<-release
+		}
+		switch r.URL.Path {
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","TXT"]`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+	defer close(release)
+
+	c := UseClient("id", "", "password")
+	go c.GetAvailableTTLs(t.Context(), "slow.com")
+	go c.GetAvailableRecordTypes(t.Context(), "slow.com")
+	time.Sleep(10 * time.Millisecond)
+
+	// The lookups of another zone go through while those of slow.com hang
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.GetAvailableTTLs(t.Context(), "example.com")
+		if err == nil {
+			_, err = c.GetAvailableRecordTypes(t.Context(), "example.com")
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Failed to look up example.com: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the lookups of example.com not to wait for slow.com")
+	}
+}
+
+func TestGetAvailableTTLsFailure(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":"Failed","statusDescription":"Invalid authentication, incorrect auth-id or auth-password."}`)
+	})
+
+	c := UseClient("id", "", "password")
+	if _, err := c.GetAvailableTTLs(t.Context(), "example.com"); err == nil {
+		t.Errorf("Expected an error for a failed status response")
+	}
+
+	if ttls, err := availableTTLs(t.Context(), c, "example.com"); ttls != nil || err != nil {
+		t.Errorf("Expected nil TTL list and no error on failure, got %v, %v", ttls, err)
+	}
+}
+
+func TestZoneNotFound(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"status":"Failed","statusDescription":"Missing domain-name"}`)
+	})
+
+	c := UseClient("id", "", "password")
+	if _, err := c.GetClouDNSRecords(t.Context(), "example.com"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound from GetClouDNSRecords, got %v", err)
+	}
+	if _, err := availableTTLs(t.Context(), c, "example.com"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound from availableTTLs, got %v", err)
+	}
+
+	calls = 0
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound from GetRecords, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retries for a missing zone, got %d requests", calls)
+	}
+}
+
+func TestGetRecordsSkipInactive(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"1": {"id": "1", "type": "A", "host": "on", "record": "192.0.2.1", "ttl": "60", "status": 1},
+			"2": {"id": "2", "type": "A", "host": "off", "record": "192.0.2.2", "ttl": "60", "status": 0}
+		}`)
+	})
+
+	for _, skip := range []bool{false, true} {
+		provider := &Provider{AuthId: "id", AuthPassword: "password", SkipInactive: skip}
+		recs, err := provider.GetRecords(t.Context(), "example.com")
+		if err != nil {
+			t.Fatalf("Failed to get records: %v", err)
+		}
+
+		active := map[string]bool{}
+		for _, rec := range recs {
+			active[rec.RR().Name] = rec.(libdns.Address).ProviderData.(RecordData).Active
+		}
+
+		expected := map[string]bool{"on": true, "off": false}
+		if skip {
+			expected = map[string]bool{"on": true}
+		}
+		if !reflect.DeepEqual(active, expected) {
+			t.Errorf("SkipInactive=%v: expected %v, got %v", skip, expected, active)
+		}
+	}
+}
+
+func TestGetAvailableNameServers(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"type":"free","name":"ns1.cloudns.net","ip4":"185.136.96.79","ip6":"2a06:fb00:1::1:79","location":"Sofia, Bulgaria","location_cc":"BG","ddos_protected":0},
+			{"type":"premium","name":"pns1.cloudns.net","ip4":"185.136.96.66","ip6":"","location":"Sofia, Bulgaria","location_cc":"BG","ddos_protected":1}
+		]`)
+	})
+
+	nameServers, err := UseClient("id", "", "password").GetAvailableNameServers(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get name servers: %v", err)
+	}
+
+	expected := []NameServer{
+		{
+			Type:       "free",
+			Name:       "ns1.cloudns.net",
+			IPv4:       netip.MustParseAddr("185.136.96.79"),
+			IPv6:       netip.MustParseAddr("2a06:fb00:1::1:79"),
+			Location:   "Sofia, Bulgaria",
+			LocationCC: "BG",
+		},
+		{
+			Type:       "premium",
+			Name:       "pns1.cloudns.net",
+			IPv4:       netip.MustParseAddr("185.136.96.66"),
+			Location:   "Sofia, Bulgaria",
+			LocationCC: "BG",
+		},
+	}
+	if !reflect.DeepEqual(nameServers, expected) {
+		t.Errorf("actual: %+v\n\nexpected: %+v", nameServers, expected)
+	}
+}
+
+func TestCheckRecordTypes(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"1.168.192.in-addr.arpa","type":"reverse","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			calls++
+			if zoneType := r.URL.Query().Get("zone-type"); zoneType != "reverse" {
+				t.Errorf("Unexpected zone type %q", zoneType)
+			}
+			fmt.Fprint(w, `["ns","ptr","cname","txt"]`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	client := UseClient("id", "", "password")
+	zone := "1.168.192.in-addr.arpa"
+	ptr := libdns.RR{Name: "10", Type: "PTR", Data: "host.example.com."}
+	if err := checkRecordTypes(t.Context(), client, zone, []libdns.Record{ptr}); err != nil {
+		t.Errorf("Expected PTR records to be accepted, got %v", err)
+	}
+	alias := libdns.RR{Name: "10", Type: "TYPE16", Data: "hello"}
+	if err := checkRecordTypes(t.Context(), client, zone, []libdns.Record{alias}); err != nil {
+		t.Errorf("Expected the alias of TXT to be accepted, got %v", err)
+	}
+
+	address := libdns.Address{Name: "10", IP: netip.MustParseAddr("192.0.2.1")}
+	if err := checkRecordTypes(t.Context(), client, zone, []libdns.Record{address}); !errors.Is(err, ErrInvalidRecord) {
+		t.Errorf("Expected ErrInvalidRecord for A records, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected the record types to be fetched once, got %d calls", calls)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{"1":{"id":"1","type":"A","host":"www","record":"192.0.2.1","ttl":"60","status":1}}`)
+		default:
+			t.Errorf("Unexpected request to %q", r.URL.Path)
+		}
+	})
+
+	c := UseClient("id", "", "password")
+	c.ReadOnly = true
+	if _, err := c.GetClouDNSRecords(t.Context(), "example.com"); err != nil {
+		t.Errorf("Expected reads to succeed, got %v", err)
+	}
+	if err := c.DeleteRecord(t.Context(), "example.com", "1"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from DeleteRecord, got %v", err)
+	}
+	if err := c.TriggerZoneUpdate(t.Context(), "example.com"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from TriggerZoneUpdate, got %v", err)
+	}
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", ReadOnly: true}
+	records := []libdns.Record{libdns.TXT{Name: "www", Text: "hello"}}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Errorf("Expected GetRecords to succeed, got %v", err)
+	}
+	if _, err := provider.AppendRecords(t.Context(), "example.com", records); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from AppendRecords, got %v", err)
+	}
+	if _, err := provider.SetRecords(t.Context(), "example.com", records); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from SetRecords, got %v", err)
+	}
+	if _, err := provider.DeleteRecords(t.Context(), "example.com", records); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from DeleteRecords, got %v", err)
+	}
+}
+
+func TestFallbackCredentials(t *testing.T) {
+	var passwords []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		password := r.URL.Query().Get("auth-password")
+		passwords = append(passwords, password)
+		if password != "secondary" {
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Invalid authentication, incorrect auth-id or auth-password."}`)
+			return
+		}
+		fmt.Fprint(w, `{"1":{"id":"1","type":"A","host":"www","record":"192.0.2.1","ttl":"60","status":1}}`)
+	})
+
+	c := UseClient("id", "", "primary")
+	if _, err := c.GetClouDNSRecords(t.Context(), "example.com"); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("Expected ErrAuthenticationFailed without fallback, got %v", err)
+	}
+
+	passwords = nil
+	provider := &Provider{AuthId: "id", AuthPassword: "primary", FallbackAuthId: "id", FallbackAuthPassword: "secondary"}
+	records, err := provider.GetRecords(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Expected the fallback credentials to be used, got %v", err)
+	}
+	if len(records) != 1 || !reflect.DeepEqual(passwords, []string{"primary", "secondary"}) {
+		t.Errorf("Unexpected records %v after requests with %v", records, passwords)
+	}
+
+	passwords = nil
+	provider.FallbackAuthPassword = "wrong"
+	if _, err := provider.GetRecords(t.Context(), "example.com"); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("Expected ErrAuthenticationFailed, got %v", err)
+	}
+	if len(passwords) != 2 {
+		t.Errorf("Expected no retries for rejected credentials, got %v", passwords)
+	}
+}
+
+func TestIPNotAllowed(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"status":"Failed","statusDescription":"Your IP address 198.51.100.7 is not allowed to use the API."}`)
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+	_, err := provider.GetRecords(t.Context(), "example.com")
+	if !errors.Is(err, ErrIPNotAllowed) {
+		t.Fatalf("Expected ErrIPNotAllowed, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "198.51.100.7") {
+		t.Errorf("Expected the status description in the error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retries for a rejected IP address, got %d requests", calls)
+	}
+	for _, description := range []string{
+		"Multiple records with the same host are not allowed.",
+		"The description of the record is not allowed.",
+		"Invalid zip code, not allowed.",
+	} {
+		if isIPNotAllowed(description) || errors.Is(newAPIError(description), ErrIPNotAllowed) {
+			t.Errorf("Expected %q not to be taken for a rejected IP address", description)
+		}
+	}
+}
+
+func TestApiError(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/delete-record.json":
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Invalid record-id."}`)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>Bad Gateway</html>")
+		}
+	})
+
+	c := UseClient("id", "", "secret")
+	err := c.DeleteRecord(t.Context(), "example.com", "42")
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an ApiError, got %v", err)
+	}
+	expected := &ApiError{
+		Endpoint:          "dns/delete-record.json",
+		HTTPStatus:        http.StatusOK,
+		Status:            "Failed",
+		StatusDescription: "Invalid record-id.",
+		Params:            map[string]string{"domain-name": "example.com", "record-id": "42"},
+	}
+	if !reflect.DeepEqual(apiErr, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, apiErr)
+	}
+	if isRetryable(err) {
+		t.Errorf("Expected a rejected record ID to be permanent")
+	}
+
+	err = c.performStatusRequest(t.Context(), apiBaseUrl.JoinPath("..", "sub-users", "change-password.json"), map[string]string{"id": "7", "password": "hunter2"})
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an ApiError, got %v", err)
+	}
+	expected = &ApiError{
+		Endpoint:          "sub-users/change-password.json",
+		HTTPStatus:        http.StatusBadGateway,
+		StatusDescription: "<html>Bad Gateway</html>",
+		Params:            map[string]string{"id": "7", "password": redactedSecret},
+		Err:               ErrUnexpectedResponse,
+	}
+	if !reflect.DeepEqual(apiErr, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, apiErr)
+	}
+	if strings.Contains(err.Error(), "secret") || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected no secrets in the error, got %v", err)
+	}
+	if !isRetryable(err) {
+		t.Errorf("Expected an unexpected HTTP status to be retryable")
+	}
+}
+
+func TestRetryClassification(t *testing.T) {
+	tests := []struct {
+		description string
+		retryable   bool
+	}{
+		{description: "Zone is updating, please try again later.", retryable: true},
+		{description: "Temporary error, please retry.", retryable: true},
+		{description: "Invalid record-type.", retryable: false},
+		{description: "Missing domain-name", retryable: false},
+	}
+
+	for _, tt := range tests {
+		if retryable := isRetryable(newAPIError(tt.description)); retryable != tt.retryable {
+			t.Errorf("%q: expected retryable %t, got %t", tt.description, tt.retryable, retryable)
+		}
+	}
+
+	if !isRetryable(errors.New("connection reset by peer")) {
+		t.Errorf("Expected transport errors to be retryable")
+	}
+
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"status":"Failed","statusDescription":"Record limit reached, please contact support."}`)
+	})
+
+	clock := &fakeClock{}
+	provider, err := NewProvider(WithCredentials("id", "password"), WithRetries(3), WithClock(clock.After))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err == nil || calls != 1 {
+		t.Errorf("Expected a single attempt for a permanent failure, got %d: %v", calls, err)
+	}
+
+	calls = 0
+	provider.RetryableStatusDescriptions = []string{"RECORD LIMIT"}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err == nil || calls != 3 {
+		t.Errorf("Expected the configured failure to be retried, got %d attempts: %v", calls, err)
+	}
+}
+
+func TestRateLimitCooldown(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Too many requests, the rate limit was exceeded."}`)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 3:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	clock := &fakeClock{}
+	provider := &Provider{AuthId: "id", AuthPassword: "password", InitialBackoff: time.Second, RateLimitCooldown: 2 * time.Minute}
+	provider.after = clock.After
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+
+	expected := []time.Duration{2 * time.Minute, 2 * time.Minute, time.Second}
+	if !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf("Expected waits %v, got %v", expected, clock.waits)
+	}
+}
+
+func TestEmptyZoneRecords(t *testing.T) {
+	body := `[]`
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	})
+
+	c := UseClient("id", "", "password")
+	records, err := c.GetClouDNSRecords(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Expected an empty zone to be decoded, got %v", err)
+	}
+	if records == nil || len(records) != 0 {
+		t.Errorf("Expected an empty slice of records, got %#v", records)
+	}
+
+	body = ` [{"id": "1", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "60", "status": 1},
+		{"id": "2", "type": "TXT", "host": "www", "record": "hello", "ttl": "60", "status": 1}]`
+	records, err = c.GetFilteredClouDNSRecords(t.Context(), "example.com", RecordFilter{Type: "TXT"})
+	if err != nil {
+		t.Fatalf("Expected an array of records to be decoded, got %v", err)
+	}
+	if len(records) != 1 || records[0].Id != "2" {
+		t.Errorf("Expected the TXT record only, got %+v", records)
+	}
+}
+
+func TestUnexpectedResponse(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+			fmt.Fprint(w, "<!DOCTYPE html>\n<html>\n  <title>Just a moment...</title>\n</html>")
+			return
+		}
+
+		// JSON is accepted whatever its announced content type
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `{"1": {"id": "1", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "60", "status": 1}}`)
+	})
+
+	c := UseClient("id", "", "password")
+	_, err := c.GetClouDNSRecords(t.Context(), "example.com")
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Fatalf("Expected ErrUnexpectedResponse, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "<!DOCTYPE html> <html> <title>Just a moment...</title> </html>") {
+		t.Errorf("Expected a snippet of the page in the error, got %v", err)
+	}
+	if !isRetryable(err) {
+		t.Errorf("Expected unexpected responses to be retryable")
+	}
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+	provider.after = (&fakeClock{}).After
+	calls = 0
+	if records, err := provider.GetRecords(t.Context(), "example.com"); err != nil || len(records) != 1 {
+		t.Errorf("Expected the provider to retry after the unexpected response, got %v, %v", records, err)
+	}
+}
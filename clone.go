@@ -0,0 +1,159 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// CloneStep is a step of CloneZone.
+type CloneStep int
+
+const (
+	// CloneExporting is reported before the zone is exported from the
+	// source account
+	CloneExporting CloneStep = iota
+
+	// CloneCreatingZone is reported before the zone is created in the target
+	// account, if it is missing there and CloneOptions.CreateZone is set
+	CloneCreatingZone
+
+	// CloneImporting is reported before the records are imported into the
+	// target account
+	CloneImporting
+
+	// CloneVerifying is reported before the records of the target account
+	// are compared with those of the source account
+	CloneVerifying
+
+	// CloneDone is reported once the zone was cloned
+	CloneDone
+)
+
+func (s CloneStep) String() string {
+	switch s {
+	case CloneExporting:
+		return "exporting"
+	case CloneCreatingZone:
+		return "creating zone"
+	case CloneImporting:
+		return "importing"
+	case CloneVerifying:
+		return "verifying"
+	case CloneDone:
+		return "done"
+	}
+
+	return fmt.Sprintf("CloneStep(%d)", int(s))
+}
+
+// CloneProgress describes how far CloneZone got.
+type CloneProgress struct {
+	// Step is the step CloneZone is about to take
+	Step CloneStep
+
+	// Zone is the zone being cloned
+	Zone string
+
+	// Records is the number of records of the source zone, known once it
+	// was exported
+	Records int
+
+	// Cloned is the number of records of the source zone found in the
+	// target zone, known once the clone was verified
+	Cloned int
+}
+
+// CloneOptions tune CloneZone.
+type CloneOptions struct {
+	// CreateZone creates the zone as a master zone in the target account if
+	// it does not exist there yet. Otherwise a missing zone fails the clone
+	// with ErrZoneNotFound.
+	CreateZone bool
+
+	// DeleteExisting deletes the records already in the zone of the target
+	// account before the import
+	DeleteExisting bool
+
+	// Progress is called before each step of the clone, if set
+	Progress func(CloneProgress)
+}
+
+// CloneZone copies the records of a zone from the account of one client to
+// the same zone in the account of another, e.g. to migrate a customer between
+// ClouDNS accounts. The zone is exported from the source account as a BIND
+// zone file and imported into the target account in bulk, after which the
+// target zone is checked to hold all the records of the source zone. NS and
+// SOA records are managed by each account and are not checked.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - from: The client of the source account
+//   - to: The client of the target account
+//   - zone: The DNS zone (domain) to clone
+//   - opts: How to clone the zone
+//
+// Returns:
+//   - error: Any error that occurred during the operation, wrapping
+//     ErrCloneIncomplete if records are missing from the target zone
+func CloneZone(ctx context.Context, from, to *Client, zone string, opts CloneOptions) error {
+	zone = normalizeZone(zone)
+	progress := CloneProgress{Zone: zone}
+	report := func(step CloneStep) {
+		progress.Step = step
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	report(CloneExporting)
+	source, err := from.GetClouDNSRecords(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("failed to get the records of zone %q: %w", zone, err)
+	}
+	source = slices.DeleteFunc(source, func(record ApiDnsRecord) bool {
+		return slices.Contains(DefaultProtectedTypes, canonicalRecordType(record.Type))
+	})
+	progress.Records = len(source)
+
+	content, err := from.ExportZone(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("failed to export zone %q: %w", zone, err)
+	}
+
+	if opts.CreateZone {
+		_, err := to.GetZoneInfo(ctx, zone)
+		if errors.Is(err, ErrZoneNotFound) {
+			report(CloneCreatingZone)
+			err = to.CreateZone(ctx, zone, CreateZoneOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create zone %q: %w", zone, err)
+		}
+	}
+
+	report(CloneImporting)
+	if err := to.ImportZone(ctx, zone, ImportFormatBIND, content, opts.DeleteExisting); err != nil {
+		return fmt.Errorf("failed to import zone %q: %w", zone, err)
+	}
+
+	report(CloneVerifying)
+	target, err := to.GetClouDNSRecords(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("failed to get the records of zone %q: %w", zone, err)
+	}
+	for _, record := range source {
+		if slices.ContainsFunc(target, func(cloned ApiDnsRecord) bool {
+			return compareIDlessRecord(record, cloned)
+		}) {
+			progress.Cloned++
+		}
+	}
+	if progress.Cloned < progress.Records {
+		return fmt.Errorf("%w: %d of %d records of zone %q are missing", ErrCloneIncomplete, progress.Records-progress.Cloned, progress.Records, zone)
+	}
+
+	report(CloneDone)
+	return nil
+}
@@ -0,0 +1,76 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCloneZone(t *testing.T) {
+	// Zone files of realistic size do not fit in a URL
+	zoneFile := "$ORIGIN example.com.\nwww\t300\tIN\tA\t192.0.2.1\n" +
+		strings.Repeat("txt\t300\tIN\tTXT\t\"v=verification-token-0123456789abcdef\"\n", 200)
+	lost := false
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		account := r.Form.Get("auth-id")
+		switch account + " " + r.URL.Path {
+		case "source /dns/records.json":
+			fmt.Fprint(w, `{
+				"1": {"id": "1", "type": "NS", "host": "", "record": "ns1.cloudns.net", "ttl": "3600", "status": 1},
+				"2": {"id": "2", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "300", "status": 1}
+			}`)
+		case "source /dns/records-export.json":
+			fmt.Fprintf(w, `{"status":"Success","zone":%q}`, zoneFile)
+		case "target /dns/get-zone-info.json":
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Missing domain-name"}`)
+		case "target /dns/register.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"Domain zone example.com was created successfully."}`)
+		case "target /dns/records-import.json":
+			if r.URL.RawQuery != "" || r.PostForm.Get("format") != "bind" || r.PostForm.Get("content") != zoneFile {
+				t.Errorf("Unexpected import of %d bytes in format %q with query %q", len(r.PostForm.Get("content")), r.PostForm.Get("format"), r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The records were imported successfully."}`)
+		case "target /dns/records.json":
+			if lost {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `{"7": {"id": "7", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "300", "status": 1}}`)
+		default:
+			t.Errorf("Unexpected request %s %s", account, r.URL.Path)
+		}
+	})
+
+	var steps []CloneStep
+	from, to := UseClient("source", "", "password"), UseClient("target", "", "password")
+	err := CloneZone(t.Context(), from, to, "example.com.", CloneOptions{
+		CreateZone: true,
+		Progress: func(progress CloneProgress) {
+			steps = append(steps, progress.Step)
+			if progress.Step == CloneDone && (progress.Records != 1 || progress.Cloned != 1) {
+				t.Errorf("Expected 1 record to be cloned, got %+v", progress)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to clone zone: %v", err)
+	}
+
+	expected := []CloneStep{CloneExporting, CloneCreatingZone, CloneImporting, CloneVerifying, CloneDone}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("Expected steps %v, got %v", expected, steps)
+	}
+
+	// Records missing after the import fail the clone
+	lost = true
+	err = CloneZone(t.Context(), from, to, "example.com", CloneOptions{})
+	if !errors.Is(err, ErrCloneIncomplete) {
+		t.Errorf("Expected ErrCloneIncomplete, got %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// ProviderOption configures a Provider created with NewProvider.
+type ProviderOption func(*Provider)
+
+// WithCredentials authenticates the provider as the main user of the account.
+func WithCredentials(authId, authPassword string) ProviderOption {
+	return func(p *Provider) {
+		p.AuthId = authId
+		p.AuthPassword = authPassword
+	}
+}
+
+// WithSubUserCredentials authenticates the provider as an API sub-user.
+func WithSubUserCredentials(subAuthId, authPassword string) ProviderOption {
+	return func(p *Provider) {
+		p.SubAuthId = subAuthId
+		p.AuthPassword = authPassword
+	}
+}
+
+// WithRetries sets the number of attempts made for every operation before
+// giving up.
+func WithRetries(retries int) ProviderOption {
+	return func(p *Provider) {
+		p.OperationRetries = retries
+	}
+}
+
+// WithBackoff sets the delay before the first retry of an operation, and the
+// maximum delay the exponential backoff grows to.
+func WithBackoff(initial, maximum time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.InitialBackoff = initial
+		p.MaxBackoff = maximum
+	}
+}
+
+// WithConcurrency makes SetRecords apply up to n changes to different names
+// at the same time, and the provider fetch up to n pages of records at the
+// same time, see Provider.Concurrency.
+func WithConcurrency(n int) ProviderOption {
+	return func(p *Provider) {
+		p.Concurrency = n
+	}
+}
+
+// WithRecordsPerPage makes the provider fetch the records of a zone in pages
+// of the given size, see Provider.RecordsPerPage.
+func WithRecordsPerPage(n int) ProviderOption {
+	return func(p *Provider) {
+		p.RecordsPerPage = n
+	}
+}
+
+// WithMutationDelay makes the provider wait the given delay between the
+// changes it makes to a zone, see Provider.MutationDelay.
+func WithMutationDelay(delay time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.MutationDelay = delay
+	}
+}
+
+// WithOperationTimeout bounds every operation of the provider whose context
+// has no deadline of its own.
+func WithOperationTimeout(timeout time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.OperationTimeout = timeout
+	}
+}
+
+// WithRetryBudget caps the number of retries made across all the requests of
+// a single call to AppendRecords, SetRecords or DeleteRecords.
+func WithRetryBudget(retries int) ProviderOption {
+	return func(p *Provider) {
+		p.RetryBudget = retries
+	}
+}
+
+// WithLogger makes the provider log the requests it sends at debug level.
+func WithLogger(logger *slog.Logger) ProviderOption {
+	return func(p *Provider) {
+		p.logger = logger
+	}
+}
+
+// WithMetrics makes the provider report its requests, retries and rate
+// limited requests to the given Metrics.
+func WithMetrics(metrics Metrics) ProviderOption {
+	return func(p *Provider) {
+		p.metrics = metrics
+	}
+}
+
+// WithAuditSink makes the provider report every change it attempts to the
+// given AuditSink.
+func WithAuditSink(sink AuditSink) ProviderOption {
+	return func(p *Provider) {
+		p.auditSink = sink
+	}
+}
+
+// WithAuditLog makes the provider write every change it attempts as a line
+// of JSON to w, see NewJSONAuditSink.
+func WithAuditLog(w io.Writer) ProviderOption {
+	return WithAuditSink(NewJSONAuditSink(w))
+}
+
+// WithHTTPTrace makes the provider log the DNS lookup, connection reuse, TLS
+// handshake and time to first byte of every request it sends, at debug level
+// to the logger set with WithLogger, to diagnose slow requests.
+func WithHTTPTrace() ProviderOption {
+	return func(p *Provider) {
+		p.trace = true
+	}
+}
+
+// WithHTTPClient makes the provider send its requests with the given client
+// instead of http.DefaultClient, e.g. to set timeouts or a proxy.
+func WithHTTPClient(client *http.Client) ProviderOption {
+	return func(p *Provider) {
+		p.httpClient = client
+	}
+}
+
+// WithRateLimit spaces the requests of the provider so that no more than
+// requestsPerSecond of them are sent per second, across all operations.
+func WithRateLimit(requestsPerSecond float64) ProviderOption {
+	return func(p *Provider) {
+		p.limiter = nil
+		if requestsPerSecond > 0 {
+			p.limiter = newRateLimiter(requestsPerSecond)
+		}
+	}
+}
+
+// WithCache makes the provider keep the accepted TTLs and record types of the
+// zones across operations for the given duration, instead of looking them up
+// again for every operation.
+func WithCache(expiry time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.cache = &zoneCache{expiry: expiry}
+	}
+}
+
+// WithClock makes the provider wait between retries, and between polls in
+// WaitForPropagation, on the channels returned by after instead of on real
+// time, e.g. so that tests can fast-forward through the backoff.
+func WithClock(after func(time.Duration) <-chan time.Time) ProviderOption {
+	return func(p *Provider) {
+		p.after = after
+	}
+}
+
+// WithAPI makes the provider work with the given implementation of the
+// ClouDNS API instead of a Client, e.g. a mock in unit tests. No credentials
+// are needed then.
+func WithAPI(api API) ProviderOption {
+	return func(p *Provider) {
+		p.api = api
+	}
+}
+
+// NewProvider creates a Provider configured by the given options, and
+// validates the resulting configuration so that mistakes are reported up
+// front instead of failing at the first API call.
+//
+// Parameters:
+//   - opts: The options to apply, in order
+//
+// Returns:
+//   - *Provider: The configured provider
+//   - error: The configuration errors, each wrapping ErrInvalidConfig
+func NewProvider(opts ...ProviderOption) (*Provider, error) {
+	p := &Provider{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Validate checks the configuration of the provider, e.g. after decoding it
+// from JSON. All problems found are joined in the returned error, each
+// wrapping ErrInvalidConfig.
+func (p *Provider) Validate() error {
+	p.credentialsMu.RLock()
+	defer p.credentialsMu.RUnlock()
+
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...)))
+	}
+
+	// The credentials are left to the API set with WithAPI
+	needsCredentials := p.api == nil
+	switch {
+	case p.AuthId != "" && p.SubAuthId != "":
+		invalid("AuthId and SubAuthId are mutually exclusive")
+	case needsCredentials && p.AuthId == "" && p.SubAuthId == "":
+		invalid("one of AuthId and SubAuthId is required")
+	}
+	switch {
+	case p.AuthPassword != "" && p.AuthPasswordFile != "":
+		invalid("AuthPassword and AuthPasswordFile are mutually exclusive")
+	case needsCredentials && p.AuthPassword == "" && p.AuthPasswordFile == "":
+		invalid("one of AuthPassword and AuthPasswordFile is required")
+	}
+
+	if p.FallbackAuthPassword != "" && (p.FallbackAuthId == "") == (p.FallbackSubAuthId == "") {
+		invalid("exactly one of FallbackAuthId and FallbackSubAuthId is required with FallbackAuthPassword")
+	}
+
+	if p.OperationRetries < 0 {
+		invalid("OperationRetries must not be negative, got %d", p.OperationRetries)
+	}
+	if p.OperationTimeout < 0 {
+		invalid("OperationTimeout must not be negative, got %s", p.OperationTimeout)
+	}
+	if p.RetryBudget < 0 {
+		invalid("RetryBudget must not be negative, got %d", p.RetryBudget)
+	}
+	if p.InitialBackoff < 0 {
+		invalid("InitialBackoff must not be negative, got %s", p.InitialBackoff)
+	}
+	if p.MaxBackoff < 0 {
+		invalid("MaxBackoff must not be negative, got %s", p.MaxBackoff)
+	}
+	if p.RateLimitCooldown < 0 {
+		invalid("RateLimitCooldown must not be negative, got %s", p.RateLimitCooldown)
+	}
+	if p.ZoneLockedPause < 0 {
+		invalid("ZoneLockedPause must not be negative, got %s", p.ZoneLockedPause)
+	}
+	if p.Concurrency < 0 {
+		invalid("Concurrency must not be negative, got %d", p.Concurrency)
+	}
+	if p.RecordsPerPage != 0 && !slices.Contains(validRowsPerPage, p.RecordsPerPage) {
+		invalid("RecordsPerPage must be one of %v, got %d", validRowsPerPage, p.RecordsPerPage)
+	}
+	if p.MutationDelay < 0 {
+		invalid("MutationDelay must not be negative, got %s", p.MutationDelay)
+	}
+	if initial, maximum := p.getInitialBackoff(), p.getMaxBackoff(); initial > maximum {
+		invalid("InitialBackoff %s exceeds MaxBackoff %s", initial, maximum)
+	}
+
+	for _, zone := range p.AllowedZones {
+		if normalizeZone(zone) == "" {
+			invalid("AllowedZones must not contain empty zone names")
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,196 @@
+package cloudns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider(WithSubUserCredentials("42", "password"))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if provider.SubAuthId != "42" || provider.AuthPassword != "password" {
+		t.Errorf("Unexpected provider %+v", provider)
+	}
+
+	tests := []struct {
+		name     string
+		provider *Provider
+		problems []string
+	}{
+		{
+			name:     "missing credentials",
+			provider: &Provider{},
+			problems: []string{"one of AuthId and SubAuthId", "one of AuthPassword and AuthPasswordFile"},
+		},
+		{
+			name:     "password and password file",
+			provider: &Provider{AuthId: "1", AuthPassword: "password", AuthPasswordFile: "/run/secrets/cloudns"},
+			problems: []string{"mutually exclusive"},
+		},
+		{
+			name:     "both IDs",
+			provider: &Provider{AuthId: "1", SubAuthId: "2", AuthPassword: "password"},
+			problems: []string{"mutually exclusive"},
+		},
+		{
+			name:     "incomplete fallback",
+			provider: &Provider{AuthId: "1", AuthPassword: "password", FallbackAuthPassword: "other"},
+			problems: []string{"FallbackAuthId"},
+		},
+		{
+			name:     "negative retries",
+			provider: &Provider{AuthId: "1", AuthPassword: "password", OperationRetries: -1},
+			problems: []string{"OperationRetries"},
+		},
+		{
+			name:     "inverted backoff",
+			provider: &Provider{AuthId: "1", AuthPassword: "password", InitialBackoff: time.Minute, MaxBackoff: time.Second},
+			problems: []string{"exceeds MaxBackoff"},
+		},
+		{
+			name:     "backoff above the default maximum",
+			provider: &Provider{AuthId: "1", AuthPassword: "password", InitialBackoff: time.Hour},
+			problems: []string{"exceeds MaxBackoff"},
+		},
+		{
+			name:     "unsupported page size",
+			provider: &Provider{AuthId: "1", AuthPassword: "password", RecordsPerPage: 25},
+			problems: []string{"RecordsPerPage must be one of [10 20 30 50 100], got 25"},
+		},
+		{
+			name:     "empty allowed zone",
+			provider: &Provider{AuthId: "1", AuthPassword: "password", AllowedZones: []string{"example.com", "."}},
+			problems: []string{"AllowedZones"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.provider.Validate()
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+			}
+			for _, problem := range tt.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("Expected %q to be reported, got %v", problem, err)
+				}
+			}
+		})
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDefaultCache(t *testing.T) {
+	lookups := map[string]int{}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lookups[r.URL.Path]++
+		switch r.URL.Path {
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","TXT"]`)
+		case "/dns/add-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":1}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	// Providers configured from JSON, e.g. by Caddy, have no options applied
+	var provider Provider
+	if err := json.Unmarshal([]byte(`{"auth_id":"id","auth_password":"password"}`), &provider); err != nil {
+		t.Fatalf("Failed to decode provider: %v", err)
+	}
+	for range 2 {
+		records := []libdns.Record{libdns.TXT{Name: "www", TTL: time.Minute, Text: "hello"}}
+		if _, err := provider.AppendRecords(t.Context(), "example.com", records); err != nil {
+			t.Fatalf("Failed to append records: %v", err)
+		}
+	}
+
+	for _, path := range []string{"/dns/get-available-ttl.json", "/dns/get-zone-info.json", "/dns/get-available-record-types.json"} {
+		if lookups[path] != 1 {
+			t.Errorf("Expected a single request to %s, got %d", path, lookups[path])
+		}
+	}
+}
+
+func TestProviderOptions(t *testing.T) {
+	ttlLookups := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/get-available-ttl.json":
+			ttlLookups++
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","AAAA","CNAME","TXT"]`)
+		case "/dns/add-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":1}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	var logs bytes.Buffer
+	transport := &countingTransport{}
+	provider, err := NewProvider(
+		WithCredentials("id", "password"),
+		WithRetries(2),
+		WithBackoff(time.Millisecond, time.Second),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithRateLimit(200),
+		WithCache(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if provider.OperationRetries != 2 || provider.InitialBackoff != time.Millisecond || provider.MaxBackoff != time.Second {
+		t.Errorf("Unexpected retry settings %+v", provider)
+	}
+
+	start := time.Now()
+	for range 2 {
+		records := []libdns.Record{libdns.TXT{Name: "www", TTL: time.Minute, Text: "hello"}}
+		if _, err := provider.AppendRecords(t.Context(), "example.com", records); err != nil {
+			t.Fatalf("Failed to append records: %v", err)
+		}
+	}
+
+	// 1 TTL, 1 zone info and 1 record type lookup, then 2 additions
+	if transport.requests != 5 {
+		t.Errorf("Expected 5 requests through the HTTP client, got %d", transport.requests)
+	}
+	if ttlLookups != 1 {
+		t.Errorf("Expected the TTLs to be cached across operations, got %d lookups", ttlLookups)
+	}
+	if elapsed := time.Since(start); elapsed < 4*5*time.Millisecond {
+		t.Errorf("Expected the requests to be spaced by the rate limit, took %s", elapsed)
+	}
+	if !strings.Contains(logs.String(), "endpoint=/dns/add-record.json") || strings.Contains(logs.String(), "password") {
+		t.Errorf("Unexpected logs:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,151 @@
+package cloudns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// decodeJSON decodes the body of an API response from the endpoint into v.
+// With StrictDecoding, fields v has no place for are logged and fail the
+// decoding with ErrUnknownField.
+func (c *Client) decodeJSON(ctx context.Context, endpoint string, data []byte, v any) error {
+	if !c.StrictDecoding {
+		return json.Unmarshal(data, v)
+	}
+
+	// Status objects are decoded by ApiResponse.UnmarshalJSON, which does not
+	// see the decoder settings
+	if _, ok := v.(*ApiResponse); ok {
+		if err := c.checkResponseFields(ctx, endpoint, data); err != nil {
+			return err
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err != nil && strings.HasPrefix(err.Error(), "json: unknown field") {
+		return c.unknownField(ctx, endpoint, strings.TrimPrefix(err.Error(), "json: unknown field "))
+	}
+
+	return err
+}
+
+// decodeResponse reads the body of the response and decodes it into v, see
+// decodeJSON.
+func (c *Client) decodeResponse(ctx context.Context, resp *http.Response, v any) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var endpoint string
+	if resp.Request != nil {
+		endpoint = resp.Request.URL.Path
+	}
+
+	return c.decodeJSON(ctx, endpoint, data, v)
+}
+
+// checkRecordFields reports the first unknown field of the records in a
+// records.json response with StrictDecoding. The records are decoded by
+// ApiDnsRecord.UnmarshalJSON, which does not see the decoder settings, so
+// their fields are compared with knownRecordFields instead.
+func (c *Client) checkRecordFields(ctx context.Context, endpoint string, data []byte) error {
+	if !c.StrictDecoding {
+		return nil
+	}
+
+	// Other shapes, like status objects, are handled by the decoding itself
+	var records map[string]map[string]json.RawMessage
+	if json.Unmarshal(data, &records) != nil {
+		return nil
+	}
+
+	known := knownRecordFields()
+	for _, id := range slices.Sorted(maps.Keys(records)) {
+		for _, field := range slices.Sorted(maps.Keys(records[id])) {
+			if _, ok := known[field]; !ok {
+				return c.unknownField(ctx, endpoint, fmt.Sprintf("%q of record %s", field, id))
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkResponseFields reports the first unknown field of a status object.
+func (c *Client) checkResponseFields(ctx context.Context, endpoint string, data []byte) error {
+	var response map[string]json.RawMessage
+	if json.Unmarshal(data, &response) != nil {
+		return nil
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(response)) {
+		switch field {
+		case "status", "statusDescription":
+		case "data":
+			var responseData map[string]json.RawMessage
+			if json.Unmarshal(response[field], &responseData) != nil {
+				continue
+			}
+			for _, dataField := range slices.Sorted(maps.Keys(responseData)) {
+				if dataField != "id" {
+					return c.unknownField(ctx, endpoint, fmt.Sprintf("%q of data", dataField))
+				}
+			}
+		default:
+			return c.unknownField(ctx, endpoint, fmt.Sprintf("%q", field))
+		}
+	}
+
+	return nil
+}
+
+// unknownField logs an unknown field of a response and returns the error
+// for it.
+func (c *Client) unknownField(ctx context.Context, endpoint string, field string) error {
+	if c.Logger != nil {
+		c.Logger.WarnContext(ctx, "Unknown field in ClouDNS API response", "endpoint", endpoint, "field", field, "operation", OperationID(ctx))
+	}
+
+	return fmt.Errorf("%w %s in response of %s", ErrUnknownField, field, endpoint)
+}
+
+// ignoredRecordFields are fields of the records returned by records.json
+// that ApiDnsRecord leaves out on purpose, as they are managed through their
+// own endpoints.
+var ignoredRecordFields = []string{"dynamicurl_status", "failover"}
+
+// knownRecordFields returns the fields of the records returned by
+// records.json that ApiDnsRecord decodes, directly or into its Record field.
+var knownRecordFields = sync.OnceValue(func() map[string]struct{} {
+	known := make(map[string]struct{})
+
+	recordType := reflect.TypeFor[ApiDnsRecord]()
+	for idx := range recordType.NumField() {
+		name, _, _ := strings.Cut(recordType.Field(idx).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known[name] = struct{}{}
+		}
+	}
+	for _, field := range ignoredRecordFields {
+		known[field] = struct{}{}
+	}
+	for _, fields := range genericRDATA {
+		for _, field := range fields {
+			known[field.key] = struct{}{}
+		}
+	}
+
+	return known
+})
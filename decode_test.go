@@ -0,0 +1,73 @@
+package cloudns
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	records := `{
+		"1": {"id": "1", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "60", "status": 1},
+		"2": {"id": "2", "type": "NAPTR", "host": "sip", "ttl": "3600", "status": 1, "order": "100", "pref": "10", "flag": "S", "params": "SIP+D2U", "regexp": "", "replace": "_sip._udp.example.com"}
+	}`
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, records)
+		case "/dns/delete-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was deleted successfully.","serial":2024}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	var logs bytes.Buffer
+	c := UseClient("id", "", "password")
+	c.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	// Unknown fields are ignored by default
+	if _, err := c.GetClouDNSRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+	if err := c.DeleteRecord(t.Context(), "example.com", "1"); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+
+	c.StrictDecoding = true
+	if _, err := c.GetClouDNSRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Expected all the record fields to be known, got %v", err)
+	}
+
+	err := c.DeleteRecord(t.Context(), "example.com", "1")
+	if !errors.Is(err, ErrUnknownField) || !strings.Contains(err.Error(), `"serial"`) {
+		t.Errorf("Expected ErrUnknownField for the serial, got %v", err)
+	}
+	if isRetryable(err) {
+		t.Errorf("Expected unknown fields not to be retried")
+	}
+
+	records = `{"1": {"id": "1", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "60", "status": 1, "dnssec": "1"}}`
+	if _, err := c.GetClouDNSRecords(t.Context(), "example.com"); !errors.Is(err, ErrUnknownField) || !strings.Contains(err.Error(), `"dnssec" of record 1`) {
+		t.Errorf("Expected ErrUnknownField for the record field, got %v", err)
+	}
+
+	for _, field := range []string{`field="\"serial\""`, `dnssec`} {
+		if !strings.Contains(logs.String(), field) {
+			t.Errorf("Expected the unknown field %s to be logged:\n%s", field, logs.String())
+		}
+	}
+}
+
+func TestStrictDecodingReplay(t *testing.T) {
+	provider, zone := useCassette(t, "get_records")
+	provider.StrictDecoding = true
+
+	if _, err := provider.GetRecords(t.Context(), zone); err != nil {
+		t.Errorf("Expected the recorded responses to have known fields only, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// DSRecord is a delegation signer record of a DNSSEC signed zone, to be
+// published in the parent zone through the domain registrar. It implements
+// libdns.Record.
+type DSRecord struct {
+	// Name is the owner name of the record, relative to the zone
+	Name string
+	TTL  time.Duration
+
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     string
+
+	// Raw is the record as returned by ClouDNS
+	Raw string
+}
+
+// RR returns the record as a generic libdns.RR of type DS.
+func (d DSRecord) RR() libdns.RR {
+	return libdns.RR{
+		Name: d.Name,
+		TTL:  d.TTL,
+		Type: "DS",
+		Data: fmt.Sprintf("%d %d %d %s", d.KeyTag, d.Algorithm, d.DigestType, d.Digest),
+	}
+}
+
+// parseDSRecord parses a DS record, either in the full zone file format
+// ("example.com. 3600 IN DS 2371 13 2 1F98...") or as its data only
+// ("2371 13 2 1F98..."). The digest may be split in several fields.
+func parseDSRecord(zone string, raw string) (DSRecord, error) {
+	ds := DSRecord{Name: "@", Raw: raw}
+
+	fields := strings.Fields(raw)
+	if idx := slices.IndexFunc(fields, func(f string) bool { return strings.EqualFold(f, "DS") }); idx >= 0 {
+		if idx > 0 {
+			ds.Name = libdns.RelativeName(fields[0], zone+".")
+		}
+		for _, field := range fields[1:idx] {
+			if ttl, err := strconv.Atoi(field); err == nil {
+				ds.TTL = time.Duration(ttl) * time.Second
+			}
+		}
+		fields = fields[idx+1:]
+	}
+
+	if len(fields) < 4 {
+		return DSRecord{}, fmt.Errorf("malformed DS record %q", raw)
+	}
+
+	keyTag, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return DSRecord{}, fmt.Errorf("invalid key tag in DS record %q: %w", raw, err)
+	}
+	algorithm, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil {
+		return DSRecord{}, fmt.Errorf("invalid algorithm in DS record %q: %w", raw, err)
+	}
+	digestType, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil {
+		return DSRecord{}, fmt.Errorf("invalid digest type in DS record %q: %w", raw, err)
+	}
+
+	ds.KeyTag = uint16(keyTag)
+	ds.Algorithm = uint8(algorithm)
+	ds.DigestType = uint8(digestType)
+	ds.Digest = strings.ToUpper(strings.Join(fields[3:], ""))
+
+	return ds, nil
+}
+
+// GetDSRecords returns the DS records of a DNSSEC signed zone, using
+// get-dnssec-ds-records.json. They have to be submitted to the registrar of
+// the domain to complete the chain of trust.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the DS records of
+//
+// Returns:
+//   - []DSRecord: The DS records of the zone, along with their raw form
+//   - error: Any error that occurred during the operation
+func (c *Client) GetDSRecords(ctx context.Context, zone string) ([]DSRecord, error) {
+	result, err := c.getDNSSECRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.dsRecords(zone)
+}
+
+// dnssecRecordsResult is the response of get-dnssec-ds-records.json.
+type dnssecRecordsResult struct {
+	Status flexInt  `json:"status"`
+	DS     []string `json:"ds"`
+	DNSKEY []string `json:"dnskey"`
+}
+
+func (r dnssecRecordsResult) dsRecords(zone string) ([]DSRecord, error) {
+	records := make([]DSRecord, 0, len(r.DS))
+	for _, raw := range r.DS {
+		ds, err := parseDSRecord(zone, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, ds)
+	}
+
+	return records, nil
+}
+
+func (c *Client) getDNSSECRecords(ctx context.Context, zone string) (dnssecRecordsResult, error) {
+	endpoint := apiBaseUrl.JoinPath("get-dnssec-ds-records.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	var result dnssecRecordsResult
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return dnssecRecordsResult{}, err
+	}
+
+	return result, nil
+}
+
+// DNSSECStatus describes the DNSSEC state of a zone.
+type DNSSECStatus struct {
+	// Available reports whether the ClouDNS plan supports DNSSEC for the zone
+	Available bool
+
+	// Active reports whether the zone is signed
+	Active bool
+
+	// DSRecords are the DS records to publish in the parent zone, if active
+	DSRecords []DSRecord
+
+	// DNSKEYs are the public keys signing the zone in zone file format, if active
+	DNSKEYs []string
+}
+
+// GetDNSSECStatus reports whether DNSSEC is available for and active on the
+// zone, along with its keys, using dnssec-available.json and
+// get-dnssec-ds-records.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the DNSSEC status of
+//
+// Returns:
+//   - DNSSECStatus: The DNSSEC state of the zone
+//   - error: Any error that occurred during the operation
+func (c *Client) GetDNSSECStatus(ctx context.Context, zone string) (DNSSECStatus, error) {
+	endpoint := apiBaseUrl.JoinPath("dnssec-available.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	var available struct {
+		Status flexInt `json:"status"`
+	}
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &available); err != nil {
+		return DNSSECStatus{}, err
+	}
+	if available.Status != 1 {
+		return DNSSECStatus{}, nil
+	}
+
+	result, err := c.getDNSSECRecords(ctx, zone)
+	if err != nil {
+		return DNSSECStatus{}, err
+	}
+
+	status := DNSSECStatus{Available: true, Active: result.Status == 1}
+	if !status.Active {
+		return status, nil
+	}
+
+	status.DSRecords, err = result.dsRecords(zone)
+	if err != nil {
+		return DNSSECStatus{}, err
+	}
+	status.DNSKEYs = result.DNSKEY
+
+	return status, nil
+}
+
+// GetAllDNSSECStatuses reports the DNSSEC state of every zone of the account,
+// keyed by zone name, e.g. for compliance audits. Zones whose state could not
+// be retrieved are left out, and their errors joined in the returned error.
+func (c *Client) GetAllDNSSECStatuses(ctx context.Context) (map[string]DNSSECStatus, error) {
+	zones, err := c.ListZones(ctx, ListZonesOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]DNSSECStatus, len(zones))
+	var errs []error
+	for _, zone := range zones {
+		status, err := c.GetDNSSECStatus(ctx, zone.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("zone %q: %w", zone.Name, err))
+			continue
+		}
+
+		statuses[zone.Name] = status
+	}
+
+	return statuses, errors.Join(errs...)
+}
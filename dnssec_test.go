@@ -0,0 +1,78 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestGetDSRecords(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":1,"ds":[
+			"example.com. 3600 IN DS 2371 13 2 1f987cc6583e92df0890718c42 91f5d5b2e7d8a9a6e3f2b1c0d9e8f7a6b5c4",
+			"2371 13 4 AABBCCDD"
+		]}`)
+	})
+
+	records, err := UseClient("id", "", "password").GetDSRecords(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Failed to get DS records: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 DS records, got %d", len(records))
+	}
+
+	expected := libdns.RR{
+		Name: "@",
+		TTL:  time.Hour,
+		Type: "DS",
+		Data: "2371 13 2 1F987CC6583E92DF0890718C4291F5D5B2E7D8A9A6E3F2B1C0D9E8F7A6B5C4",
+	}
+	if rr := records[0].RR(); rr != expected {
+		t.Errorf("Expected %+v, got %+v", expected, rr)
+	}
+	if records[1].DigestType != 4 || records[1].Raw != "2371 13 4 AABBCCDD" {
+		t.Errorf("Unexpected DS record %+v", records[1])
+	}
+}
+
+func TestGetDNSSECStatus(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/list-zones.json":
+			fmt.Fprint(w, `[{"name":"signed.com","type":"master","status":"1"},{"name":"unsigned.com","type":"master","status":"1"},{"name":"free.com","type":"master","status":"1"}]`)
+		case "/dns/dnssec-available.json":
+			if r.URL.Query().Get("domain-name") == "free.com" {
+				fmt.Fprint(w, `{"status":0}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":1}`)
+		case "/dns/get-dnssec-ds-records.json":
+			if r.URL.Query().Get("domain-name") == "unsigned.com" {
+				fmt.Fprint(w, `{"status":0}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":1,"ds":["2371 13 2 AABB"],"dnskey":["signed.com. 3600 IN DNSKEY 257 3 13 mdsswUyr"]}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	statuses, err := UseClient("id", "", "password").GetAllDNSSECStatuses(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get DNSSEC statuses: %v", err)
+	}
+
+	if s := statuses["free.com"]; s.Available || s.Active {
+		t.Errorf("Expected DNSSEC to be unavailable for free.com, got %+v", s)
+	}
+	if s := statuses["unsigned.com"]; !s.Available || s.Active {
+		t.Errorf("Expected DNSSEC to be available but inactive for unsigned.com, got %+v", s)
+	}
+	if s := statuses["signed.com"]; !s.Active || len(s.DSRecords) != 1 || len(s.DNSKEYs) != 1 {
+		t.Errorf("Expected DNSSEC to be active for signed.com, got %+v", s)
+	}
+}
@@ -0,0 +1,91 @@
+package cloudns
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/libdns/libdns"
+)
+
+// dryRunAPI passes the requests reading the account on to the wrapped API,
+// and logs the ones that would change it instead of sending them.
+type dryRunAPI struct {
+	API
+	logger *slog.Logger
+
+	// created holds the zones whose creation was skipped. They do not exist
+	// in the account, so their settings are not looked up.
+	created sync.Map
+}
+
+// dryRun wraps the API in a dryRunAPI if the provider is in DryRun mode.
+func (p *Provider) dryRun(api API) API {
+	if !p.DryRun {
+		return api
+	}
+
+	logger := p.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &dryRunAPI{API: api, logger: logger}
+}
+
+// log reports a skipped change.
+func (d *dryRunAPI) log(ctx context.Context, change, zone string, attrs ...any) {
+	d.logger.InfoContext(ctx, "ClouDNS dry run, skipped change", append([]any{"change", change, "zone", zone, "operation", OperationID(ctx)}, attrs...)...)
+}
+
+// GetAvailableTTLs returns no TTLs for a zone whose creation was skipped, so
+// that the default TTLs are used.
+func (d *dryRunAPI) GetAvailableTTLs(ctx context.Context, zone string) ([]int, error) {
+	if _, ok := d.created.Load(zone); ok {
+		return nil, nil
+	}
+
+	return d.API.GetAvailableTTLs(ctx, zone)
+}
+
+// GetAvailableRecordTypes returns no record types for a zone whose creation
+// was skipped, so that the record types are not checked.
+func (d *dryRunAPI) GetAvailableRecordTypes(ctx context.Context, zone string) ([]string, error) {
+	if _, ok := d.created.Load(zone); ok {
+		return nil, nil
+	}
+
+	return d.API.GetAvailableRecordTypes(ctx, zone)
+}
+
+func (d *dryRunAPI) AddClouDNSRecord(ctx context.Context, zone string, record ApiDnsRecord) (ApiDnsRecord, error) {
+	d.log(ctx, "add record", zone, "type", record.Type, "host", record.Host, "record", record.Record, "ttl", record.Ttl)
+
+	record.Status = 1
+	return record, nil
+}
+
+func (d *dryRunAPI) UpdateRecord(ctx context.Context, zone string, record ApiDnsRecord) (libdns.Record, error) {
+	d.log(ctx, "modify record", zone, "id", record.Id, "type", record.Type, "host", record.Host, "record", record.Record, "ttl", record.Ttl)
+
+	return record.toLibdnsRecord()
+}
+
+func (d *dryRunAPI) DeleteRecord(ctx context.Context, zone string, recordId string) error {
+	d.log(ctx, "delete record", zone, "id", recordId)
+
+	return nil
+}
+
+func (d *dryRunAPI) ChangeRecordStatus(ctx context.Context, zone string, recordId string, active bool) error {
+	d.log(ctx, "change record status", zone, "id", recordId, "active", active)
+
+	return nil
+}
+
+func (d *dryRunAPI) CreateZone(ctx context.Context, zone string, opts CreateZoneOptions) error {
+	d.log(ctx, "create zone", zone)
+	d.created.Store(zone, struct{}{})
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package cloudns
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestDryRun(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{
+				"1": {"id": "1", "type": "A", "host": "www", "record": "192.0.2.1", "ttl": "60", "status": 1},
+				"2": {"id": "2", "type": "TXT", "host": "old", "record": "bye", "ttl": "60", "status": 1}
+			}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","TXT"]`)
+		default:
+			t.Errorf("Unexpected request to %q in a dry run", r.URL.Path)
+		}
+	})
+
+	var logs bytes.Buffer
+	provider, err := NewProvider(WithCredentials("id", "password"), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	provider.DryRun = true
+	provider.OnRecordAdded = func(context.Context, string, libdns.Record) {
+		t.Errorf("Expected no hooks to be called in a dry run")
+	}
+
+	added, err := provider.AppendRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "new", TTL: time.Minute, Text: "hello"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to append records: %v", err)
+	}
+	if len(added) != 1 || added[0].RR().Data != "hello" {
+		t.Errorf("Expected the record that would be added, got %+v", added)
+	}
+
+	_, audit, err := provider.SetRecordsWithAudit(t.Context(), "example.com", []libdns.Record{
+		libdns.RR{Name: "www", TTL: time.Minute, Type: "A", Data: "192.0.2.5"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+	if len(audit) != 1 || audit[0].Kind != OperationModify || audit[0].After.RR().Data != "192.0.2.5" {
+		t.Errorf("Expected the modification that would be made, got %+v", audit)
+	}
+
+	deleted, err := provider.DeleteRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "old", Text: "bye"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].RR().Data != "bye" {
+		t.Errorf("Expected the record that would be deleted, got %+v", deleted)
+	}
+
+	for _, change := range []string{`change="add record"`, `change="modify record"`, `change="delete record"`} {
+		if !strings.Contains(logs.String(), change) {
+			t.Errorf("Expected the skipped change %s to be logged:\n%s", change, logs.String())
+		}
+	}
+}
+
+func TestDryRunMissingZone(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json", "/dns/get-available-ttl.json", "/dns/get-zone-info.json", "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Missing domain-name"}`)
+		default:
+			t.Errorf("Unexpected request to %q in a dry run", r.URL.Path)
+		}
+	})
+
+	// Without a logger of its own, the provider logs to the default logger
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", DryRun: true, RegisterMissingZones: true}
+
+	added, err := provider.AppendRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "new", TTL: time.Minute, Text: "hello"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to append records: %v", err)
+	}
+	if len(added) != 1 {
+		t.Errorf("Expected the record that would be added, got %+v", added)
+	}
+
+	_, audit, err := provider.SetRecordsWithAudit(t.Context(), "example.com", []libdns.Record{
+		libdns.RR{Name: "www", TTL: time.Minute, Type: "A", Data: "192.0.2.5"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+	if len(audit) != 1 || audit[0].Kind != OperationAdd {
+		t.Errorf("Expected the addition that would be made, got %+v", audit)
+	}
+
+	if _, err := provider.SyncZone(t.Context(), "example.com", []libdns.Record{
+		libdns.RR{Name: "www", TTL: time.Minute, Type: "A", Data: "192.0.2.5"},
+	}, SyncOptions{}); err != nil {
+		t.Fatalf("Failed to sync zone: %v", err)
+	}
+
+	for _, change := range []string{`change="create zone"`, `change="add record"`} {
+		if !strings.Contains(logs.String(), change) {
+			t.Errorf("Expected the skipped change %s to be logged:\n%s", change, logs.String())
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// DynamicURL is the dynamic DNS URL of an A or AAAA record. Calling it
+// updates the record with the IP address of the caller, so it must be kept
+// secret.
+type DynamicURL struct {
+	// Host is the host of the record the URL updates
+	Host string `json:"host"`
+	URL  string `json:"url"`
+}
+
+// GetDynamicURL returns the dynamic DNS URL of a record, using
+// get-dynamic-url.json. ClouDNS creates it on first use.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the A or AAAA record
+//
+// Returns:
+//   - DynamicURL: The dynamic DNS URL of the record
+//   - error: Any error that occurred during the operation
+func (c *Client) GetDynamicURL(ctx context.Context, zone string, recordId string) (DynamicURL, error) {
+	return c.getDynamicURL(ctx, "get-dynamic-url.json", zone, recordId)
+}
+
+// ChangeDynamicURL replaces the dynamic DNS URL of a record with a new one,
+// using change-dynamic-url.json. The previous URL stops working, which makes
+// it possible to rotate a leaked URL.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the A or AAAA record
+//
+// Returns:
+//   - DynamicURL: The new dynamic DNS URL of the record
+//   - error: Any error that occurred during the operation
+func (c *Client) ChangeDynamicURL(ctx context.Context, zone string, recordId string) (DynamicURL, error) {
+	return c.getDynamicURL(ctx, "change-dynamic-url.json", zone, recordId)
+}
+
+// DisableDynamicURL disables the dynamic DNS URL of a record, using
+// disable-dynamic-url.json. A new URL is created by the next call to
+// GetDynamicURL.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the A or AAAA record
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) DisableDynamicURL(ctx context.Context, zone string, recordId string) error {
+	endpoint := apiBaseUrl.JoinPath("disable-dynamic-url.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"record-id":   recordId,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+func (c *Client) getDynamicURL(ctx context.Context, path string, zone string, recordId string) (DynamicURL, error) {
+	endpoint := apiBaseUrl.JoinPath(path)
+	params := map[string]string{
+		"domain-name": zone,
+		"record-id":   recordId,
+	}
+
+	var result DynamicURL
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return DynamicURL{}, err
+	}
+
+	return result, nil
+}
+
+// ddnsTTL is the TTL of the records created by UpdateToCurrentIP, rounded to
+// the TTLs accepted for the zone. It is kept short so IP changes propagate fast.
+const ddnsTTL = time.Minute
+
+// UpdateToCurrentIP points a host to the public IP address the request
+// originates from, as seen by ClouDNS, making it a dynamic DNS updater. The A
+// record is updated for IPv4 addresses, the AAAA record for IPv6 ones. The
+// record is created if the host has none, and left untouched if it already
+// holds the address.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the host
+//   - host: The name of the record, relative to the zone
+//
+// Returns:
+//   - libdns.Record: The record, holding the current IP address
+//   - error: Any error that occurred during the operation
+func (c *Client) UpdateToCurrentIP(ctx context.Context, zone string, host string) (libdns.Record, error) {
+	ip, err := c.GetCurrentIP(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current IP address: %w", err)
+	}
+
+	type_ := "A"
+	if ip.Is6() {
+		type_ = "AAAA"
+	}
+
+	records, err := c.GetClouDNSRecords(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get records: %w", err)
+	}
+
+	existing := clouDNSRecordsToMap(records)[newNameAndType(host, type_)]
+	if len(existing) == 0 {
+		ttls, err := availableTTLs(ctx, c, zone)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.AddRecord(ctx, zone, fromLibdnsRecord(libdns.Address{Name: host, TTL: ddnsTTL, IP: ip}, "", ttls))
+	}
+
+	record := existing[0]
+	if record.Record == ip.String() {
+		return record.toLibdnsRecord()
+	}
+
+	record.Record = ip.String()
+	return c.UpdateRecord(ctx, zone, record)
+}
+
+// GetCurrentIP returns the public IP address the requests of the client
+// originate from, as seen by ClouDNS, using ip/get-my-ip.json. IPv4-mapped
+// IPv6 addresses are returned as plain IPv4 addresses.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//
+// Returns:
+//   - netip.Addr: The public IP address of the client
+//   - error: Any error that occurred during the operation
+func (c *Client) GetCurrentIP(ctx context.Context) (netip.Addr, error) {
+	// The endpoint is not part of the DNS API, but lives next to it
+	endpoint := apiBaseUrl.JoinPath("..", "ip", "get-my-ip.json")
+
+	var result struct {
+		IP string `json:"ip"`
+	}
+	if err := c.performGetJSONRequest(ctx, endpoint, nil, &result); err != nil {
+		return netip.Addr{}, err
+	}
+
+	ip, err := netip.ParseAddr(result.IP)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid IP address %q: %w", result.IP, err)
+	}
+
+	return ip.Unmap(), nil
+}
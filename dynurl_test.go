@@ -0,0 +1,87 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"testing"
+)
+
+func TestChangeDynamicURL(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("domain-name") != "example.com" || query.Get("record-id") != "42" {
+			t.Errorf("Unexpected parameters %v", query)
+		}
+
+		switch r.URL.Path {
+		case "/dns/change-dynamic-url.json":
+			fmt.Fprint(w, `{"host":"home","url":"https://ipv4.cloudns.net/api/dynamicURL/?q=new"}`)
+		case "/dns/disable-dynamic-url.json":
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Invalid domain-name."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	client := UseClient("id", "", "password")
+	dynURL, err := client.ChangeDynamicURL(t.Context(), "example.com", "42")
+	if err != nil {
+		t.Fatalf("Failed to change dynamic URL: %v", err)
+	}
+	if dynURL.Host != "home" || dynURL.URL != "https://ipv4.cloudns.net/api/dynamicURL/?q=new" {
+		t.Errorf("Unexpected dynamic URL %+v", dynURL)
+	}
+
+	if err := client.DisableDynamicURL(t.Context(), "example.com", "42"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound, got %v", err)
+	}
+}
+
+func TestUpdateToCurrentIP(t *testing.T) {
+	var updated string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/ip/get-my-ip.json":
+			fmt.Fprint(w, `{"ip":"192.0.2.7"}`)
+		case "/dns/records.json":
+			fmt.Fprint(w, `{"1":{"id":"1","type":"A","host":"home","record":"192.0.2.1","ttl":"60","status":1},"2":{"id":"2","type":"AAAA","host":"home","record":"2001:db8::1","ttl":"60","status":1}}`)
+		case "/dns/mod-record.json":
+			updated = query.Get("record-id") + " " + query.Get("record")
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was modified successfully."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	rec, err := UseClient("id", "", "password").UpdateToCurrentIP(t.Context(), "example.com", "Home")
+	if err != nil {
+		t.Fatalf("Failed to update to current IP: %v", err)
+	}
+
+	if updated != "1 192.0.2.7" {
+		t.Errorf("Expected record 1 to be updated to 192.0.2.7, got %q", updated)
+	}
+	if rr := rec.RR(); rr.Type != "A" || rr.Data != "192.0.2.7" {
+		t.Errorf("Unexpected record %+v", rr)
+	}
+}
+
+func TestGetCurrentIP(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ip/get-my-ip.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"ip":"::ffff:192.0.2.7"}`)
+	})
+
+	ip, err := UseClient("id", "", "password").GetCurrentIP(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get current IP: %v", err)
+	}
+	if ip != netip.MustParseAddr("192.0.2.7") {
+		t.Errorf("Expected 192.0.2.7, got %v", ip)
+	}
+}
@@ -0,0 +1,330 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrZoneNotFound is returned when the zone does not exist in the ClouDNS account.
+var ErrZoneNotFound = errors.New("zone not found")
+
+// ErrReadOnly is returned when a read-only Client or Provider is asked to
+// change the account.
+var ErrReadOnly = errors.New("client is read-only")
+
+// ErrZoneNotAllowed is returned when a Provider is asked to operate on a zone
+// that is not one of its AllowedZones.
+var ErrZoneNotAllowed = errors.New("zone not allowed")
+
+// ErrAuthenticationFailed is returned when the API rejects the credentials.
+var ErrAuthenticationFailed = errors.New("authentication failed")
+
+// ErrIPNotAllowed is returned when the API rejects a request because the IP
+// address it originates from is not on the allow-list of the credentials.
+var ErrIPNotAllowed = errors.New("IP address not allowed")
+
+// ErrInvalidConfig is returned when the configuration of a Provider or Client
+// is invalid.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ErrProviderClosed is returned by the operations of a Provider after Close.
+var ErrProviderClosed = errors.New("provider is closed")
+
+// ErrRateLimited is returned when ClouDNS rejects a request because too many
+// requests were made. It is retried after a dedicated cool-off, see
+// Provider.RateLimitCooldown.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// rateLimitDescriptions are fragments of the status descriptions ClouDNS
+// returns when the request rate of the account was exceeded.
+var rateLimitDescriptions = []string{
+	"rate limit",
+	"too many requests",
+	"requests limit",
+	"request limit",
+}
+
+// ErrUnknownField is returned with StrictDecoding when an API response has
+// a field this package does not know, e.g. after a change of the API.
+var ErrUnknownField = errors.New("unknown field")
+
+// ErrUnexpectedResponse is returned when the API answers with something
+// other than JSON, like an HTML maintenance page or a bot challenge of a
+// proxy. It is usually transient, so operations failing with it are retried.
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// ErrCloneIncomplete is returned by CloneZone when records of the source
+// zone are missing from the target zone after the import.
+var ErrCloneIncomplete = errors.New("clone incomplete")
+
+// ErrRetryBudgetExhausted is returned when an operation used up the
+// RetryBudget of the Provider.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// ErrCanceledPartially is returned when the context of AppendRecords,
+// SetRecords or DeleteRecords is done after some of the changes were applied.
+// The records changed so far are returned along with it, so that callers can
+// reconcile their state.
+var ErrCanceledPartially = errors.New("canceled with changes partially applied")
+
+// canceledError returns the error for an operation whose context is done
+// after the given number of changes were applied.
+func canceledError(ctx context.Context, applied int) error {
+	if applied == 0 {
+		return ctx.Err()
+	}
+
+	return fmt.Errorf("%w after %d changes: %w", ErrCanceledPartially, applied, context.Cause(ctx))
+}
+
+// zoneNotFoundDescriptions are fragments of the status descriptions ClouDNS
+// returns when the given domain-name is not a zone of the account.
+var zoneNotFoundDescriptions = []string{
+	"missing domain-name",
+	"invalid domain-name",
+}
+
+// zoneLockedDescriptions are fragments of the status descriptions ClouDNS
+// returns while a zone is locked by the changes it is applying.
+var zoneLockedDescriptions = []string{
+	"is updating",
+	"is locked",
+}
+
+// isZoneLocked reports whether the API rejected a request because the zone
+// is locked or updating.
+func isZoneLocked(err error) bool {
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && containsAny(apiErr.StatusDescription, zoneLockedDescriptions)
+}
+
+// DefaultRetryableStatusDescriptions are fragments of the status descriptions
+// of transient ClouDNS failures, which are retried. Other failures reported by
+// the API are permanent. See Provider.RetryableStatusDescriptions.
+var DefaultRetryableStatusDescriptions = []string{
+	"is updating",
+	"is locked",
+	"temporar",
+	"try again",
+	"timeout",
+	"timed out",
+	"internal error",
+}
+
+// ApiError is a failure reported by the ClouDNS API, either through the
+// status of its response or through an HTTP status other than 200 OK.
+type ApiError struct {
+	// Endpoint is the path of the request relative to the API root, e.g.
+	// "dns/records.json"
+	Endpoint string
+
+	// HTTPStatus is the HTTP status code of the response
+	HTTPStatus int
+
+	// Status and StatusDescription are the status reported by ClouDNS. For
+	// responses with an HTTP status other than 200 OK, StatusDescription
+	// holds the start of the response body instead.
+	Status            string
+	StatusDescription string
+
+	// Params are the parameters of the request, without the credentials and
+	// with any password redacted
+	Params map[string]string
+
+	// Err is a more specific error the failure was recognized as, if any
+	Err error
+}
+
+func (e *ApiError) Error() string {
+	var b strings.Builder
+	b.WriteString("API operation failed")
+	if e.Endpoint != "" {
+		fmt.Fprintf(&b, " at %s", e.Endpoint)
+	}
+	if e.HTTPStatus != 0 && e.HTTPStatus != http.StatusOK {
+		fmt.Fprintf(&b, " with HTTP status %d", e.HTTPStatus)
+	}
+	if e.StatusDescription != "" {
+		fmt.Fprintf(&b, ": %s", e.StatusDescription)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %s", e.Err)
+	}
+
+	return b.String()
+}
+
+func (e *ApiError) Unwrap() error {
+	return e.Err
+}
+
+// maxErrorBody is the number of bytes of an unexpected response body kept
+// in an ApiError.
+const maxErrorBody = 512
+
+// from sets the endpoint, HTTP status and parameters of the request the
+// response answers on the error, and returns it.
+func (e *ApiError) from(resp *http.Response) *ApiError {
+	e.HTTPStatus = resp.StatusCode
+	if resp.Request == nil {
+		return e
+	}
+
+	root := path.Dir(strings.TrimSuffix(apiBaseUrl.Path, "/"))
+	e.Endpoint = strings.TrimPrefix(strings.TrimPrefix(resp.Request.URL.Path, root), "/")
+
+	e.Params = make(map[string]string)
+	for key, values := range resp.Request.URL.Query() {
+		switch {
+		case key == "auth-id" || key == "sub-auth-id" || key == "auth-password":
+			continue
+		case strings.Contains(key, "password"):
+			e.Params[key] = redactedSecret
+		default:
+			e.Params[key] = strings.Join(values, ",")
+		}
+	}
+
+	return e
+}
+
+// newHTTPError creates the error for a response with an HTTP status other
+// than 200 OK, consuming its body. It wraps ErrUnexpectedResponse if the
+// response is not JSON, e.g. a maintenance page.
+func newHTTPError(resp *http.Response) *ApiError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if !isJSONContentType(resp.Header.Get("Content-Type")) && !json.Valid(body) {
+		return newUnexpectedResponseError(resp, body)
+	}
+
+	return (&ApiError{StatusDescription: strings.TrimSpace(string(body))}).from(resp)
+}
+
+// newUnexpectedResponseError creates the error for a response that is not
+// JSON, with the start of its body, whitespace collapsed, as description.
+func newUnexpectedResponseError(resp *http.Response, body []byte) *ApiError {
+	snippet := strings.Join(strings.Fields(string(body[:min(len(body), maxErrorBody)])), " ")
+	return (&ApiError{StatusDescription: snippet, Err: ErrUnexpectedResponse}).from(resp)
+}
+
+// isJSONContentType reports whether the Content-Type header announces JSON.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// operationError tags an error with the ID of the operation it occurred in,
+// see OperationID.
+type operationError struct {
+	id  string
+	err error
+}
+
+func (e *operationError) Error() string {
+	return fmt.Sprintf("operation %s: %s", e.id, e.err)
+}
+
+func (e *operationError) Unwrap() error {
+	return e.err
+}
+
+// newAPIError creates the error for a failed API operation from the status
+// description of the response, wrapping a more specific error if possible.
+// Use ApiError.from to add the details of the request.
+func newAPIError(description string) *ApiError {
+	apiErr := &ApiError{Status: "Failed", StatusDescription: description}
+	switch {
+	case isZoneNotFound(description):
+		apiErr.Err = ErrZoneNotFound
+	case isRateLimited(description):
+		apiErr.Err = ErrRateLimited
+	case isIPNotAllowed(description):
+		apiErr.Err = ErrIPNotAllowed
+	case isAuthenticationFailure(description):
+		apiErr.Err = ErrAuthenticationFailed
+	}
+
+	return apiErr
+}
+
+func isZoneNotFound(description string) bool {
+	return containsAny(description, zoneNotFoundDescriptions)
+}
+
+func isRateLimited(description string) bool {
+	return containsAny(description, rateLimitDescriptions)
+}
+
+// containsAny reports whether the description contains any of the fragments,
+// without regard to case.
+func containsAny(description string, fragments []string) bool {
+	description = strings.ToLower(description)
+	return slices.ContainsFunc(fragments, func(fragment string) bool {
+		return strings.Contains(description, strings.ToLower(fragment))
+	})
+}
+
+// ipNotAllowedPattern matches the status description ClouDNS returns for
+// requests from an IP address that may not use the credentials, e.g. "Your
+// IP address 198.51.100.7 is not allowed to use the API.". "IP" must be a
+// word of its own, so that e.g. "multiple" or "zip" do not match.
+var ipNotAllowedPattern = regexp.MustCompile(`(?i)\bip(v[46])?( address)?\b.*\bnot allowed\b`)
+
+// isIPNotAllowed reports whether the status description is one ClouDNS
+// returns for requests from an IP address that may not use the credentials.
+func isIPNotAllowed(description string) bool {
+	return ipNotAllowedPattern.MatchString(description)
+}
+
+// isAuthenticationFailure reports whether the status description is the one
+// ClouDNS returns for an unknown auth-id or a wrong auth-password.
+func isAuthenticationFailure(description string) bool {
+	return strings.Contains(strings.ToLower(description), "invalid authentication")
+}
+
+// isRetryable reports whether an operation that failed with err may succeed
+// when attempted again. Errors caused by the request itself are permanent,
+// and so are failures reported by the API, unless their status description
+// matches DefaultRetryableStatusDescriptions.
+func isRetryable(err error) bool {
+	return isRetryableWith(err, DefaultRetryableStatusDescriptions)
+}
+
+// isRetryableWith works like isRetryable, with the given fragments of the
+// status descriptions of transient failures.
+func isRetryableWith(err error, retryableDescriptions []string) bool {
+	if errors.Is(err, ErrZoneNotFound) ||
+		errors.Is(err, ErrInvalidRecord) ||
+		errors.Is(err, ErrInvalidConfig) ||
+		errors.Is(err, ErrReadOnly) ||
+		errors.Is(err, ErrAuthenticationFailed) ||
+		errors.Is(err, ErrIPNotAllowed) ||
+		errors.Is(err, ErrProviderClosed) ||
+		errors.Is(err, ErrRetryBudgetExhausted) ||
+		errors.Is(err, ErrCanceledPartially) ||
+		errors.Is(err, ErrUnknownField) {
+		return false
+	}
+
+	// Unexpected HTTP statuses are mostly caused by overloaded servers and
+	// proxies, so only failures reported by ClouDNS itself are permanent
+	var apiErr *ApiError
+	if errors.Is(err, ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.Status == "" {
+		return true
+	}
+
+	return containsAny(apiErr.StatusDescription, retryableDescriptions)
+}
@@ -0,0 +1,262 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// failoverTimeLayout is the layout of the timestamps returned by the failover
+// endpoints, in UTC.
+const failoverTimeLayout = time.DateTime
+
+// FailoverStatus is the health of the endpoint of a failover-monitored record,
+// as last checked by ClouDNS.
+type FailoverStatus struct {
+	RecordId string
+
+	// Up reports whether ClouDNS considers the endpoint healthy
+	Up bool
+
+	// LastCheck is the time of the last check, zero if it never ran
+	LastCheck time.Time
+
+	// Details is the outcome of the last check, as reported by ClouDNS
+	Details string
+}
+
+type failoverStatusResult struct {
+	Status    flexString `json:"status"`
+	LastCheck string     `json:"last_check"`
+	Details   string     `json:"details"`
+}
+
+func (r failoverStatusResult) toFailoverStatus(recordId string) (FailoverStatus, error) {
+	status := FailoverStatus{
+		RecordId: recordId,
+		Up:       strings.EqualFold(string(r.Status), "up") || r.Status == "1",
+		Details:  r.Details,
+	}
+
+	if r.LastCheck != "" {
+		lastCheck, err := time.Parse(failoverTimeLayout, r.LastCheck)
+		if err != nil {
+			return FailoverStatus{}, fmt.Errorf("invalid last check time %q: %w", r.LastCheck, err)
+		}
+		status.LastCheck = lastCheck
+	}
+
+	return status, nil
+}
+
+// GetFailoverStatus returns the health of the endpoint of a failover-monitored
+// record, using failover-status.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the record with failover enabled
+//
+// Returns:
+//   - FailoverStatus: The result of the last check of the record
+//   - error: Any error that occurred during the operation
+func (c *Client) GetFailoverStatus(ctx context.Context, zone string, recordId string) (FailoverStatus, error) {
+	endpoint := apiBaseUrl.JoinPath("failover-status.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"record-id":   recordId,
+	}
+
+	var result failoverStatusResult
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return FailoverStatus{}, err
+	}
+
+	return result.toFailoverStatus(recordId)
+}
+
+// ListFailoverStatuses returns the health of the endpoints of all the
+// failover-monitored records of a zone, in the order of GetClouDNSRecords.
+// Records whose status could not be retrieved are left out, and their errors
+// joined in the returned error.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the failover statuses of
+//
+// Returns:
+//   - []FailoverStatus: The result of the last check of each monitored record
+//   - error: Any error that occurred during the operation
+func (c *Client) ListFailoverStatuses(ctx context.Context, zone string) ([]FailoverStatus, error) {
+	records, err := c.GetClouDNSRecords(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get records: %w", err)
+	}
+
+	var statuses []FailoverStatus
+	var errs []error
+	for _, record := range records {
+		if record.Failover != "1" {
+			continue
+		}
+
+		status, err := c.GetFailoverStatus(ctx, zone, record.Id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("record %s: %w", record.Id, err))
+			continue
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, errors.Join(errs...)
+}
+
+// FailoverNotificationType is the kind of target failover notifications are
+// sent to.
+type FailoverNotificationType string
+
+const (
+	FailoverNotificationEmail   FailoverNotificationType = "mail"
+	FailoverNotificationWebhook FailoverNotificationType = "webhook"
+)
+
+// FailoverNotification is a target ClouDNS notifies when the endpoint of a
+// failover-monitored record goes down or comes back up.
+type FailoverNotification struct {
+	Id   string                   `json:"id"`
+	Type FailoverNotificationType `json:"type"`
+
+	// Value is the email address or the webhook URL to notify
+	Value string `json:"value"`
+}
+
+// ListFailoverNotifications returns the notification targets of a
+// failover-monitored record, using failover-notifications.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the record with failover enabled
+//
+// Returns:
+//   - []FailoverNotification: The notification targets of the record
+//   - error: Any error that occurred during the operation
+func (c *Client) ListFailoverNotifications(ctx context.Context, zone string, recordId string) ([]FailoverNotification, error) {
+	endpoint := apiBaseUrl.JoinPath("failover-notifications.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"record-id":   recordId,
+	}
+
+	var result []FailoverNotification
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddFailoverNotification adds a notification target to a failover-monitored
+// record, using failover-add-notification.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the record with failover enabled
+//   - type_: The kind of target to notify
+//   - value: The email address or the webhook URL to notify
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) AddFailoverNotification(ctx context.Context, zone string, recordId string, type_ FailoverNotificationType, value string) error {
+	endpoint := apiBaseUrl.JoinPath("failover-add-notification.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"record-id":   recordId,
+		"type":        string(type_),
+		"value":       value,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// DeleteFailoverNotification removes a notification target from a
+// failover-monitored record, using failover-delete-notification.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the record with failover enabled
+//   - notificationId: ID of the target, as returned by ListFailoverNotifications
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) DeleteFailoverNotification(ctx context.Context, zone string, recordId string, notificationId string) error {
+	endpoint := apiBaseUrl.JoinPath("failover-delete-notification.json")
+	params := map[string]string{
+		"domain-name":     zone,
+		"record-id":       recordId,
+		"notification-id": notificationId,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// SetFailoverNotifications makes the given targets the only notification
+// targets of a failover-monitored record. Missing targets are added before
+// the other ones are removed, so alerting keeps working should any step fail.
+// The Id of the given targets is ignored.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the record
+//   - recordId: ID of the record with failover enabled
+//   - notifications: The desired notification targets
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) SetFailoverNotifications(ctx context.Context, zone string, recordId string, notifications []FailoverNotification) error {
+	existing, err := c.ListFailoverNotifications(ctx, zone, recordId)
+	if err != nil {
+		return fmt.Errorf("failed to list failover notifications: %w", err)
+	}
+
+	type target struct {
+		type_ FailoverNotificationType
+		value string
+	}
+	desired := make(map[target]bool, len(notifications))
+	for _, notification := range notifications {
+		desired[target{notification.Type, notification.Value}] = true
+	}
+	current := make(map[target]bool, len(existing))
+	for _, notification := range existing {
+		current[target{notification.Type, notification.Value}] = true
+	}
+
+	for _, notification := range notifications {
+		key := target{notification.Type, notification.Value}
+		if current[key] {
+			continue
+		}
+		if err := c.AddFailoverNotification(ctx, zone, recordId, notification.Type, notification.Value); err != nil {
+			return fmt.Errorf("failed to add failover notification %s %q: %w", notification.Type, notification.Value, err)
+		}
+		current[key] = true
+	}
+
+	var errs []error
+	for _, notification := range existing {
+		if desired[target{notification.Type, notification.Value}] {
+			continue
+		}
+		if err := c.DeleteFailoverNotification(ctx, zone, recordId, notification.Id); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete failover notification %s %q: %w", notification.Type, notification.Value, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
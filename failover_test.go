@@ -0,0 +1,84 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListFailoverStatuses(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{"1":{"id":"1","type":"A","host":"www","record":"192.0.2.1","failover":"1","ttl":"60","status":1},"2":{"id":"2","type":"A","host":"mail","record":"192.0.2.2","failover":"0","ttl":"60","status":1},"3":{"id":"3","type":"A","host":"api","record":"192.0.2.3","failover":"1","ttl":"60","status":1}}`)
+		case "/dns/failover-status.json":
+			switch r.URL.Query().Get("record-id") {
+			case "1":
+				fmt.Fprint(w, `{"status":"up","last_check":"2025-03-01 12:30:00","details":"HTTP 200"}`)
+			case "3":
+				fmt.Fprint(w, `{"status":"down","last_check":"2025-03-01 12:31:00","details":"Connection timed out"}`)
+			default:
+				t.Errorf("Unexpected record %q", r.URL.Query().Get("record-id"))
+			}
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	statuses, err := UseClient("id", "", "password").ListFailoverStatuses(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Failed to list failover statuses: %v", err)
+	}
+
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %+v", statuses)
+	}
+	for _, status := range statuses {
+		switch status.RecordId {
+		case "1":
+			if !status.Up || !status.LastCheck.Equal(time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)) {
+				t.Errorf("Unexpected status %+v", status)
+			}
+		case "3":
+			if status.Up || status.Details != "Connection timed out" {
+				t.Errorf("Unexpected status %+v", status)
+			}
+		default:
+			t.Errorf("Unexpected status %+v", status)
+		}
+	}
+}
+
+func TestSetFailoverNotifications(t *testing.T) {
+	var calls []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/dns/failover-notifications.json":
+			fmt.Fprint(w, `[{"id":"10","type":"mail","value":"ops@example.com"},{"id":"11","type":"webhook","value":"https://old.example.com/hook"}]`)
+			return
+		case "/dns/failover-add-notification.json":
+			calls = append(calls, "add "+query.Get("type")+" "+query.Get("value"))
+		case "/dns/failover-delete-notification.json":
+			calls = append(calls, "delete "+query.Get("notification-id"))
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"status":"Success","statusDescription":"Done."}`)
+	})
+
+	err := UseClient("id", "", "password").SetFailoverNotifications(t.Context(), "example.com", "1", []FailoverNotification{
+		{Type: FailoverNotificationEmail, Value: "ops@example.com"},
+		{Type: FailoverNotificationWebhook, Value: "https://new.example.com/hook"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set failover notifications: %v", err)
+	}
+
+	expected := []string{"add webhook https://new.example.com/hook", "delete 11"}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
@@ -0,0 +1,267 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/libdns/libdns"
+)
+
+// FakeProvider is an in-memory stand-in for Provider, meant for unit testing
+// applications that embed this package without ClouDNS credentials.
+//
+// It implements the same libdns interfaces and mirrors the behavior of
+// Provider: TTLs are rounded up to the values accepted by ClouDNS, SetRecords
+// replaces whole RRsets while leaving matching records untouched, records
+// passed several times are only added once, and operations on zones that do
+// not exist fail with ErrZoneNotFound. Records are assigned IDs and carry a
+// RecordData, like the ones returned by ClouDNS.
+//
+// A FakeProvider is safe for concurrent use by multiple goroutines.
+type FakeProvider struct {
+	mu     sync.Mutex
+	nextId int
+	zones  map[string][]ApiDnsRecord
+}
+
+// NewFakeProvider creates a FakeProvider holding the given empty zones.
+//
+// Parameters:
+//   - zones: The names of the zones, with or without trailing dot
+//
+// Returns:
+//   - *FakeProvider: The fake provider
+func NewFakeProvider(zones ...string) *FakeProvider {
+	f := &FakeProvider{zones: make(map[string][]ApiDnsRecord, len(zones))}
+	for _, zone := range zones {
+		f.AddZone(zone)
+	}
+
+	return f
+}
+
+// AddZone adds an empty zone to the fake provider. Existing zones are left as is.
+func (f *FakeProvider) AddZone(zone string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	zone = normalizeZone(zone)
+	if _, ok := f.zones[zone]; !ok {
+		f.zones[zone] = nil
+	}
+}
+
+// GetRecords lists all the records in the zone.
+func (f *FakeProvider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	records, err := f.zoneRecords(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]libdns.Record, 0, len(records))
+	for _, record := range records {
+		rec, err := record.toLibdnsRecord()
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, rec)
+	}
+
+	return ret, nil
+}
+
+// AppendRecords adds records to the zone. It returns the records that were added.
+func (f *FakeProvider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	if err := validateRecords(records); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.zoneRecords(zone); err != nil {
+		return nil, err
+	}
+
+	created := make([]libdns.Record, 0, len(records))
+	for _, record := range dedupeRecords(records, nil) {
+		upstream := fromLibdnsRecord(record, "", nil)
+		upstream.Status = 1
+		rec, err := f.add(zone, upstream)
+		if err != nil {
+			return nil, err
+		}
+
+		created = append(created, rec)
+	}
+
+	return created, nil
+}
+
+// SetRecords sets the records in the zone, replacing the RRsets with the
+// same name and type. It returns the records that were added or updated.
+func (f *FakeProvider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	if err := validateRecords(records); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	existing, err := f.zoneRecords(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	rrsets := libdnsRecordsToMap(dedupeRecords(records, nil))
+	oplist := makeOperationList(rrsets, clouDNSRecordsToMap(existing), nil)
+
+	ret := make([]libdns.Record, 0, len(records))
+	for _, op := range oplist {
+		rec := op.record
+		if op.status != nil {
+			rec.Status = 0
+			if *op.status {
+				rec.Status = 1
+			}
+		}
+
+		switch op.op {
+		case addRecord:
+			if op.status == nil {
+				rec.Status = 1
+			}
+			added, err := f.add(zone, rec)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, added)
+			continue
+		case deleteRecord:
+			f.delete(zone, rec.Id)
+			continue
+		}
+
+		f.replace(zone, rec)
+		updated, err := rec.toLibdnsRecord()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, updated)
+	}
+
+	return ret, nil
+}
+
+// DeleteRecords deletes the records from the zone. It returns the records
+// that were deleted. Like with Provider, empty TTL and data fields of the
+// given records match any value.
+func (f *FakeProvider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	existing, err := f.zoneRecords(zone)
+	if err != nil {
+		return nil, err
+	}
+	keyedRecords := clouDNSRecordsToMap(existing)
+
+	var deleted []libdns.Record
+	for _, record := range records {
+		rr := record.RR()
+		for _, matchingRecord := range keyedRecords[newNameAndType(rr.Name, rr.Type)] {
+			matched, err := matchingRecord.toLibdnsRecord()
+			if err != nil {
+				return nil, err
+			}
+			if !matchDeleteTarget(record, matched) || !f.delete(zone, matchingRecord.Id) {
+				continue
+			}
+
+			deleted = append(deleted, matched)
+		}
+	}
+
+	return deleted, nil
+}
+
+// ListZones returns the zones of the fake provider, as fully qualified names.
+func (f *FakeProvider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ret := make([]libdns.Zone, 0, len(f.zones))
+	for zone := range f.zones {
+		ret = append(ret, libdns.Zone{Name: toUnicode(zone) + "."})
+	}
+	slices.SortFunc(ret, func(a, b libdns.Zone) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return ret, nil
+}
+
+// zoneRecords returns the records of the zone, or ErrZoneNotFound.
+func (f *FakeProvider) zoneRecords(zone string) ([]ApiDnsRecord, error) {
+	records, ok := f.zones[normalizeZone(zone)]
+	if !ok {
+		return nil, fmt.Errorf("zone %q: %w", normalizeZone(zone), ErrZoneNotFound)
+	}
+
+	return records, nil
+}
+
+// add stores a new record in the zone under a fresh ID. The caller sets its status.
+func (f *FakeProvider) add(zone string, record ApiDnsRecord) (libdns.Record, error) {
+	f.nextId++
+	record.Id = strconv.Itoa(f.nextId)
+
+	rec, err := record.toLibdnsRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	zone = normalizeZone(zone)
+	f.zones[zone] = append(f.zones[zone], record)
+
+	return rec, nil
+}
+
+// replace overwrites the record of the zone with the same ID.
+func (f *FakeProvider) replace(zone string, record ApiDnsRecord) {
+	records := f.zones[normalizeZone(zone)]
+	if idx := slices.IndexFunc(records, func(r ApiDnsRecord) bool { return r.Id == record.Id }); idx >= 0 {
+		records[idx] = record
+	}
+}
+
+// delete removes the record with the given ID from the zone, and reports
+// whether it existed.
+func (f *FakeProvider) delete(zone, id string) bool {
+	zone = normalizeZone(zone)
+	records := f.zones[zone]
+	idx := slices.IndexFunc(records, func(r ApiDnsRecord) bool { return r.Id == id })
+	if idx < 0 {
+		return false
+	}
+
+	f.zones[zone] = slices.Delete(records, idx, idx+1)
+	return true
+}
+
+// Interface guards
+var (
+	_ libdns.RecordGetter   = (*FakeProvider)(nil)
+	_ libdns.RecordAppender = (*FakeProvider)(nil)
+	_ libdns.RecordSetter   = (*FakeProvider)(nil)
+	_ libdns.RecordDeleter  = (*FakeProvider)(nil)
+	_ libdns.ZoneLister     = (*FakeProvider)(nil)
+)
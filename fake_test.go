@@ -0,0 +1,64 @@
+package cloudns
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestFakeProvider(t *testing.T) {
+	fake := NewFakeProvider("example.com.")
+
+	if _, err := fake.GetRecords(t.Context(), "example.org"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound, got %v", err)
+	}
+
+	// TTLs are rounded up to the values accepted by ClouDNS
+	added, err := fake.AppendRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.Address{Name: "www", TTL: 90 * time.Second, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", TTL: 100 * time.Second, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.TXT{Name: "www", TTL: time.Hour, Text: "hello"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to append records: %v", err)
+	}
+	if len(added) != 2 || added[0].RR().TTL != 5*time.Minute {
+		t.Errorf("Expected 2 records with rounded TTLs, got %+v", added)
+	}
+
+	// SetRecords replaces the RRset of the A records only
+	set, err := fake.SetRecords(t.Context(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.2")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+	if len(set) != 1 {
+		t.Errorf("Expected the existing record to be left untouched, got %+v", set)
+	}
+
+	records, err := fake.GetRecords(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("Expected 3 records, got %+v", records)
+	}
+
+	deleted, err := fake.DeleteRecords(t.Context(), "example.com", []libdns.Record{libdns.RR{Name: "www", Type: "A"}})
+	if err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("Expected 2 records to be deleted, got %+v", deleted)
+	}
+
+	zones, err := fake.ListZones(t.Context())
+	if err != nil || len(zones) != 1 || zones[0].Name != "example.com." {
+		t.Errorf("Expected zone example.com., got %+v, %v", zones, err)
+	}
+}
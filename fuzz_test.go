@@ -0,0 +1,106 @@
+package cloudns
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func FuzzRecordConversion(f *testing.F) {
+	for _, rec := range records {
+		f.Add(rec.Type, rec.Host, rec.Record, rec.Ttl, rec.Priority, rec.Port, rec.Weight)
+	}
+	for rec := range invalidRecords {
+		f.Add(rec.Type, rec.Host, rec.Record, rec.Ttl, rec.Priority, rec.Port, rec.Weight)
+	}
+	for _, rec := range generateApiRecords(1, 50) {
+		f.Add(rec.Type, rec.Host, rec.Record, rec.Ttl, rec.Priority, rec.Port, rec.Weight)
+	}
+	f.Add("TTL", "@", "", "-1", uint16(0), uint16(0), uint16(0))
+	f.Add("SRV", "_sip._tcp.", "example.com", "99999999999999999999", uint16(1), uint16(2), uint16(3))
+	f.Add("TXT", "www", strings.Repeat(`"\\\"`, 1024), "3600", uint16(0), uint16(0), uint16(0))
+
+	f.Fuzz(func(t *testing.T, type_, host, record, ttl string, priority, port, weight uint16) {
+		upstream := ApiDnsRecord{Id: "1", Type: type_, Host: host, Record: record, Ttl: ttl, Priority: priority, Port: port, Weight: weight, Status: 1}
+		rec, err := upstream.toLibdnsRecord()
+		if err != nil {
+			return
+		}
+
+		// Converting back and forth may normalize the record once, after
+		// which it must be stable
+		first := fromLibdnsRecord(rec, "1", nil)
+		rec, err = first.toLibdnsRecord()
+		if err != nil {
+			t.Fatalf("Failed to convert normalized record %+v: %v", first, err)
+		}
+		if second := fromLibdnsRecord(rec, "1", nil); second != first {
+			t.Errorf("Record changed on a second round trip: %+v != %+v", second, first)
+		}
+	})
+}
+
+func FuzzUnmarshalRecord(f *testing.F) {
+	f.Add([]byte(`{"id":"1","type":"NAPTR","host":"sip","ttl":"3600","status":1,"order":"100","pref":"10","flag":"S","params":"SIP+D2U","regexp":"","replace":"_sip._udp.example.com"}`))
+	f.Add([]byte(`{"id":"2","type":"MX","host":"","record":"mail.example.com","ttl":"3600","priority":"10","status":1}`))
+	f.Add([]byte(`{"id":"3","type":"TYPE16","host":"www","record":"hello","ttl":3600,"status":"1"}`))
+	for _, rec := range generateApiRecords(2, 20) {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			f.Fatalf("Failed to encode generated record: %v", err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var upstream ApiDnsRecord
+		if err := json.Unmarshal(data, &upstream); err != nil {
+			return
+		}
+
+		// Malformed records must be rejected, not panic
+		_, _ = upstream.toLibdnsRecord()
+	})
+}
+
+func FuzzTokenizeRDATA(f *testing.F) {
+	f.Add(`100 10 "S" "SIP+D2U" "" _sip._udp.example.com`)
+	f.Add(`"a \"quoted\" \034string\034"`)
+	f.Add(`"unterminated`)
+	f.Add(`"\`)
+
+	f.Fuzz(func(t *testing.T, data string) {
+		tokens, ok := tokenizeRDATA(data)
+		if !ok {
+			return
+		}
+
+		// Quoting the fields again must yield the same fields
+		quoted := make([]string, 0, len(tokens))
+		for _, token := range tokens {
+			quoted = append(quoted, quoteTXTString(token.value))
+		}
+		again, ok := tokenizeRDATA(strings.Join(quoted, " "))
+		if !ok {
+			t.Fatalf("Failed to tokenize quoted fields %q", quoted)
+		}
+		if !slices.EqualFunc(tokens, again, func(a, b rdataToken) bool { return a.value == b.value }) {
+			t.Errorf("Fields changed after quoting: %+v != %+v", again, tokens)
+		}
+	})
+}
+
+func FuzzRDATAParameters(f *testing.F) {
+	for type_ := range genericRDATA {
+		f.Add(type_, `100 10 "S" "SIP+D2U" "" _sip._udp.example.com`)
+		f.Add(type_, `admin.example.com. info.example.com.`)
+	}
+
+	f.Fuzz(func(t *testing.T, type_, data string) {
+		params, ok := rdataParameters(type_, data)
+		if ok && len(params) != len(genericRDATA[canonicalRecordType(type_)]) {
+			t.Errorf("Expected a parameter per field of %s, got %v", type_, params)
+		}
+	})
+}
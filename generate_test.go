@@ -0,0 +1,197 @@
+package cloudns
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// generatedTypes are the record types generateRecord knows to produce.
+var generatedTypes = []string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "SRV", "TXT", "SSHFP", "TLSA", "DS", "NAPTR", "RP", "PTR"}
+
+// newGenerator returns a random number generator yielding the same values for
+// the same seed, so that failures can be reproduced.
+func newGenerator(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// generateLabel returns a random lower case DNS label.
+func generateLabel(rng *rand.Rand) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	label := make([]byte, 1+rng.IntN(12))
+	for idx := range label {
+		label[idx] = alphabet[rng.IntN(len(alphabet))]
+	}
+	if label[0] >= '0' && label[0] <= '9' {
+		label[0] = 'x'
+	}
+
+	return string(label)
+}
+
+// generateName returns a random record name relative to the zone, "@" for
+// the apex.
+func generateName(rng *rand.Rand) string {
+	if rng.IntN(5) == 0 {
+		return "@"
+	}
+
+	labels := make([]string, 1+rng.IntN(2))
+	for idx := range labels {
+		labels[idx] = generateLabel(rng)
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// generateTarget returns a random fully qualified domain name.
+func generateTarget(rng *rand.Rand) string {
+	return generateLabel(rng) + "." + generateLabel(rng) + ".example."
+}
+
+// generateHex returns random hex data of the given number of bytes.
+func generateHex(rng *rand.Rand, size int) string {
+	var b strings.Builder
+	for range size {
+		fmt.Fprintf(&b, "%02x", rng.IntN(256))
+	}
+
+	return b.String()
+}
+
+// generateText returns random printable text, including characters that
+// need escaping in presentation format.
+func generateText(rng *rand.Rand) string {
+	const alphabet = `abcdefghijklmnopqrstuvwxyz ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789=;:-_."\`
+
+	text := make([]byte, 1+rng.IntN(64))
+	for idx := range text {
+		text[idx] = alphabet[rng.IntN(len(alphabet))]
+	}
+
+	return strings.TrimSpace(string(text)) + "x"
+}
+
+// generateRecord returns a valid record of the given type, with a TTL
+// accepted by ClouDNS.
+func generateRecord(rng *rand.Rand, type_ string) libdns.Record {
+	name := generateName(rng)
+	ttl := time.Duration(defaultTTLs[rng.IntN(len(defaultTTLs))]) * time.Second
+
+	switch type_ {
+	case "A":
+		return libdns.Address{Name: name, TTL: ttl, IP: netip.AddrFrom4([4]byte{192, 0, 2, byte(rng.IntN(256))})}
+	case "AAAA":
+		ip := netip.MustParseAddr("2001:db8::").As16()
+		ip[15] = byte(rng.IntN(256))
+		ip[14] = byte(rng.IntN(256))
+		return libdns.Address{Name: name, TTL: ttl, IP: netip.AddrFrom16(ip)}
+	case "CAA":
+		tags := []string{"issue", "issuewild", "iodef"}
+		tag := tags[rng.IntN(len(tags))]
+		value := strings.TrimSuffix(generateTarget(rng), ".")
+		if tag == "iodef" {
+			value = "mailto:security@" + value
+		}
+		return libdns.CAA{Name: name, TTL: ttl, Flags: uint8(rng.IntN(2) * 128), Tag: tag, Value: value}
+	case "CNAME":
+		return libdns.CNAME{Name: generateLabel(rng), TTL: ttl, Target: generateTarget(rng)}
+	case "MX":
+		return libdns.MX{Name: name, TTL: ttl, Preference: uint16(rng.IntN(100)), Target: generateTarget(rng)}
+	case "NS":
+		return libdns.NS{Name: generateLabel(rng), TTL: ttl, Target: generateTarget(rng)}
+	case "SRV":
+		transports := []string{"tcp", "udp"}
+		return libdns.SRV{
+			Service:   generateLabel(rng),
+			Transport: transports[rng.IntN(len(transports))],
+			Name:      name,
+			TTL:       ttl,
+			Priority:  uint16(rng.IntN(100)),
+			Weight:    uint16(rng.IntN(100)),
+			Port:      uint16(1 + rng.IntN(65535)),
+			Target:    generateTarget(rng),
+		}
+	case "TXT":
+		return libdns.TXT{Name: name, TTL: ttl, Text: generateText(rng)}
+	case "SSHFP":
+		return libdns.RR{Name: name, TTL: ttl, Type: type_, Data: fmt.Sprintf("%d 2 %s", 1+rng.IntN(4), strings.ToUpper(generateHex(rng, 32)))}
+	case "TLSA":
+		return libdns.RR{Name: "_443._tcp", TTL: ttl, Type: type_, Data: fmt.Sprintf("%d %d 1 %s", rng.IntN(4), rng.IntN(2), strings.ToUpper(generateHex(rng, 32)))}
+	case "DS":
+		return libdns.RR{Name: generateLabel(rng), TTL: ttl, Type: type_, Data: fmt.Sprintf("%d 13 2 %s", rng.IntN(65536), strings.ToUpper(generateHex(rng, 32)))}
+	case "NAPTR":
+		return libdns.RR{Name: name, TTL: ttl, Type: type_, Data: fmt.Sprintf(`%d %d "S" "SIP+D2U" "" _sip._udp.%s`, rng.IntN(1000), rng.IntN(100), generateTarget(rng))}
+	case "RP":
+		return libdns.RR{Name: name, TTL: ttl, Type: type_, Data: generateTarget(rng) + " " + generateTarget(rng)}
+	case "PTR":
+		return libdns.RR{Name: name, TTL: ttl, Type: type_, Data: generateTarget(rng)}
+	}
+
+	panic("no generator for record type " + type_)
+}
+
+// generateRecords returns n valid records of random types for the seed.
+func generateRecords(seed uint64, n int) []libdns.Record {
+	rng := newGenerator(seed)
+
+	records := make([]libdns.Record, 0, n)
+	for range n {
+		records = append(records, generateRecord(rng, generatedTypes[rng.IntN(len(generatedTypes))]))
+	}
+
+	return records
+}
+
+// generateApiRecords returns n valid upstream records of random types for
+// the seed, with IDs.
+func generateApiRecords(seed uint64, n int) []ApiDnsRecord {
+	records := generateRecords(seed, n)
+
+	upstream := make([]ApiDnsRecord, 0, len(records))
+	for idx, rec := range records {
+		record := fromLibdnsRecord(rec, fmt.Sprint(idx+1), nil)
+		record.Status = 1
+		upstream = append(upstream, record)
+	}
+
+	return upstream
+}
+
+func TestGeneratedRoundTrip(t *testing.T) {
+	for seed := range uint64(50) {
+		for _, rec := range generateRecords(seed, 20) {
+			if err := validateRecord(rec); err != nil {
+				t.Fatalf("Seed %d generated an invalid record %+v: %v", seed, rec, err)
+			}
+
+			upstream := fromLibdnsRecord(rec, "1", nil)
+			converted, err := upstream.toLibdnsRecord()
+			if err != nil {
+				t.Fatalf("Seed %d: failed to convert %+v: %v", seed, upstream, err)
+			}
+
+			if again := fromLibdnsRecord(converted, "1", nil); again != upstream {
+				t.Errorf("Seed %d: record changed on a round trip: %+v != %+v", seed, again, upstream)
+			}
+			if rr, convertedRR := rec.RR(), converted.RR(); rr.Type != convertedRR.Type || rr.TTL != convertedRR.TTL {
+				t.Errorf("Seed %d: expected %s record with TTL %s, got %+v", seed, rr.Type, rr.TTL, convertedRR)
+			}
+		}
+	}
+}
+
+func TestGeneratorsAreDeterministic(t *testing.T) {
+	first, second := generateApiRecords(42, 30), generateApiRecords(42, 30)
+	for idx := range first {
+		if first[idx] != second[idx] {
+			t.Fatalf("Expected the same records for the same seed, got %+v and %+v", first[idx], second[idx])
+		}
+	}
+}
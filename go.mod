@@ -2,4 +2,9 @@ module github.com/libdns/cloudns
 
 go 1.24
 
-require github.com/libdns/libdns v1.0.0
+require (
+	github.com/libdns/libdns v1.0.0
+	golang.org/x/net v0.42.0
+)
+
+require golang.org/x/text v0.27.0 // indirect
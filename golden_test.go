@@ -0,0 +1,104 @@
+package cloudns
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// assertGolden compares the value, encoded as indented JSON, with the named
+// golden file in testdata/golden, or rewrites the file when run with -update.
+func assertGolden(t *testing.T, name string, value any) {
+	t.Helper()
+
+	actual, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to encode %s: %v", name, err)
+	}
+	actual = append(actual, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatalf("Failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file, run with -update to create it: %v", err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("%s does not match %s, run with -update if the change is intended:\n%s", name, path, actual)
+	}
+}
+
+// goldenRecords holds a record of every supported type, with fields that are
+// easily dropped, like zero preferences and weights, set to zero.
+var goldenRecords = []libdns.Record{
+	libdns.Address{Name: "@", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.1")},
+	libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("2001:db8::1")},
+	libdns.CAA{Name: "@", TTL: time.Hour, Flags: 0, Tag: "issue", Value: "letsencrypt.org"},
+	libdns.CNAME{Name: "alias", TTL: time.Hour, Target: "www.example.com."},
+	libdns.MX{Name: "@", TTL: time.Hour, Preference: 0, Target: "mail.example.com."},
+	libdns.NS{Name: "sub", TTL: time.Hour, Target: "ns1.example.net."},
+	libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", TTL: time.Hour, Priority: 10, Weight: 0, Port: 5060, Target: "sip.example.com."},
+	libdns.TXT{Name: "_acme-challenge", TTL: time.Minute, Text: `token with "quotes"`},
+	libdns.RR{Name: "host", TTL: time.Hour, Type: "SSHFP", Data: "4 2 123456789abcdef67890123456789abcdef67890123456789abcdef123456789"},
+	libdns.RR{Name: "_443._tcp", TTL: time.Hour, Type: "TLSA", Data: "3 1 1 0123456789abcdef"},
+	libdns.RR{Name: "sub", TTL: time.Hour, Type: "DS", Data: "12345 13 2 0123456789abcdef"},
+	libdns.RR{Name: "sip", TTL: time.Hour, Type: "NAPTR", Data: `100 0 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+	libdns.RR{Name: "@", TTL: time.Hour, Type: "RP", Data: "admin.example.com. info.example.com."},
+	libdns.RR{Name: "office", TTL: time.Hour, Type: "LOC", Data: "51 30 12.748 N 0 7 39.611 W 0 0 0 0"},
+	libdns.RR{Name: "_smimecert", TTL: time.Hour, Type: "SMIMEA", Data: "3 0 0 0123456789abcdef"},
+	libdns.RR{Name: "ptr", TTL: time.Hour, Type: "PTR", Data: "host.example.com."},
+}
+
+func TestParameterEncodingGolden(t *testing.T) {
+	var endpoint string
+	var query map[string]string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		endpoint = r.URL.Path
+		query = make(map[string]string)
+		for key, values := range r.URL.Query() {
+			if key != "auth-id" && key != "auth-password" {
+				query[key] = values[0]
+			}
+		}
+		fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was saved successfully.","data":{"id":1}}`)
+	})
+
+	c := &Client{AuthId: "id", AuthPassword: "password"}
+	encoded := make(map[string]map[string]string)
+	for _, rec := range goldenRecords {
+		rr := rec.RR()
+		key := fmt.Sprintf("%s %s", rr.Type, rr.Name)
+
+		if _, err := c.AddRecord(t.Context(), "example.com", fromLibdnsRecord(rec, "", nil)); err != nil {
+			t.Fatalf("Failed to add %s: %v", key, err)
+		}
+		encoded[endpoint+" "+key] = query
+
+		if _, err := c.UpdateRecord(t.Context(), "example.com", fromLibdnsRecord(rec, "1", nil)); err != nil {
+			t.Fatalf("Failed to update %s: %v", key, err)
+		}
+		encoded[endpoint+" "+key] = query
+	}
+
+	assertGolden(t, "parameters", encoded)
+}
@@ -0,0 +1,23 @@
+package cloudns
+
+import "time"
+
+// Metrics receives measurements of the requests sent to the ClouDNS API, to
+// export them to a monitoring system. The prometheus subpackage has a
+// ready-made implementation. Implementations must be safe for concurrent use.
+//
+// Endpoints are the paths of the API requests, e.g. "/dns/records.json", and
+// zones are empty for requests not bound to a zone.
+type Metrics interface {
+	// ObserveRequest is called once the response to a request was received,
+	// with its HTTP status code, or 0 if no response was received.
+	ObserveRequest(endpoint, zone string, status int, duration time.Duration)
+
+	// ObserveRetry is called when an operation is retried, with the last
+	// request that failed.
+	ObserveRetry(endpoint, zone string)
+
+	// ObserveRateLimited is called when a request was rejected by the rate
+	// limit of the API.
+	ObserveRateLimited(endpoint, zone string)
+}
@@ -0,0 +1,76 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMetrics records the observations made by a provider.
+type recordingMetrics struct {
+	mu          sync.Mutex
+	requests    []string
+	retries     []string
+	rateLimited []string
+}
+
+func (m *recordingMetrics) ObserveRequest(endpoint, zone string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, fmt.Sprintf("%s %s %d", endpoint, zone, status))
+}
+
+func (m *recordingMetrics) ObserveRetry(endpoint, zone string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, endpoint+" "+zone)
+}
+
+func (m *recordingMetrics) ObserveRateLimited(endpoint, zone string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimited = append(m.rateLimited, endpoint+" "+zone)
+}
+
+func TestMetrics(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	metrics := &recordingMetrics{}
+	clock := &fakeClock{}
+	provider, err := NewProvider(WithCredentials("id", "password"), WithMetrics(metrics), WithClock(clock.After))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+
+	expectedRequests := []string{
+		"/dns/records.json example.com 429",
+		"/dns/records.json example.com 502",
+		"/dns/records.json example.com 200",
+	}
+	if !reflect.DeepEqual(metrics.requests, expectedRequests) {
+		t.Errorf("Expected requests %v, got %v", expectedRequests, metrics.requests)
+	}
+	if expected := []string{"/dns/records.json example.com", "/dns/records.json example.com"}; !reflect.DeepEqual(metrics.retries, expected) {
+		t.Errorf("Expected retries %v, got %v", expected, metrics.retries)
+	}
+	if expected := []string{"/dns/records.json example.com"}; !reflect.DeepEqual(metrics.rateLimited, expected) {
+		t.Errorf("Expected rate limited requests %v, got %v", expected, metrics.rateLimited)
+	}
+}
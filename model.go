@@ -1,9 +1,10 @@
 package cloudns
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 	"net/netip"
-	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -14,8 +15,8 @@ import (
 // ApiDnsRecord represents a DNS record retrieved from or sent to the API.
 // It includes fields for record identification, configuration, and status.
 type ApiDnsRecord struct {
-	Id       string `json:"id"                        parameters:"record-id"`
-	Type     string `json:"type"                      parameters:"record-type"`
+	Id       string `json:"id"`
+	Type     string `json:"type"`
 	Host     string `json:"host"`
 	Record   string `json:"record,omitempty"`
 	Failover string `json:"failover"`
@@ -27,11 +28,108 @@ type ApiDnsRecord struct {
 	Port     uint16 `json:"port,string,omitempty"`
 	Weight   uint16 `json:"weight,string,omitempty"`
 	Status   int    `json:"status"`
+
+	// SSHFP records hold the fingerprint in Record, DS records the digest
+	Algorithm  uint8  `json:"algorithm,string,omitempty"`
+	FpType     uint8  `json:"fptype,string,omitempty"`
+	KeyTag     uint16 `json:"key_tag,string,omitempty"`
+	DigestType uint8  `json:"digest_type,string,omitempty"`
+
+	// TLSA records hold the certificate association data in Record
+	TLSAUsage        uint8 `json:"tlsa_usage,string,omitempty"`
+	TLSASelector     uint8 `json:"tlsa_selector,string,omitempty"`
+	TLSAMatchingType uint8 `json:"tlsa_matching_type,string,omitempty"`
+
+	// GeoDNSLocation is the location the record is served to, in GeoDNS zones
+	GeoDNSLocation string `json:"geodns-location,omitempty"`
+}
+
+// RecordData is attached to the ProviderData field of the records returned by
+// this package, for the record types that support it. It carries ClouDNS
+// specific details that have no place in the generic libdns structures.
+type RecordData struct {
+	// ID is the ID ClouDNS assigned to the record, to reference it in later
+	// requests. It is ignored when the record is passed back to the provider.
+	ID string
+
+	// Active reports whether the record is enabled. Inactive records are
+	// kept in the zone but not served by the ClouDNS nameservers.
+	Active bool
+
+	// GeoDNSLocation is the location the record is served to, in GeoDNS
+	// zones, e.g. "EU" or "US". It is empty for the default location and
+	// for the other zone types.
+	GeoDNSLocation string
+}
+
+// Active reports whether the record is enabled on ClouDNS.
+func (r ApiDnsRecord) Active() bool {
+	return r.Status == 1
+}
+
+func (r ApiDnsRecord) providerData() RecordData {
+	return RecordData{ID: r.Id, Active: r.Active(), GeoDNSLocation: r.GeoDNSLocation}
+}
+
+// RecordID returns the ID ClouDNS assigned to a record returned by this
+// package, as carried in its RecordData, and whether it is known.
+func RecordID(rec libdns.Record) (string, bool) {
+	data, ok := recordDataOf(rec)
+	if !ok || data.ID == "" {
+		return "", false
+	}
+
+	return data.ID, true
+}
+
+// recordDataOf returns the RecordData attached to a libdns record, if any.
+func recordDataOf(rec libdns.Record) (RecordData, bool) {
+	var providerData any
+	switch impl := rec.(type) {
+	case libdns.Address:
+		providerData = impl.ProviderData
+	case libdns.CAA:
+		providerData = impl.ProviderData
+	case libdns.CNAME:
+		providerData = impl.ProviderData
+	case libdns.MX:
+		providerData = impl.ProviderData
+	case libdns.NS:
+		providerData = impl.ProviderData
+	case libdns.SRV:
+		providerData = impl.ProviderData
+	case libdns.TXT:
+		providerData = impl.ProviderData
+	}
+
+	switch data := providerData.(type) {
+	case RecordData:
+		return data, true
+	case *RecordData:
+		if data != nil {
+			return *data, true
+		}
+	}
+
+	return RecordData{}, false
+}
+
+// fromLibdnsRecord translates a libdns record into an upstream API object.
+// The TTL is rounded to the next value out of ttls, or out of the default
+// ClouDNS TTL list if ttls is empty. The GeoDNS location is taken from the
+// RecordData of the record, if any.
+func fromLibdnsRecord(rec libdns.Record, id string, ttls []int) ApiDnsRecord {
+	ret := fromLibdnsRR(rec, id, ttls)
+	if data, ok := recordDataOf(rec); ok {
+		ret.GeoDNSLocation = data.GeoDNSLocation
+	}
+
+	return ret
 }
 
-func fromLibdnsRecord(rec libdns.Record, id string) ApiDnsRecord {
-	ttl := strconv.Itoa(ttlRounder(rec.RR().TTL))
-	type_ := rec.RR().Type
+func fromLibdnsRR(rec libdns.Record, id string, ttls []int) ApiDnsRecord {
+	ttl := strconv.Itoa(ttlRounder(rec.RR().TTL, ttls))
+	type_ := canonicalRecordType(rec.RR().Type)
 
 	switch impl := rec.(type) {
 	case libdns.Address:
@@ -39,7 +137,7 @@ func fromLibdnsRecord(rec libdns.Record, id string) ApiDnsRecord {
 			Id:     id,
 			Ttl:    ttl,
 			Type:   type_,
-			Host:   impl.Name,
+			Host:   normalizeHost(impl.Name),
 			Record: impl.IP.String(),
 		}
 	case libdns.CAA:
@@ -47,10 +145,10 @@ func fromLibdnsRecord(rec libdns.Record, id string) ApiDnsRecord {
 			Id:       id,
 			Ttl:      ttl,
 			Type:     type_,
-			Host:     impl.Name,
+			Host:     normalizeHost(impl.Name),
 			CAAFlag:  impl.Flags,
-			CAAType:  impl.Tag,
-			CAAValue: impl.Value,
+			CAAType:  normalizeCAATag(impl.Tag),
+			CAAValue: unquoteCAAValue(impl.Value),
 		}
 
 	case libdns.CNAME:
@@ -58,8 +156,8 @@ func fromLibdnsRecord(rec libdns.Record, id string) ApiDnsRecord {
 			Id:     id,
 			Ttl:    ttl,
 			Type:   type_,
-			Host:   impl.Name,
-			Record: impl.Target,
+			Host:   normalizeHost(impl.Name),
+			Record: normalizeTarget(impl.Target),
 		}
 
 	case libdns.MX:
@@ -67,9 +165,9 @@ func fromLibdnsRecord(rec libdns.Record, id string) ApiDnsRecord {
 			Id:       id,
 			Ttl:      ttl,
 			Type:     type_,
-			Host:     impl.Name,
+			Host:     normalizeHost(impl.Name),
 			Priority: impl.Preference,
-			Record:   impl.Target,
+			Record:   normalizeTarget(impl.Target),
 		}
 
 	case libdns.NS:
@@ -77,34 +175,157 @@ func fromLibdnsRecord(rec libdns.Record, id string) ApiDnsRecord {
 			Id:     id,
 			Ttl:    ttl,
 			Type:   type_,
-			Host:   impl.Name,
-			Record: impl.Target,
+			Host:   normalizeHost(impl.Name),
+			Record: normalizeTarget(impl.Target),
+		}
+	case libdns.TXT:
+		return ApiDnsRecord{
+			Id:     id,
+			Ttl:    ttl,
+			Type:   type_,
+			Host:   normalizeHost(impl.Name),
+			Record: encodeTXT(impl.Text),
 		}
 	case libdns.SRV:
+		// An empty name refers to the zone apex, like "@"
+		if impl.Name == "" {
+			impl.Name = "@"
+		}
 		return ApiDnsRecord{
 			Id:       id,
 			Ttl:      ttl,
 			Type:     type_,
-			Host:     fmt.Sprintf("_%v._%v.%v", impl.Service, impl.Transport, impl.Name),
+			Host:     normalizeHost(impl.RR().Name),
 			Priority: impl.Priority,
 			Weight:   impl.Weight,
 			Port:     impl.Port,
-			Record:   impl.Target,
+			Record:   normalizeTarget(impl.Target),
 		}
 	default:
 		rr := rec.RR()
-		return ApiDnsRecord{
+		ret := ApiDnsRecord{
 			Id:     id,
 			Ttl:    ttl,
 			Type:   type_,
-			Host:   rr.Name,
+			Host:   normalizeHost(rr.Name),
 			Record: rr.Data,
 		}
+
+		// ClouDNS takes the fields of some types as separate parameters.
+		// Malformed data is sent as is, for ClouDNS to reject it.
+		switch type_ {
+		case "SSHFP":
+			if algorithm, fpType, fingerprint, err := parseSSHFP(rr.Data); err == nil {
+				ret.Algorithm = algorithm
+				ret.FpType = fpType
+				ret.Record = fingerprint
+			}
+		case "DS":
+			if ds, err := parseDSRecord("", rr.Data); err == nil {
+				ret.KeyTag = ds.KeyTag
+				ret.Algorithm = ds.Algorithm
+				ret.DigestType = ds.DigestType
+				ret.Record = ds.Digest
+			}
+		case "TLSA":
+			if usage, selector, matchingType, data, err := parseTLSA(rr.Data); err == nil {
+				ret.TLSAUsage = usage
+				ret.TLSASelector = selector
+				ret.TLSAMatchingType = matchingType
+				ret.Record = data
+			}
+		}
+
+		return ret
+	}
+}
+
+// normalizeTarget brings the target of a CNAME, MX, NS or SRV record into the
+// form used by ClouDNS: fully qualified, without the trailing dot, in
+// punycode. A single dot, meaning no target in MX and SRV records, is kept.
+func normalizeTarget(target string) string {
+	if target == "." {
+		return target
 	}
+
+	return toASCII(strings.TrimSuffix(target, "."))
+}
+
+// splitSRVHost splits the host of an SRV record, like "_sip._tcp.voip", into
+// its service, transport and name, following the conventions of libdns.SRV:
+// SRV records at the zone apex, like "_sip._tcp", get the name "@".
+func splitSRVHost(host string) (string, string, string, error) {
+	parts := strings.SplitN(host, ".", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "_") || !strings.HasPrefix(parts[1], "_") {
+		return "", "", "", fmt.Errorf("Name %q is not of the form _service._transport[.name]", host)
+	}
+
+	name := "@"
+	if len(parts) == 3 {
+		name = parts[2]
+	}
+
+	return strings.TrimPrefix(parts[0], "_"), strings.TrimPrefix(parts[1], "_"), name, nil
+}
+
+// parseSSHFP splits the data of an SSHFP record ("4 2 123456789abcdef...")
+// into its algorithm, fingerprint type and fingerprint.
+func parseSSHFP(data string) (uint8, uint8, string, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return 0, 0, "", fmt.Errorf("malformed SSHFP data %q", data)
+	}
+
+	algorithm, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid SSHFP algorithm %q: %w", fields[0], err)
+	}
+	fpType, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid SSHFP fingerprint type %q: %w", fields[1], err)
+	}
+
+	return uint8(algorithm), uint8(fpType), strings.Join(fields[2:], ""), nil
+}
+
+// parseTLSA splits the data of a TLSA record ("3 1 1 0123456789abcdef...")
+// into its certificate usage, selector, matching type and certificate
+// association data.
+func parseTLSA(data string) (uint8, uint8, uint8, string, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 4 {
+		return 0, 0, 0, "", fmt.Errorf("malformed TLSA data %q", data)
+	}
+
+	var values [3]uint8
+	for idx, name := range []string{"certificate usage", "selector", "matching type"} {
+		value, err := strconv.ParseUint(fields[idx], 10, 8)
+		if err != nil {
+			return 0, 0, 0, "", fmt.Errorf("invalid TLSA %s %q: %w", name, fields[idx], err)
+		}
+		values[idx] = uint8(value)
+	}
+
+	return values[0], values[1], values[2], strings.Join(fields[3:], ""), nil
+}
+
+// normalizeHost brings a relative record name into the form used by ClouDNS.
+// A trailing "@" label referring to the zone apex is dropped, so that "*.@"
+// becomes "*", and the zone file escape of the asterisk is unescaped, so that
+// wildcard records end up identical to the ones created in the ClouDNS UI.
+// Internationalized labels are converted to punycode.
+func normalizeHost(name string) string {
+	name = strings.ReplaceAll(name, `\042`, "*")
+	if name != "@" {
+		name = strings.TrimSuffix(name, ".@")
+	}
+
+	return toASCII(name)
 }
 
 // toLibdnsRecord translates an upstream API object into a libdns
-// record object.
+// record object. Typed records carry a RecordData in their ProviderData field.
+// Punycode names are converted back to Unicode.
 func (r ApiDnsRecord) toLibdnsRecord() (libdns.Record, error) {
 	rawttl, err := strconv.Atoi(r.Ttl)
 	if err != nil {
@@ -112,6 +333,7 @@ func (r ApiDnsRecord) toLibdnsRecord() (libdns.Record, error) {
 	}
 	ttl := time.Duration(rawttl) * time.Second
 
+	r.Type = canonicalRecordType(r.Type)
 	switch r.Type {
 	case "A", "AAAA":
 		addr, err := netip.ParseAddr(r.Record)
@@ -120,62 +342,97 @@ func (r ApiDnsRecord) toLibdnsRecord() (libdns.Record, error) {
 		}
 
 		return libdns.Address{
-			Name: r.Host,
+			Name: toUnicode(r.Host),
 			TTL:  ttl,
 			IP:   addr,
+
+			ProviderData: r.providerData(),
 		}, nil
 	case "CAA":
 		return libdns.CAA{
-			Name:  r.Host,
+			Name:  toUnicode(r.Host),
 			TTL:   ttl,
 			Flags: r.CAAFlag,
 			Tag:   r.CAAType,
-			Value: r.CAAValue,
+			Value: unquoteCAAValue(r.CAAValue),
+
+			ProviderData: r.providerData(),
 		}, nil
 	case "CNAME":
 		return libdns.CNAME{
-			Name:   r.Host,
+			Name:   toUnicode(r.Host),
 			TTL:    ttl,
-			Target: r.Record,
+			Target: toUnicode(normalizeTarget(r.Record)),
+
+			ProviderData: r.providerData(),
 		}, nil
 	case "MX":
 		return libdns.MX{
-			Name:       r.Host,
+			Name:       toUnicode(r.Host),
 			TTL:        ttl,
 			Preference: r.Priority,
-			Target:     r.Record,
+			Target:     toUnicode(normalizeTarget(r.Record)),
+
+			ProviderData: r.providerData(),
 		}, nil
 	case "NS":
 		return libdns.NS{
-			Name:   r.Host,
+			Name:   toUnicode(r.Host),
 			TTL:    ttl,
-			Target: r.Record,
+			Target: toUnicode(normalizeTarget(r.Record)),
+
+			ProviderData: r.providerData(),
 		}, nil
 	case "SRV":
-		parts := strings.SplitN(r.Host, ".", 3)
-		if len(parts) < 3 {
-			return libdns.SRV{}, fmt.Errorf("Name %q does not have enough components (expected >3, got %v)", r.Host, len(parts))
+		service, transport, name, err := splitSRVHost(r.Host)
+		if err != nil {
+			return libdns.SRV{}, err
 		}
 		return libdns.SRV{
-			Service:   strings.TrimPrefix(parts[0], "_"),
-			Transport: strings.TrimPrefix(parts[1], "_"),
-			Name:      parts[2],
+			Service:   service,
+			Transport: transport,
+			Name:      toUnicode(name),
 			TTL:       ttl,
 			Priority:  r.Priority,
 			Weight:    r.Weight,
 			Port:      r.Port,
-			Target:    r.Record,
+			Target:    toUnicode(normalizeTarget(r.Record)),
+
+			ProviderData: r.providerData(),
 		}, nil
 	case "TXT":
 		return libdns.TXT{
-			Name: r.Host,
+			Name: toUnicode(r.Host),
+			TTL:  ttl,
+			Text: decodeTXT(r.Record),
+
+			ProviderData: r.providerData(),
+		}, nil
+	case "SSHFP":
+		return libdns.RR{
+			Name: toUnicode(r.Host),
 			TTL:  ttl,
-			Text: r.Record,
+			Type: r.Type,
+			Data: fmt.Sprintf("%d %d %s", r.Algorithm, r.FpType, r.Record),
+		}, nil
+	case "DS":
+		return libdns.RR{
+			Name: toUnicode(r.Host),
+			TTL:  ttl,
+			Type: r.Type,
+			Data: fmt.Sprintf("%d %d %d %s", r.KeyTag, r.Algorithm, r.DigestType, r.Record),
+		}, nil
+	case "TLSA":
+		return libdns.RR{
+			Name: toUnicode(r.Host),
+			TTL:  ttl,
+			Type: r.Type,
+			Data: fmt.Sprintf("%d %d %d %s", r.TLSAUsage, r.TLSASelector, r.TLSAMatchingType, r.Record),
 		}, nil
 	// HTTPS and SVCB do not appear supported by ClouDNS rn
 	default:
 		return libdns.RR{
-			Name: r.Host,
+			Name: toUnicode(r.Host),
 			TTL:  ttl,
 			Type: r.Type,
 			Data: r.Record,
@@ -183,20 +440,56 @@ func (r ApiDnsRecord) toLibdnsRecord() (libdns.Record, error) {
 	}
 }
 
+// toParameters returns the parameters of add-record.json and mod-record.json
+// describing the record. All the fields of the record type are sent, even
+// when zero, like an MX preference or an SRV weight of 0.
 func (r ApiDnsRecord) toParameters() map[string]string {
-	ret := make(map[string]string)
-
-	val := reflect.ValueOf(r)
-	typ := reflect.TypeOf(r)
-	for idx := range val.NumField() {
-		field := val.Field(idx)
-		if !field.IsZero() {
-			name := typ.Field(idx).Tag.Get("parameters")
-			if name == "" {
-				name = typ.Field(idx).Tag.Get("json")
-				name = strings.Split(name, ",")[0]
-			}
-			ret[name] = fmt.Sprintf("%v", field)
+	r.Type = canonicalRecordType(r.Type)
+	ret := map[string]string{
+		"record-type": r.Type,
+		"host":        r.Host,
+		"ttl":         r.Ttl,
+	}
+	if r.Id != "" {
+		ret["record-id"] = r.Id
+	}
+	if r.GeoDNSLocation != "" {
+		ret["geodns-location"] = r.GeoDNSLocation
+	}
+
+	switch r.Type {
+	case "MX":
+		ret["priority"] = strconv.Itoa(int(r.Priority))
+		ret["record"] = r.Record
+	case "SRV":
+		ret["priority"] = strconv.Itoa(int(r.Priority))
+		ret["weight"] = strconv.Itoa(int(r.Weight))
+		ret["port"] = strconv.Itoa(int(r.Port))
+		ret["record"] = r.Record
+	case "CAA":
+		ret["caa_flag"] = strconv.Itoa(int(r.CAAFlag))
+		ret["caa_type"] = r.CAAType
+		ret["caa_value"] = r.CAAValue
+	case "SSHFP":
+		ret["algorithm"] = strconv.Itoa(int(r.Algorithm))
+		ret["fptype"] = strconv.Itoa(int(r.FpType))
+		ret["record"] = r.Record
+	case "TLSA":
+		ret["tlsa-usage"] = strconv.Itoa(int(r.TLSAUsage))
+		ret["tlsa-selector"] = strconv.Itoa(int(r.TLSASelector))
+		ret["tlsa-matching-type"] = strconv.Itoa(int(r.TLSAMatchingType))
+		ret["record"] = r.Record
+	case "DS":
+		ret["key-tag"] = strconv.Itoa(int(r.KeyTag))
+		ret["algorithm"] = strconv.Itoa(int(r.Algorithm))
+		ret["digest-type"] = strconv.Itoa(int(r.DigestType))
+		ret["record"] = r.Record
+	default:
+		// The data of multi-field types is split into their parameters
+		if params, ok := rdataParameters(r.Type, r.Record); ok {
+			maps.Copy(ret, params)
+		} else {
+			ret["record"] = r.Record
 		}
 	}
 
@@ -211,3 +504,155 @@ type ApiResponse struct {
 		Id int `json:"id"`
 	} `json:"data,omitempty"`
 }
+
+// NameServer represents a ClouDNS nameserver available to the account, as
+// returned by the available-name-servers.json endpoint.
+type NameServer struct {
+	// Type is the plan the nameserver belongs to, e.g. "free" or "premium"
+	Type       string     `json:"type"`
+	Name       string     `json:"name"`
+	IPv4       netip.Addr `json:"ip4"`
+	IPv6       netip.Addr `json:"ip6"`
+	Location   string     `json:"location"`
+	LocationCC string     `json:"location_cc"`
+}
+
+// ZoneGroup represents a group zones can be organized in.
+type ZoneGroup struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ZoneStats holds the number of zones in the account and the maximum number
+// of zones allowed by its ClouDNS plan.
+type ZoneStats struct {
+	Count int
+	Limit int
+}
+
+// Remaining returns how many more zones can be created within the plan limit.
+func (s ZoneStats) Remaining() int {
+	return max(s.Limit-s.Count, 0)
+}
+
+// APIUsage holds the number of API requests made with the credentials of the
+// client during the current period, and the maximum number allowed by the
+// ClouDNS plan before requests are throttled.
+type APIUsage struct {
+	Requests int
+	Limit    int
+
+	// Period is the window the limit applies to, as reported by ClouDNS
+	Period string
+}
+
+// Remaining returns how many more requests can be made within the limit.
+func (u APIUsage) Remaining() int {
+	return max(u.Limit-u.Requests, 0)
+}
+
+// flexInt decodes integers that the API returns either as JSON numbers or as
+// strings, depending on the endpoint.
+type flexInt int
+
+func (i *flexInt) UnmarshalJSON(data []byte) error {
+	unquoted := strings.Trim(string(data), `"`)
+	if unquoted == "" || unquoted == "null" {
+		*i = 0
+		return nil
+	}
+
+	value, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s: %w", data, err)
+	}
+
+	*i = flexInt(value)
+	return nil
+}
+
+// flexUint8 and flexUint16 decode unsigned integers that the API returns
+// either as JSON numbers or as strings, see flexInt.
+type (
+	flexUint8  uint8
+	flexUint16 uint16
+)
+
+func (u *flexUint8) UnmarshalJSON(data []byte) error {
+	value, err := parseFlexUint(data, 8)
+	*u = flexUint8(value)
+	return err
+}
+
+func (u *flexUint16) UnmarshalJSON(data []byte) error {
+	value, err := parseFlexUint(data, 16)
+	*u = flexUint16(value)
+	return err
+}
+
+// parseFlexUint parses an unsigned integer of the given bit size, quoted or
+// not. Empty values and null are zero.
+func parseFlexUint(data []byte, bitSize int) (uint64, error) {
+	unquoted := strings.Trim(string(data), `"`)
+	if unquoted == "" || unquoted == "null" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseUint(unquoted, 10, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unsigned integer %s: %w", data, err)
+	}
+
+	return value, nil
+}
+
+// UnmarshalJSON decodes a status object, accepting the ID of created objects
+// both as a JSON number and as a string.
+func (r *ApiResponse) UnmarshalJSON(data []byte) error {
+	var response struct {
+		Status            flexString `json:"status"`
+		StatusDescription string     `json:"statusDescription"`
+		Data              struct {
+			Id flexInt `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+
+	r.Status = string(response.Status)
+	r.StatusDescription = response.StatusDescription
+	r.Data.Id = int(response.Data.Id)
+	return nil
+}
+
+// MasterServer is a master server a slave zone transfers its records from.
+type MasterServer struct {
+	Id   string `json:"id"`
+	Host string `json:"host"`
+}
+
+// flexString decodes values that the API returns either as JSON strings or as
+// numbers, depending on the endpoint.
+type flexString string
+
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = ""
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = flexString(str)
+		return nil
+	}
+
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return fmt.Errorf("invalid string %s: %w", data, err)
+	}
+
+	*s = flexString(num.String())
+	return nil
+}
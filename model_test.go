@@ -1,8 +1,14 @@
 package cloudns
 
 import (
+	"encoding/json"
 	"errors"
+	"maps"
+	"net/netip"
 	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
 )
 
 var records = []ApiDnsRecord{
@@ -62,11 +68,89 @@ var records = []ApiDnsRecord{
 		Record:   "other.example.com",
 	},
 	{
-		Id:     "8",
+		Id:     "9",
+		Ttl:    "60",
+		Type:   "A",
+		Host:   "*",
+		Record: "127.0.0.1",
+	},
+	{
+		Id:     "10",
+		Ttl:    "60",
+		Type:   "TXT",
+		Host:   "*.sub",
+		Record: "wildcard",
+	},
+	{
+		Id:     "11",
+		Ttl:    "60",
+		Type:   "CNAME",
+		Host:   "*.sub",
+		Record: "other.example.com",
+	},
+	{
+		Id:       "16",
+		Ttl:      "60",
+		Type:     "SRV",
+		Host:     "_sip._tcp",
+		Priority: 10,
+		Weight:   0,
+		Port:     5060,
+		Record:   "sip.example.com",
+	},
+	{
+		Id:       "17",
+		Ttl:      "60",
+		Type:     "SRV",
+		Host:     "_xmpp-server._tcp.chat.eu",
+		Priority: 5,
+		Weight:   10,
+		Port:     5269,
+		Record:   "xmpp.example.com",
+	},
+	{
+		Id:             "12",
+		Ttl:            "60",
+		Type:           "A",
+		Host:           "geo",
+		Record:         "192.0.2.10",
+		GeoDNSLocation: "EU",
+	},
+	{
+		Id:               "13",
+		Ttl:              "60",
+		Type:             "TLSA",
+		Host:             "_443._tcp.www",
+		TLSAUsage:        3,
+		TLSASelector:     1,
+		TLSAMatchingType: 1,
+		Record:           "0C72AC70B745AC19998811B131D662C9AC69DBDBE7CB23E5B514B56664C5D3D6",
+	},
+	{
+		Id:     "14",
 		Ttl:    "60",
-		Type:   "SSHFP",
-		Host:   "ssh.example.com",
-		Record: "4 1 834B398AFD6CBFD93D06F26D2E23E0BAF6576A9D",
+		Type:   "TLSA",
+		Host:   "_25._tcp.mail",
+		Record: "308201A2300D06092A864886F70D01010105000382018F00",
+	},
+	{
+		Id:         "15",
+		Ttl:        "3600",
+		Type:       "DS",
+		Host:       "child",
+		KeyTag:     2371,
+		Algorithm:  13,
+		DigestType: 2,
+		Record:     "1F987CC6583E92DF0890718C42",
+	},
+	{
+		Id:        "8",
+		Ttl:       "60",
+		Type:      "SSHFP",
+		Host:      "ssh.example.com",
+		Algorithm: 4,
+		FpType:    1,
+		Record:    "834B398AFD6CBFD93D06F26D2E23E0BAF6576A9D",
 	},
 }
 
@@ -78,7 +162,7 @@ func TestRoundTrip(t *testing.T) {
 			t.Errorf("Error converting record %+v to libdns record: %v", rec, err)
 		}
 
-		newrec := fromLibdnsRecord(libdnsrec, id)
+		newrec := fromLibdnsRecord(libdnsrec, id, nil)
 		if newrec != rec {
 			t.Errorf("Expected newrec == rec: %+v == %+v", newrec, rec)
 		}
@@ -89,8 +173,13 @@ var invalidRecords = map[ApiDnsRecord]error{
 	{
 		Type: "SRV",
 		Ttl:  "60",
-		Host: "_http._tcp",
-	}: errors.New("Name \"_http._tcp\" does not have enough components (expected >3, got 2)"),
+		Host: "_http",
+	}: errors.New("Name \"_http\" is not of the form _service._transport[.name]"),
+	{
+		Type: "SRV",
+		Ttl:  "60",
+		Host: "sip.tcp.example",
+	}: errors.New("Name \"sip.tcp.example\" is not of the form _service._transport[.name]"),
 	{
 		Ttl: "foo",
 	}: errors.New("Invalid TTL \"foo\""),
@@ -113,3 +202,329 @@ func TestBadConversions(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeHost(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"@":        "@",
+		"www":      "www",
+		"*":        "*",
+		"*.@":      "*",
+		`\042.sub`: "*.sub",
+		"*.sub.@":  "*.sub",
+	}
+
+	for in, expected := range tests {
+		if out := normalizeHost(in); out != expected {
+			t.Errorf("normalizeHost(%q): expected %q, got %q", in, expected, out)
+		}
+	}
+}
+
+func TestWildcardMatching(t *testing.T) {
+	existing := clouDNSRecordsToMap([]ApiDnsRecord{
+		{Id: "1", Host: "*", Type: "A", Record: "192.0.2.1", Ttl: "60"},
+		{Id: "2", Host: "*.sub", Type: "TXT", Record: "wildcard", Ttl: "60"},
+	})
+	desired := []libdns.Record{
+		libdns.Address{Name: "*.@", TTL: time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.TXT{Name: `\042.sub`, TTL: time.Minute, Text: "wildcard"},
+	}
+
+	if ops := makeOperationList(libdnsRecordsToMap(desired), existing, nil); len(ops) != 0 {
+		t.Errorf("Expected wildcard records to match, got operations %+v", ops)
+	}
+
+	for _, rec := range desired {
+		if err := validateRecord(rec); err != nil {
+			t.Errorf("Expected wildcard record to be valid: %v", err)
+		}
+
+		rr := rec.RR()
+		matches := existing[newNameAndType(rr.Name, rr.Type)]
+		if len(matches) != 1 {
+			t.Fatalf("Expected one record to match %q for deletion, got %v", rr.Name, matches)
+		}
+		matched, err := matches[0].toLibdnsRecord()
+		if err != nil {
+			t.Fatalf("Failed to convert record: %v", err)
+		}
+		if !matchDeleteTarget(rec, matched) {
+			t.Errorf("Expected %+v to match deletion target %+v", matched, rec)
+		}
+	}
+}
+
+func TestGeoDNSLocation(t *testing.T) {
+	existing := clouDNSRecordsToMap([]ApiDnsRecord{
+		{Id: "1", Host: "www", Type: "A", Record: "192.0.2.1", Ttl: "60", Status: 1, GeoDNSLocation: "EU"},
+		{Id: "2", Host: "www", Type: "A", Record: "192.0.2.2", Ttl: "60", Status: 1, GeoDNSLocation: "US"},
+	})
+
+	// Records without RecordData keep their location
+	unspecified := []libdns.Record{
+		libdns.Address{Name: "www", TTL: time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", TTL: time.Minute, IP: netip.MustParseAddr("192.0.2.2")},
+	}
+	if ops := makeOperationList(libdnsRecordsToMap(unspecified), existing, nil); len(ops) != 0 {
+		t.Errorf("Expected no operations, got %+v", ops)
+	}
+
+	// Records with RecordData are moved to the requested location
+	moved := []libdns.Record{
+		libdns.Address{Name: "www", TTL: time.Minute, IP: netip.MustParseAddr("192.0.2.1"), ProviderData: RecordData{Active: true, GeoDNSLocation: "EU"}},
+		libdns.Address{Name: "www", TTL: time.Minute, IP: netip.MustParseAddr("192.0.2.2"), ProviderData: RecordData{Active: true, GeoDNSLocation: "ASIA"}},
+	}
+	ops := makeOperationList(libdnsRecordsToMap(moved), existing, nil)
+	if len(ops) != 1 || ops[0].op != modifyRecord || ops[0].record.Id != "2" || ops[0].record.GeoDNSLocation != "ASIA" {
+		t.Errorf("Expected record 2 to be moved to ASIA, got %+v", ops)
+	}
+	if params := ops[0].record.toParameters(); params["geodns-location"] != "ASIA" {
+		t.Errorf("Expected geodns-location parameter, got %v", params)
+	}
+}
+
+func TestSSHFPParameters(t *testing.T) {
+	rec := libdns.RR{Name: "ssh", TTL: time.Minute, Type: "SSHFP", Data: "4 2 123456789abcdef"}
+
+	params := fromLibdnsRecord(rec, "", nil).toParameters()
+	if params["algorithm"] != "4" || params["fptype"] != "2" || params["record"] != "123456789abcdef" {
+		t.Errorf("Unexpected SSHFP parameters %v", params)
+	}
+}
+
+func TestTLSAParameters(t *testing.T) {
+	rec := libdns.RR{Name: "_25._tcp.mail", TTL: time.Minute, Type: "TLSA", Data: "0 0 0 308201a2"}
+
+	params := fromLibdnsRecord(rec, "", nil).toParameters()
+	if params["tlsa-usage"] != "0" || params["tlsa-selector"] != "0" || params["tlsa-matching-type"] != "0" || params["record"] != "308201a2" {
+		t.Errorf("Unexpected TLSA parameters %v", params)
+	}
+
+	rr, err := fromLibdnsRecord(rec, "", nil).toLibdnsRecord()
+	if err != nil {
+		t.Fatalf("Failed to convert TLSA record: %v", err)
+	}
+	if rr.RR().Data != rec.Data {
+		t.Errorf("Expected data %q, got %q", rec.Data, rr.RR().Data)
+	}
+}
+
+func TestDSParameters(t *testing.T) {
+	rec := DSRecord{Name: "child", TTL: time.Hour, KeyTag: 2371, Algorithm: 13, DigestType: 2, Digest: "1F987CC6583E92DF0890718C42"}
+
+	params := fromLibdnsRecord(rec, "", nil).toParameters()
+	if params["key-tag"] != "2371" || params["algorithm"] != "13" || params["digest-type"] != "2" || params["record"] != "1F987CC6583E92DF0890718C42" {
+		t.Errorf("Unexpected DS parameters %v", params)
+	}
+}
+
+func TestCAAParameters(t *testing.T) {
+	rec := libdns.CAA{Name: "@", TTL: time.Minute, Flags: 0, Tag: "Issue", Value: `"letsencrypt.org"`}
+
+	params := fromLibdnsRecord(rec, "", nil).toParameters()
+	if params["caa_flag"] != "0" || params["caa_type"] != "issue" || params["caa_value"] != "letsencrypt.org" {
+		t.Errorf("Unexpected CAA parameters %v", params)
+	}
+
+	critical := libdns.CAA{Name: "@", TTL: time.Minute, Flags: 128, Tag: "iodef", Value: "mailto:security@example.com"}
+	if params := fromLibdnsRecord(critical, "", nil).toParameters(); params["caa_flag"] != "128" {
+		t.Errorf("Expected the critical flag to be sent, got %v", params)
+	}
+}
+
+func TestSRVHost(t *testing.T) {
+	tests := map[string]libdns.SRV{
+		"_sip._tcp":         {Service: "sip", Transport: "tcp", Name: "@"},
+		"_sip._udp":         {Service: "sip", Transport: "udp", Name: ""},
+		"_sip._tls.voip.eu": {Service: "sip", Transport: "tls", Name: "voip.eu"},
+	}
+
+	for expected, srv := range tests {
+		srv.Port = 5060
+		srv.Target = "sip.example.com"
+		if host := fromLibdnsRecord(srv, "", nil).Host; host != expected {
+			t.Errorf("Expected host %q for %+v, got %q", expected, srv, host)
+		}
+	}
+}
+
+func TestToParameters(t *testing.T) {
+	common := func(type_, host string, extra map[string]string) map[string]string {
+		params := map[string]string{"record-id": "1", "record-type": type_, "host": host, "ttl": "60"}
+		maps.Copy(params, extra)
+		return params
+	}
+
+	tests := []struct {
+		name     string
+		record   ApiDnsRecord
+		expected map[string]string
+	}{
+		{
+			name:     "A",
+			record:   ApiDnsRecord{Id: "1", Type: "A", Host: "www", Ttl: "60", Record: "192.0.2.1", Failover: "0", Status: 1},
+			expected: common("A", "www", map[string]string{"record": "192.0.2.1"}),
+		},
+		{
+			name:     "apex AAAA",
+			record:   ApiDnsRecord{Id: "1", Type: "AAAA", Host: "", Ttl: "60", Record: "2001:db8::1"},
+			expected: common("AAAA", "", map[string]string{"record": "2001:db8::1"}),
+		},
+		{
+			name:     "CNAME in a GeoDNS zone",
+			record:   ApiDnsRecord{Id: "1", Type: "CNAME", Host: "www", Ttl: "60", Record: "eu.example.com", GeoDNSLocation: "EU"},
+			expected: common("CNAME", "www", map[string]string{"record": "eu.example.com", "geodns-location": "EU"}),
+		},
+		{
+			name:     "MX with preference 0",
+			record:   ApiDnsRecord{Id: "1", Type: "MX", Host: "", Ttl: "60", Priority: 0, Record: "mail.example.com"},
+			expected: common("MX", "", map[string]string{"priority": "0", "record": "mail.example.com"}),
+		},
+		{
+			name:     "null MX",
+			record:   ApiDnsRecord{Id: "1", Type: "MX", Host: "", Ttl: "60", Priority: 0, Record: "."},
+			expected: common("MX", "", map[string]string{"priority": "0", "record": "."}),
+		},
+		{
+			name:     "SRV with weight 0",
+			record:   ApiDnsRecord{Id: "1", Type: "SRV", Host: "_sip._tcp", Ttl: "60", Priority: 10, Weight: 0, Port: 5060, Record: "sip.example.com"},
+			expected: common("SRV", "_sip._tcp", map[string]string{"priority": "10", "weight": "0", "port": "5060", "record": "sip.example.com"}),
+		},
+		{
+			name:     "CAA with flag 0",
+			record:   ApiDnsRecord{Id: "1", Type: "CAA", Host: "", Ttl: "60", CAAFlag: 0, CAAType: "issue", CAAValue: "letsencrypt.org"},
+			expected: common("CAA", "", map[string]string{"caa_flag": "0", "caa_type": "issue", "caa_value": "letsencrypt.org"}),
+		},
+		{
+			name:     "TXT",
+			record:   ApiDnsRecord{Id: "1", Type: "TXT", Host: "_acme-challenge", Ttl: "60", Record: "token"},
+			expected: common("TXT", "_acme-challenge", map[string]string{"record": "token"}),
+		},
+		{
+			name:     "SSHFP",
+			record:   ApiDnsRecord{Id: "1", Type: "SSHFP", Host: "ssh", Ttl: "60", Algorithm: 4, FpType: 2, Record: "123456789abcdef"},
+			expected: common("SSHFP", "ssh", map[string]string{"algorithm": "4", "fptype": "2", "record": "123456789abcdef"}),
+		},
+		{
+			name:     "TLSA with zero fields",
+			record:   ApiDnsRecord{Id: "1", Type: "TLSA", Host: "_25._tcp.mail", Ttl: "60", Record: "308201a2"},
+			expected: common("TLSA", "_25._tcp.mail", map[string]string{"tlsa-usage": "0", "tlsa-selector": "0", "tlsa-matching-type": "0", "record": "308201a2"}),
+		},
+		{
+			name:     "DS",
+			record:   ApiDnsRecord{Id: "1", Type: "DS", Host: "child", Ttl: "60", KeyTag: 2371, Algorithm: 13, DigestType: 2, Record: "1F987CC6"},
+			expected: common("DS", "child", map[string]string{"key-tag": "2371", "algorithm": "13", "digest-type": "2", "record": "1F987CC6"}),
+		},
+		{
+			name:     "RP",
+			record:   ApiDnsRecord{Id: "1", Type: "RP", Host: "", Ttl: "60", Record: "admin.example.com. info.example.com."},
+			expected: common("RP", "", map[string]string{"mail": "admin.example.com.", "txt": "info.example.com."}),
+		},
+		{
+			name:     "unknown type",
+			record:   ApiDnsRecord{Id: "1", Type: "WR", Host: "old", Ttl: "60", Record: "https://example.com/"},
+			expected: common("WR", "old", map[string]string{"record": "https://example.com/"}),
+		},
+		{
+			name:     "new record",
+			record:   ApiDnsRecord{Type: "A", Host: "www", Ttl: "60", Record: "192.0.2.1"},
+			expected: map[string]string{"record-type": "A", "host": "www", "ttl": "60", "record": "192.0.2.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if params := tt.record.toParameters(); !maps.Equal(params, tt.expected) {
+				t.Errorf("Expected parameters %v, got %v", tt.expected, params)
+			}
+		})
+	}
+}
+
+func TestTargetNormalization(t *testing.T) {
+	existing := clouDNSRecordsToMap([]ApiDnsRecord{
+		{Id: "1", Host: "www", Type: "CNAME", Record: "web.example.com", Ttl: "60"},
+		{Id: "2", Host: "", Type: "MX", Priority: 10, Record: "mail.example.com.", Ttl: "60"},
+		{Id: "3", Host: "_sip._tcp", Type: "SRV", Priority: 10, Port: 5060, Record: "sip.example.com", Ttl: "60"},
+	})
+	desired := []libdns.Record{
+		libdns.CNAME{Name: "www", TTL: time.Minute, Target: "web.example.com."},
+		libdns.MX{Name: "", TTL: time.Minute, Preference: 10, Target: "Mail.Example.com"},
+		libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", TTL: time.Minute, Priority: 10, Port: 5060, Target: "sip.example.com."},
+	}
+
+	if ops := makeOperationList(libdnsRecordsToMap(desired), existing, nil); len(ops) != 0 {
+		t.Errorf("Expected targets to match regardless of the trailing dot, got operations %+v", ops)
+	}
+
+	if target := fromLibdnsRecord(desired[0], "", nil).Record; target != "web.example.com" {
+		t.Errorf("Expected the trailing dot to be dropped, got %q", target)
+	}
+	nullMX := libdns.MX{Name: "", TTL: time.Minute, Target: "."}
+	if target := fromLibdnsRecord(nullMX, "", nil).Record; target != "." {
+		t.Errorf("Expected the null MX target to be kept, got %q", target)
+	}
+	rec, err := existing[newNameAndType("", "MX")][0].toLibdnsRecord()
+	if err != nil {
+		t.Fatalf("Failed to convert record: %v", err)
+	}
+	if target := rec.(libdns.MX).Target; target != "mail.example.com" {
+		t.Errorf("Expected the trailing dot to be dropped on reads, got %q", target)
+	}
+}
+
+func TestRecordID(t *testing.T) {
+	rec, err := ApiDnsRecord{Id: "42", Type: "A", Host: "www", Record: "192.0.2.1", Ttl: "60", Status: 1}.toLibdnsRecord()
+	if err != nil {
+		t.Fatalf("Failed to convert record: %v", err)
+	}
+	if id, ok := RecordID(rec); !ok || id != "42" {
+		t.Errorf("Expected ID 42, got %q", id)
+	}
+
+	if id, ok := RecordID(libdns.TXT{Name: "www", Text: "hello"}); ok {
+		t.Errorf("Expected no ID for a record without RecordData, got %q", id)
+	}
+
+	// The ID is not sent back when the record is added again
+	if upstream := fromLibdnsRecord(rec, "", nil); upstream.Id != "" {
+		t.Errorf("Expected the ID of the RecordData to be ignored, got %q", upstream.Id)
+	}
+}
+
+func TestUnmarshalFieldVariations(t *testing.T) {
+	variants := []string{
+		`{"id":"7","type":"SRV","host":"_sip._tcp","record":"sip.example.com","ttl":"3600","priority":"10","weight":"5","port":"5060","status":1}`,
+		`{"id":7,"type":"SRV","host":"_sip._tcp","record":"sip.example.com","ttl":3600,"priority":10,"weight":5,"port":5060,"status":"1"}`,
+	}
+
+	expected := ApiDnsRecord{Id: "7", Type: "SRV", Host: "_sip._tcp", Record: "sip.example.com", Ttl: "3600", Priority: 10, Weight: 5, Port: 5060, Status: 1}
+	for _, variant := range variants {
+		var record ApiDnsRecord
+		if err := json.Unmarshal([]byte(variant), &record); err != nil {
+			t.Fatalf("Failed to decode %s: %v", variant, err)
+		}
+		if record != expected {
+			t.Errorf("Expected %+v, got %+v from %s", expected, record, variant)
+		}
+	}
+
+	var record ApiDnsRecord
+	if err := json.Unmarshal([]byte(`{"id":"1","type":"MX","priority":"70000"}`), &record); err == nil {
+		t.Errorf("Expected an out of range priority to be rejected, got %+v", record)
+	}
+
+	for _, variant := range []string{
+		`{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":12}}`,
+		`{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":"12"}}`,
+	} {
+		var response ApiResponse
+		if err := json.Unmarshal([]byte(variant), &response); err != nil {
+			t.Fatalf("Failed to decode %s: %v", variant, err)
+		}
+		if response.Status != "Success" || response.Data.Id != 12 {
+			t.Errorf("Unexpected response %+v from %s", response, variant)
+		}
+	}
+}
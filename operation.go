@@ -0,0 +1,90 @@
+package cloudns
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// operationKey is the context key of the operation a request belongs to.
+type operationKey struct{}
+
+// operationIDKey is the context key of an operation ID set by the caller.
+type operationIDKey struct{}
+
+// operationInfo describes a top level call to the Provider, e.g. SetRecords,
+// spanning any number of requests and retries.
+type operationInfo struct {
+	id string
+
+	// endpoint and zone are those of the last request sent, to label the
+	// retries of the operation
+	mu       sync.Mutex
+	endpoint string
+	zone     string
+}
+
+// WithOperationID returns a context making the provider use the given ID for
+// the operations started with it, instead of a random one, e.g. to correlate
+// them with the ID of an incoming request.
+func WithOperationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, operationIDKey{}, id)
+}
+
+// OperationID returns the ID of the operation of the context, as included in
+// the log lines and errors of the operation, or an empty string.
+func OperationID(ctx context.Context) string {
+	if op, ok := ctx.Value(operationKey{}).(*operationInfo); ok {
+		return op.id
+	}
+	if id, ok := ctx.Value(operationIDKey{}).(string); ok {
+		return id
+	}
+
+	return ""
+}
+
+// withOperation returns a context for a top level operation, with an ID set
+// by WithOperationID or a random one. Operations started by another one keep
+// its ID.
+func withOperation(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(operationKey{}).(*operationInfo); ok {
+		return ctx
+	}
+
+	id, ok := ctx.Value(operationIDKey{}).(string)
+	if !ok || id == "" {
+		id = newOperationID()
+	}
+
+	return context.WithValue(ctx, operationKey{}, &operationInfo{id: id})
+}
+
+// newOperationID returns a random operation ID.
+func newOperationID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// trackRequest sets the last request of the operation of the context.
+func trackRequest(ctx context.Context, endpoint, zone string) {
+	if op, ok := ctx.Value(operationKey{}).(*operationInfo); ok {
+		op.mu.Lock()
+		defer op.mu.Unlock()
+		op.endpoint, op.zone = endpoint, zone
+	}
+}
+
+// lastRequestOf returns the last request of the operation of the context.
+func lastRequestOf(ctx context.Context) (endpoint, zone string) {
+	if op, ok := ctx.Value(operationKey{}).(*operationInfo); ok {
+		op.mu.Lock()
+		defer op.mu.Unlock()
+		return op.endpoint, op.zone
+	}
+
+	return "", ""
+}
@@ -0,0 +1,72 @@
+package cloudns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOperationID(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"status":"Failed","statusDescription":"Invalid record type."}`)
+	})
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	clock := &fakeClock{}
+	provider, err := NewProvider(WithCredentials("id", "password"), WithLogger(logger), WithClock(clock.After))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	ctx := WithOperationID(t.Context(), "request-42")
+	_, err = provider.GetRecords(ctx, "example.com")
+	if err == nil || !strings.Contains(err.Error(), "operation request-42: ") {
+		t.Fatalf("Expected the error to carry the operation ID, got %v", err)
+	}
+	var opErr *operationError
+	if !errors.As(err, &opErr) || opErr.id != "request-42" {
+		t.Errorf("Expected an operationError, got %#v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a line per request and attempt, got:\n%s", logs.String())
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		if entry["operation"] != "request-42" {
+			t.Errorf("Expected the operation ID in %s", line)
+		}
+	}
+}
+
+func TestOperationIDGenerated(t *testing.T) {
+	if OperationID(t.Context()) != "" {
+		t.Errorf("Expected no operation ID outside of an operation")
+	}
+
+	first, second := OperationID(withOperation(t.Context())), OperationID(withOperation(t.Context()))
+	if first == "" || first == second {
+		t.Errorf("Expected distinct random operation IDs, got %q and %q", first, second)
+	}
+
+	ctx := withOperation(t.Context())
+	if nested := OperationID(withOperation(ctx)); nested != OperationID(ctx) {
+		t.Errorf("Expected nested operations to keep the ID %q, got %q", OperationID(ctx), nested)
+	}
+}
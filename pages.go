@@ -0,0 +1,105 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// validRowsPerPage are the page sizes ClouDNS accepts for paginated results.
+var validRowsPerPage = []int{10, 20, 30, 50, 100}
+
+// getRecordPages fetches the records matching the filter page by page, with
+// up to PageConcurrency pages at the same time once the number of pages is
+// known. A page size ClouDNS does not accept is reported as ErrInvalidConfig
+// before any request is sent.
+func (c *Client) getRecordPages(ctx context.Context, params map[string]string, filter RecordFilter) ([]ApiDnsRecord, error) {
+	if !slices.Contains(validRowsPerPage, c.RecordsPerPage) {
+		return nil, fmt.Errorf("%w: RecordsPerPage must be one of %v, got %d", ErrInvalidConfig, validRowsPerPage, c.RecordsPerPage)
+	}
+
+	params = maps.Clone(params)
+	params["rows-per-page"] = strconv.Itoa(c.RecordsPerPage)
+
+	count, err := c.getRecordPagesCount(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	pages := make([][]ApiDnsRecord, count)
+	sem := make(chan struct{}, max(c.PageConcurrency, 1))
+	for page := 1; page <= count; page++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		pageParams := maps.Clone(params)
+		pageParams["page"] = strconv.Itoa(page)
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			records, err := c.getRecords(ctx, pageParams, filter)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("failed to get records on page %d: %w", page, err)
+					cancel()
+				})
+				return
+			}
+			pages[page-1] = records
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Records added or deleted while the pages are fetched shift the others
+	// between pages, so they may show up twice
+	records := make([]ApiDnsRecord, 0, count*c.RecordsPerPage)
+	seen := make(map[string]bool, count*c.RecordsPerPage)
+	for _, page := range pages {
+		for _, record := range page {
+			if !seen[record.Id] {
+				seen[record.Id] = true
+				records = append(records, record)
+			}
+		}
+	}
+
+	return records, nil
+}
+
+// getRecordPagesCount returns the number of pages of records matching the
+// parameters, from get-records-pages-count.json.
+func (c *Client) getRecordPagesCount(ctx context.Context, params map[string]string) (int, error) {
+	var count int
+	if err := c.performGetJSONRequest(ctx, apiBaseUrl.JoinPath("get-records-pages-count.json"), params, &count); err != nil {
+		return 0, fmt.Errorf("failed to get the number of pages of records: %w", err)
+	}
+
+	return count, nil
+}
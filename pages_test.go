@@ -0,0 +1,109 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecordPages(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxPages int
+	)
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("rows-per-page") != "10" || query.Get("type") != "TXT" {
+			t.Errorf("Unexpected query %v", query)
+		}
+
+		switch r.URL.Path {
+		case "/dns/get-records-pages-count.json":
+			fmt.Fprint(w, `3`)
+		case "/dns/records.json":
+			mu.Lock()
+			inFlight++
+			maxPages = max(maxPages, inFlight)
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			// The second page repeats a record of the first one, as if a
+			// record was deleted meanwhile
+			switch query.Get("page") {
+			case "1":
+				fmt.Fprint(w, `{"1": {"id": "1", "type": "TXT", "host": "a", "record": "a", "ttl": "60", "status": 1},
+					"2": {"id": "2", "type": "TXT", "host": "b", "record": "b", "ttl": "60", "status": 1}}`)
+			case "2":
+				fmt.Fprint(w, `{"2": {"id": "2", "type": "TXT", "host": "b", "record": "b", "ttl": "60", "status": 1},
+					"3": {"id": "3", "type": "TXT", "host": "c", "record": "c", "ttl": "60", "status": 1}}`)
+			case "3":
+				fmt.Fprint(w, `{"4": {"id": "4", "type": "TXT", "host": "d", "record": "d", "ttl": "60", "status": 1}}`)
+			default:
+				t.Errorf("Unexpected page %q", query.Get("page"))
+			}
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	c := UseClient("id", "", "password")
+	c.RecordsPerPage = 10
+	c.PageConcurrency = 2
+	records, err := c.GetFilteredClouDNSRecords(t.Context(), "example.com", RecordFilter{Type: "TXT"})
+	if err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.Id)
+	}
+	slices.Sort(ids)
+	if strings.Join(ids, ",") != "1,2,3,4" {
+		t.Errorf("Expected the 4 records of the pages, got %v", ids)
+	}
+	if maxPages > 2 {
+		t.Errorf("Expected at most 2 pages to be fetched at the same time, got %d", maxPages)
+	}
+}
+
+func TestRecordPagesFailure(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/dns/get-records-pages-count.json":
+			fmt.Fprint(w, `2`)
+		case r.URL.Query().Get("page") == "2":
+			fmt.Fprint(w, `{"status":"Failed","statusDescription":"Something went wrong."}`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+
+	c := UseClient("id", "", "password")
+	c.RecordsPerPage = 10
+	c.PageConcurrency = 2
+	if _, err := c.GetClouDNSRecords(t.Context(), "example.com"); err == nil || !strings.Contains(err.Error(), "page 2") {
+		t.Errorf("Expected the failure of page 2, got %v", err)
+	}
+}
+
+func TestRecordPagesSize(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request for an unsupported page size, got %q", r.URL.Path)
+	})
+
+	// Providers decoded from JSON are not validated before their first use
+	provider := &Provider{AuthId: "id", AuthPassword: "password", RecordsPerPage: 25}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), "got 25") {
+		t.Errorf("Expected ErrInvalidConfig for the page size, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package prometheus exposes the requests a cloudns.Provider sends to the
+// ClouDNS API as Prometheus metrics:
+//
+//   - cloudns_api_requests_total, a counter labeled by endpoint, zone and code
+//   - cloudns_api_request_duration_seconds, a histogram labeled by endpoint and zone
+//   - cloudns_retries_total, a counter labeled by endpoint and zone
+//   - cloudns_rate_limited_total, a counter labeled by endpoint and zone
+//
+// The metrics are served in the Prometheus text exposition format, so that
+// the package does not depend on the Prometheus client library:
+//
+//	metrics := prometheus.New()
+//	provider, err := cloudns.NewProvider(cloudns.WithCredentials(id, "", password), cloudns.WithMetrics(metrics))
+//	http.Handle("/metrics", metrics)
+package prometheus
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libdns/cloudns"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of the
+// request duration histogram.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// labels identify a series of a metric.
+type labels struct {
+	endpoint string
+	zone     string
+	code     int
+}
+
+// histogram counts observations into cumulative buckets.
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Metrics collects the measurements of a provider and serves them to
+// Prometheus. It implements cloudns.Metrics and http.Handler, and is safe for
+// concurrent use.
+type Metrics struct {
+	buckets []float64
+
+	mu          sync.Mutex
+	requests    map[labels]uint64
+	durations   map[labels]*histogram
+	retries     map[labels]uint64
+	rateLimited map[labels]uint64
+}
+
+// New returns Metrics with the request duration histogram using the given
+// bucket upper bounds in seconds, or DefaultBuckets if none are given.
+func New(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Metrics{
+		buckets:     buckets,
+		requests:    make(map[labels]uint64),
+		durations:   make(map[labels]*histogram),
+		retries:     make(map[labels]uint64),
+		rateLimited: make(map[labels]uint64),
+	}
+}
+
+// ObserveRequest implements cloudns.Metrics.
+func (m *Metrics) ObserveRequest(endpoint, zone string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[labels{endpoint: endpoint, zone: zone, code: status}]++
+
+	key := labels{endpoint: endpoint, zone: zone}
+	h, ok := m.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[key] = h
+	}
+
+	seconds := duration.Seconds()
+	for idx, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[idx]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// ObserveRetry implements cloudns.Metrics.
+func (m *Metrics) ObserveRetry(endpoint, zone string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries[labels{endpoint: endpoint, zone: zone}]++
+}
+
+// ObserveRateLimited implements cloudns.Metrics.
+func (m *Metrics) ObserveRateLimited(endpoint, zone string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rateLimited[labels{endpoint: endpoint, zone: zone}]++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format to w.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	writeHeader(&b, "cloudns_api_requests_total", "counter", "Requests sent to the ClouDNS API.")
+	for _, key := range sortedLabels(m.requests) {
+		fmt.Fprintf(&b, "cloudns_api_requests_total{%s,code=\"%d\"} %d\n", key, key.code, m.requests[key])
+	}
+
+	writeHeader(&b, "cloudns_api_request_duration_seconds", "histogram", "Duration of the requests sent to the ClouDNS API.")
+	for _, key := range sortedLabels(m.durations) {
+		h := m.durations[key]
+		for idx, bound := range m.buckets {
+			fmt.Fprintf(&b, "cloudns_api_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", key, formatFloat(bound), h.counts[idx])
+		}
+		fmt.Fprintf(&b, "cloudns_api_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", key, h.count)
+		fmt.Fprintf(&b, "cloudns_api_request_duration_seconds_sum{%s} %s\n", key, formatFloat(h.sum))
+		fmt.Fprintf(&b, "cloudns_api_request_duration_seconds_count{%s} %d\n", key, h.count)
+	}
+
+	writeHeader(&b, "cloudns_retries_total", "counter", "Operations retried after a failed request.")
+	for _, key := range sortedLabels(m.retries) {
+		fmt.Fprintf(&b, "cloudns_retries_total{%s} %d\n", key, m.retries[key])
+	}
+
+	writeHeader(&b, "cloudns_rate_limited_total", "counter", "Requests rejected by the rate limit of the ClouDNS API.")
+	for _, key := range sortedLabels(m.rateLimited) {
+		fmt.Fprintf(&b, "cloudns_rate_limited_total{%s} %d\n", key, m.rateLimited[key])
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// String formats the endpoint and zone labels.
+func (l labels) String() string {
+	return fmt.Sprintf("endpoint=%s,zone=%s", quoteLabel(l.endpoint), quoteLabel(l.zone))
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric.
+func writeHeader(b *strings.Builder, name, type_, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, type_)
+}
+
+// sortedLabels returns the series of a metric in a stable order.
+func sortedLabels[V any](series map[labels]V) []labels {
+	return slices.SortedFunc(maps.Keys(series), func(a, b labels) int {
+		return cmp.Or(strings.Compare(a.endpoint, b.endpoint), strings.Compare(a.zone, b.zone), cmp.Compare(a.code, b.code))
+	})
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes and newlines.
+func quoteLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// formatFloat formats a sample value.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Interface guards
+var (
+	_ cloudns.Metrics = (*Metrics)(nil)
+	_ http.Handler    = (*Metrics)(nil)
+)
@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := New(0.1, 1)
+	metrics.ObserveRequest("/dns/records.json", "example.com", 200, 50*time.Millisecond)
+	metrics.ObserveRequest("/dns/records.json", "example.com", 429, 2*time.Second)
+	metrics.ObserveRetry("/dns/records.json", "example.com")
+	metrics.ObserveRateLimited("/dns/records.json", "example.com")
+	metrics.ObserveRequest("/dns/list-zones.json", `ex"ample`, 0, 0)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", contentType)
+	}
+
+	body := recorder.Body.String()
+	for _, line := range []string{
+		`# TYPE cloudns_api_requests_total counter`,
+		`cloudns_api_requests_total{endpoint="/dns/list-zones.json",zone="ex\"ample",code="0"} 1`,
+		`cloudns_api_requests_total{endpoint="/dns/records.json",zone="example.com",code="200"} 1`,
+		`cloudns_api_requests_total{endpoint="/dns/records.json",zone="example.com",code="429"} 1`,
+		`# TYPE cloudns_api_request_duration_seconds histogram`,
+		`cloudns_api_request_duration_seconds_bucket{endpoint="/dns/records.json",zone="example.com",le="0.1"} 1`,
+		`cloudns_api_request_duration_seconds_bucket{endpoint="/dns/records.json",zone="example.com",le="1"} 1`,
+		`cloudns_api_request_duration_seconds_bucket{endpoint="/dns/records.json",zone="example.com",le="+Inf"} 2`,
+		`cloudns_api_request_duration_seconds_sum{endpoint="/dns/records.json",zone="example.com"} 2.05`,
+		`cloudns_api_request_duration_seconds_count{endpoint="/dns/records.json",zone="example.com"} 2`,
+		`cloudns_retries_total{endpoint="/dns/records.json",zone="example.com"} 1`,
+		`cloudns_rate_limited_total{endpoint="/dns/records.json",zone="example.com"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, body)
+		}
+	}
+}
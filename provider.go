@@ -2,9 +2,16 @@ package cloudns
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/libdns/libdns"
@@ -22,95 +29,532 @@ const (
 
 	// DefaultMaxBackoff is the default maximum backoff duration for retries
 	DefaultMaxBackoff = 30 * time.Second
+
+	// DefaultRateLimitCooldown is the default wait before retrying a request
+	// rejected by the ClouDNS rate limit
+	DefaultRateLimitCooldown = time.Minute
+
+	// DefaultZoneLockedPause is the default wait before retrying a request
+	// rejected because the zone is locked or updating
+	DefaultZoneLockedPause = 5 * time.Second
 )
 
 // Provider facilitates DNS record manipulation with ClouDNS.
+//
+// A Provider is safe for concurrent use by multiple goroutines once
+// configured. Operations changing the records of a zone are serialized per
+// zone, so that concurrent calls to AppendRecords, SetRecords and
+// DeleteRecords do not act on outdated views of the zone.
 type Provider struct {
-	AuthId           string        `json:"auth_id,omitempty"`
-	SubAuthId        string        `json:"sub_auth_id,omitempty"`
-	AuthPassword     string        `json:"auth_password"`
+	AuthId       string `json:"auth_id,omitempty"`
+	SubAuthId    string `json:"sub_auth_id,omitempty"`
+	AuthPassword string `json:"auth_password"`
+
+	// AuthPasswordFile is the path of a file holding the password, used
+	// instead of AuthPassword. The file is read again whenever it changes,
+	// e.g. when a mounted Kubernetes secret is rotated, so that the new
+	// password is picked up without a restart.
+	AuthPasswordFile string `json:"auth_password_file,omitempty"`
+
 	OperationRetries int           `json:"operation_retries,omitempty"`
 	InitialBackoff   time.Duration `json:"initial_backoff,omitempty"`
 	MaxBackoff       time.Duration `json:"max_backoff,omitempty"`
+
+	// RateLimitCooldown is waited before retrying a request that was
+	// rejected by the ClouDNS rate limit, instead of the usual backoff.
+	// DefaultRateLimitCooldown if zero.
+	RateLimitCooldown time.Duration `json:"rate_limit_cooldown,omitempty"`
+
+	// ZoneLockedPause is waited before retrying a request that was rejected
+	// because the zone is locked or updating, instead of the usual backoff,
+	// to let ClouDNS finish applying the previous changes.
+	// DefaultZoneLockedPause if zero.
+	ZoneLockedPause time.Duration `json:"zone_locked_pause,omitempty"`
+
+	// MutationDelay is waited between the changes AppendRecords, SetRecords
+	// and DeleteRecords make to a zone, so that large batches do not run
+	// into the zone being locked while ClouDNS applies the previous changes.
+	// No delay if zero.
+	MutationDelay time.Duration `json:"mutation_delay,omitempty"`
+
+	// Concurrency is the number of changes SetRecords makes at the same
+	// time, and of pages of records fetched at the same time with
+	// RecordsPerPage. Only changes to different names are made concurrently,
+	// those to the same name are still made one after the other, deletions
+	// first. One if zero.
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// RecordsPerPage makes the provider fetch the records of a zone in
+	// pages of the given size, as huge zones require. ClouDNS accepts 10,
+	// 20, 30, 50 and 100. All records are fetched with a single request if
+	// zero.
+	RecordsPerPage int `json:"records_per_page,omitempty"`
+
+	// OperationTimeout bounds GetRecords, AppendRecords, SetRecords,
+	// DeleteRecords and ListZones, including their retries, when the
+	// context passed by the caller has no deadline. This keeps callers that
+	// pass context.Background, like long-running certificate maintenance
+	// loops, from hanging on an unresponsive API. Unbounded if zero.
+	OperationTimeout time.Duration `json:"operation_timeout,omitempty"`
+
+	// RetryBudget caps the number of retries made across all the requests
+	// of a single call to AppendRecords, SetRecords or DeleteRecords, so
+	// that a large batch fails fast during an outage instead of retrying
+	// every request OperationRetries times. Unlimited if zero.
+	RetryBudget int `json:"retry_budget,omitempty"`
+
+	// RetryableStatusDescriptions are fragments of the status descriptions
+	// of the failures reported by ClouDNS that are retried, matched without
+	// regard to case. Other failures reported by the API are permanent. If
+	// empty, DefaultRetryableStatusDescriptions are used.
+	RetryableStatusDescriptions []string `json:"retryable_status_descriptions,omitempty"`
+
+	// FallbackAuthId, FallbackSubAuthId and FallbackAuthPassword are a
+	// second credential pair, used automatically for requests the API
+	// rejects with an authentication error. This smooths over the window
+	// in which a rotated password has not reached all instances yet.
+	FallbackAuthId       string `json:"fallback_auth_id,omitempty"`
+	FallbackSubAuthId    string `json:"fallback_sub_auth_id,omitempty"`
+	FallbackAuthPassword string `json:"fallback_auth_password,omitempty"`
+
+	// SkipInactive makes GetRecords leave out records that are disabled
+	// on ClouDNS. By default they are returned alongside active records,
+	// and can be told apart through their RecordData.
+	SkipInactive bool `json:"skip_inactive,omitempty"`
+
+	// RegisterMissingZones makes AppendRecords and SetRecords register the
+	// zone as a new master zone if it does not exist in the account yet,
+	// instead of failing with ErrZoneNotFound.
+	RegisterMissingZones bool `json:"register_missing_zones,omitempty"`
+
+	// SPFAsTXT makes GetRecords return legacy SPF records as TXT records,
+	// and SetRecords match desired TXT records against existing SPF ones,
+	// so that both copies of a policy are handled as one record set. By
+	// default SPF records are passed through as libdns.RR of type SPF.
+	SPFAsTXT bool `json:"spf_as_txt,omitempty"`
+
+	// ReadOnly makes AppendRecords, SetRecords and DeleteRecords fail with
+	// ErrReadOnly, and the underlying Client reject any change, so that
+	// staging environments and audit tools can share credentials without
+	// risking writes.
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// StrictDecoding makes the operations fail with ErrUnknownField when a
+	// response of the API has fields this package does not know, which are
+	// also logged as warnings, so that changes of the API are noticed early.
+	// By default unknown fields are ignored.
+	StrictDecoding bool `json:"strict_decoding,omitempty"`
+
+	// DryRun makes AppendRecords, SetRecords and DeleteRecords log the
+	// changes they would make at info level, and return the records as if
+	// the changes were made, without changing the zone. The zone is still
+	// read, to compute the changes. No hooks are called in dry runs.
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// AllowedZones restricts the provider to the listed zones, if not empty.
+	// Operations on any other zone fail with ErrZoneNotAllowed before a
+	// request is sent, and ListZones leaves them out. This protects shared
+	// credentials from mistyped zone names.
+	AllowedZones []string `json:"allowed_zones,omitempty"`
+
+	// MarshalSecrets makes MarshalJSON include the passwords in plain text,
+	// e.g. to persist the configuration. By default they are redacted, so
+	// that the provider can be dumped for debugging without leaking them.
+	MarshalSecrets bool `json:"-"`
+
+	// OnRecordAdded, OnRecordModified and OnRecordDeleted are called after
+	// each record AppendRecords, SetRecords, SyncZone or DeleteRecords
+	// added, modified or deleted, and OnZoneSynced after SetRecords or
+	// SyncZone brought all the given RRsets of a zone up to date. Enabling
+	// or disabling a record counts as a modification. The zone is passed
+	// without trailing dot. The hooks are called synchronously, while the
+	// zone is locked, and must not call back into the provider for the same
+	// zone. With a Concurrency above one, SetRecords and SyncZone may call
+	// them concurrently.
+	OnRecordAdded    func(ctx context.Context, zone string, record libdns.Record)        `json:"-"`
+	OnRecordModified func(ctx context.Context, zone string, before, after libdns.Record) `json:"-"`
+	OnRecordDeleted  func(ctx context.Context, zone string, record libdns.Record)        `json:"-"`
+	OnZoneSynced     func(ctx context.Context, zone string, records []libdns.Record)     `json:"-"`
+
+	// credentialsMu guards the credentials against concurrent rotation
+	// through SetCredentials.
+	credentialsMu sync.RWMutex
+
+	// zoneLocks holds a *sync.Mutex per zone, serializing the operations
+	// changing its records
+	zoneLocks sync.Map
+
+	// closed is set once Close was called
+	closed atomic.Bool
+
+	// passwordFile caches the contents of AuthPasswordFile
+	passwordFile secretFile
+
+	// clientOnce guards the creation of the Client shared by the operations
+	// of the provider, see client
+	clientOnce sync.Once
+	shared     atomic.Pointer[sharedClient]
+
+	// defaultCacheOnce guards the creation of the zone cache of providers
+	// created without WithCache, see zoneCache
+	defaultCacheOnce sync.Once
+
+	// Settings applied through the ProviderOptions of NewProvider
+	logger     *slog.Logger
+	httpClient *http.Client
+	limiter    *rateLimiter
+	cache      *zoneCache
+	after      func(time.Duration) <-chan time.Time
+	api        API
+	metrics    Metrics
+	auditSink  AuditSink
+	trace      bool
+}
+
+// redactedSecret replaces the passwords of a Provider marshaled to JSON.
+const redactedSecret = "REDACTED"
+
+// MarshalJSON encodes the provider with its passwords redacted, unless
+// MarshalSecrets is set. Unmarshaling is not affected, so configurations
+// holding the actual passwords are decoded as usual.
+func (p *Provider) MarshalJSON() ([]byte, error) {
+	p.credentialsMu.RLock()
+	defer p.credentialsMu.RUnlock()
+
+	// The fields of the outer struct take precedence over the embedded ones
+	type plain Provider
+	redacted := struct {
+		*plain
+		AuthPassword         string `json:"auth_password"`
+		FallbackAuthPassword string `json:"fallback_auth_password,omitempty"`
+	}{
+		plain:                (*plain)(p),
+		AuthPassword:         redactSecret(p.AuthPassword, p.MarshalSecrets),
+		FallbackAuthPassword: redactSecret(p.FallbackAuthPassword, p.MarshalSecrets),
+	}
+
+	return json.Marshal(redacted)
+}
+
+func redactSecret(secret string, reveal bool) string {
+	if secret == "" || reveal {
+		return secret
+	}
+
+	return redactedSecret
+}
+
+// SetCredentials replaces the credentials of the provider, e.g. after the
+// password was rotated, without restarting the application embedding it. It
+// is safe to call concurrently with other methods. Operations already in
+// progress finish with the previous credentials.
+//
+// Parameters:
+//   - authId: The ClouDNS authentication ID, empty if subAuthId is used
+//   - subAuthId: The ClouDNS sub-user authentication ID, empty if authId is used
+//   - authPassword: The ClouDNS authentication password
+func (p *Provider) SetCredentials(authId, subAuthId, authPassword string) {
+	p.credentialsMu.Lock()
+	defer p.credentialsMu.Unlock()
+
+	p.AuthId = authId
+	p.SubAuthId = subAuthId
+	p.AuthPassword = authPassword
+}
+
+// clientSettings are the credentials and settings of the provider a Client
+// is built with.
+type clientSettings struct {
+	authId, subAuthId, authPassword                         string
+	fallbackAuthId, fallbackSubAuthId, fallbackAuthPassword string
+	readOnly, strictDecoding                                bool
+	recordsPerPage, pageConcurrency                         int
+}
+
+// sharedClient is the Client shared by the operations of a provider, along
+// with the settings it was built with.
+type sharedClient struct {
+	settings clientSettings
+	client   *Client
+}
+
+// client returns a Client using the credentials and options of the provider,
+// or the API set with WithAPI. The password is read from AuthPasswordFile, if
+// set. The Client is built once and reused by all operations, until the
+// credentials are rotated or the settings changed.
+func (p *Provider) client() (API, error) {
+	if p.closed.Load() {
+		return nil, ErrProviderClosed
+	}
+	if p.api != nil {
+		return p.dryRun(p.api), nil
+	}
+
+	p.credentialsMu.RLock()
+	settings := clientSettings{
+		authId:               p.AuthId,
+		subAuthId:            p.SubAuthId,
+		authPassword:         p.AuthPassword,
+		fallbackAuthId:       p.FallbackAuthId,
+		fallbackSubAuthId:    p.FallbackSubAuthId,
+		fallbackAuthPassword: p.FallbackAuthPassword,
+		readOnly:             p.ReadOnly,
+		strictDecoding:       p.StrictDecoding,
+		recordsPerPage:       p.RecordsPerPage,
+		pageConcurrency:      p.Concurrency,
+	}
+	passwordFile := p.AuthPasswordFile
+	p.credentialsMu.RUnlock()
+
+	if passwordFile != "" {
+		password, err := p.passwordFile.read(passwordFile)
+		if err != nil {
+			return nil, err
+		}
+		settings.authPassword = password
+	}
+
+	p.clientOnce.Do(func() {
+		p.shared.Store(p.newSharedClient(settings))
+	})
+
+	// Operations already in progress keep the Client they started with
+	shared := p.shared.Load()
+	if shared.settings != settings {
+		shared = p.newSharedClient(settings)
+		p.shared.Store(shared)
+	}
+
+	return p.dryRun(shared.client), nil
+}
+
+// newSharedClient builds the Client for the operations of the provider.
+func (p *Provider) newSharedClient(settings clientSettings) *sharedClient {
+	c := UseClient(settings.authId, settings.subAuthId, settings.authPassword)
+	c.FallbackAuthId = settings.fallbackAuthId
+	c.FallbackSubAuthId = settings.fallbackSubAuthId
+	c.FallbackAuthPassword = settings.fallbackAuthPassword
+	c.ReadOnly = settings.readOnly
+	c.StrictDecoding = settings.strictDecoding
+	c.RecordsPerPage = settings.recordsPerPage
+	c.PageConcurrency = settings.pageConcurrency
+	c.Logger = p.logger
+	c.Metrics = p.metrics
+	c.TraceRequests = p.trace
+	c.HTTPClient = p.httpClient
+	c.limiter = p.limiter
+	c.cache = p.zoneCache()
+
+	return &sharedClient{settings: settings, client: c}
+}
+
+// zoneCache returns the cache of the accepted TTLs and record types shared by
+// all operations of the provider, creating it on first use unless one was set
+// with WithCache.
+func (p *Provider) zoneCache() *zoneCache {
+	p.defaultCacheOnce.Do(func() {
+		if p.cache == nil {
+			p.cache = &zoneCache{}
+		}
+	})
+
+	return p.cache
+}
+
+// lockZone locks the zone for an operation changing its records, and returns
+// the function unlocking it.
+func (p *Provider) lockZone(zone string) func() {
+	mu, _ := p.zoneLocks.LoadOrStore(strings.ToLower(zone), &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	return mu.(*sync.Mutex).Unlock
+}
+
+// checkZone returns an error wrapping ErrZoneNotAllowed if the normalized zone
+// is not one of the AllowedZones of the provider.
+func (p *Provider) checkZone(zone string) error {
+	if len(p.AllowedZones) == 0 || slices.ContainsFunc(p.AllowedZones, func(allowed string) bool {
+		return strings.EqualFold(normalizeZone(allowed), zone)
+	}) {
+		return nil
+	}
+
+	return fmt.Errorf("zone %q: %w", zone, ErrZoneNotAllowed)
 }
 
 // GetRecords lists all the records in the zone.
 func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
-	zone = strings.TrimSuffix(zone, ".")
+	ctx, cancel := p.withOperationTimeout(ctx)
+	defer cancel()
+	ctx = withOperation(ctx)
+
+	zone = normalizeZone(zone)
+	if err := p.checkZone(zone); err != nil {
+		return nil, err
+	}
 
 	// Use retry mechanism for the GetRecords operation
-	var records []libdns.Record
-	err := RetryWithBackoff(ctx, func() error {
-		var e error
+	var upstreamRecords []ApiDnsRecord
+	err := p.retry(ctx, func() error {
+		c, e := p.client()
+		if e != nil {
+			return e
+		}
 
-		records, e = UseClient(p.AuthId, p.SubAuthId, p.AuthPassword).GetRecords(ctx, zone)
+		upstreamRecords, e = c.GetClouDNSRecords(ctx, zone)
 		return e
-	}, p.getOperationRetries(), p.getInitialBackoff(), p.getMaxBackoff())
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get records after retries: %w", err)
 	}
 
-	return records, nil
+	if p.SkipInactive {
+		upstreamRecords = slices.DeleteFunc(upstreamRecords, func(r ApiDnsRecord) bool {
+			return !r.Active()
+		})
+	}
+	if p.SPFAsTXT {
+		upstreamRecords = spfAsTXT(upstreamRecords)
+	}
+
+	return recordsToLibdns(upstreamRecords)
 }
 
 // AppendRecords adds records to the zone. It returns the records that were added.
+// Invalid records are rejected before any change is made, with an error wrapping ErrInvalidRecord.
+// Records that are passed several times, or that only differ in a TTL rounding
+// to the same value, are added once.
 func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	zone = strings.TrimSuffix(zone, ".")
+	ctx, cancel := p.withOperationTimeout(ctx)
+	defer cancel()
+	ctx = withOperation(ctx)
+
+	if p.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	zone = normalizeZone(zone)
+	if err := p.checkZone(zone); err != nil {
+		return nil, err
+	}
+
+	if err := validateRecords(records); err != nil {
+		return nil, err
+	}
+
+	defer p.lockZone(zone)()
+	ctx = p.withRetryBudget(ctx)
+
+	c, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Looking up the accepted TTLs also checks that the zone exists, so a
+	// missing zone is reported before any record is added
+	ttls, err := availableTTLs(ctx, c, zone)
+	if errors.Is(err, ErrZoneNotFound) && p.RegisterMissingZones {
+		if err = p.registerZone(ctx, c, zone); err == nil {
+			ttls, err = availableTTLs(ctx, c, zone)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRecordTypes(ctx, c, zone, records); err != nil {
+		return nil, err
+	}
+	records = dedupeRecords(records, ttls)
 
 	createdRecords := make([]libdns.Record, 0, cap(records))
 	for _, record := range records {
+		p.paceMutation(ctx, len(createdRecords))
+		if ctx.Err() != nil {
+			return createdRecords, canceledError(ctx, len(createdRecords))
+		}
+
 		// Use retry mechanism for the AddRecord operation
-		var r libdns.Record
-		err := RetryWithBackoff(ctx, func() error {
+		var added ApiDnsRecord
+		err := p.retry(ctx, func() error {
 			var err error
-			r, err = UseClient(p.AuthId, p.SubAuthId, p.AuthPassword).AddRecord(ctx, zone, fromLibdnsRecord(record, ""))
+			added, err = c.AddClouDNSRecord(ctx, zone, fromLibdnsRecord(record, "", ttls))
 
 			return err
-		}, p.getOperationRetries(), p.getInitialBackoff(), p.getMaxBackoff())
+		})
+		if err != nil {
+			p.audit(ctx, zone, AuditEntry{Kind: OperationAdd, Err: err}, record)
+		}
+		if err != nil && ctx.Err() != nil {
+			return createdRecords, canceledError(ctx, len(createdRecords))
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to add record %q: %w", record.RR().Name, err)
 		}
 
+		r, err := added.toLibdnsRecord()
+		if err != nil {
+			return nil, err
+		}
 		createdRecords = append(createdRecords, r)
+		p.audit(ctx, zone, AuditEntry{Kind: OperationAdd, After: r}, record)
+		if p.OnRecordAdded != nil && !p.DryRun {
+			p.OnRecordAdded(ctx, zone, r)
+		}
 	}
 
 	return createdRecords, nil
 }
 
-func (p *Provider) processOperation(ctx context.Context, c *Client, zone string, oplist operationEntry) (libdns.Record, error) {
+func (p *Provider) processOperation(ctx context.Context, c API, zone string, oplist operationEntry) (libdns.Record, error) {
 	var (
-		r   libdns.Record
+		rec = oplist.record
 		err error
 	)
 
 	switch oplist.op {
+	case nop:
+		// Nothing to change besides the status
 	case addRecord:
-		err = RetryWithBackoff(ctx, func() error {
+		err = p.retry(ctx, func() error {
 			var e error
-			r, e = c.AddRecord(ctx, zone, oplist.record)
+			rec, e = c.AddClouDNSRecord(ctx, zone, oplist.record)
 
 			return e
-		}, p.getOperationRetries(), p.getInitialBackoff(), p.getMaxBackoff())
+		})
 
 	case modifyRecord:
-		err = RetryWithBackoff(ctx, func() error {
-			var e error
-			r, e = c.UpdateRecord(ctx, zone, oplist.record)
+		err = p.retry(ctx, func() error {
+			_, e := c.UpdateRecord(ctx, zone, oplist.record)
 
 			return e
-		}, p.getOperationRetries(), p.getInitialBackoff(), p.getMaxBackoff())
+		})
 	case deleteRecord:
-		err = RetryWithBackoff(ctx, func() error {
-			var e error
-			r, e = nil, c.DeleteRecord(ctx, zone, oplist.record.Id)
-
-			return e
-		}, p.getOperationRetries(), p.getInitialBackoff(), p.getMaxBackoff())
+		err = p.retry(ctx, func() error {
+			return c.DeleteRecord(ctx, zone, oplist.record.Id)
+		})
+		return nil, err
 	default:
 		return nil, fmt.Errorf("unknown operation: %v", oplist.op)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if oplist.status != nil {
+		err = p.retry(ctx, func() error {
+			return c.ChangeRecordStatus(ctx, zone, rec.Id, *oplist.status)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to change status of record %q: %w", rec.Host, err)
+		}
 
-	return r, err
+		rec.Status = 0
+		if *oplist.status {
+			rec.Status = 1
+		}
+	}
+
+	return rec.toLibdnsRecord()
 }
 
 // SetRecords sets the records in the zone, either by updating existing records or creating new ones.
@@ -118,38 +562,172 @@ func (p *Provider) processOperation(ctx context.Context, c *Client, zone string,
 // in an inconsistent state upon error. No rollback is attempted.
 //
 // All updates are attempted, even if an error is encountered. All successfully
-// updated records are returned.
+// updated records are returned. Invalid records are rejected before any change
+// is made, with an error wrapping ErrInvalidRecord. Duplicate records are only
+// set once, the first occurrence deciding on the record status.
+//
+// Records carrying a RecordData in their ProviderData field are enabled or
+// disabled on ClouDNS to match RecordData.Active. The status of records
+// without it is left as is.
 func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	zone = strings.TrimSuffix(zone, ".")
+	ret, _, err := p.SetRecordsWithAudit(ctx, zone, records)
+	return ret, err
+}
+
+// SetRecordsWithAudit works like SetRecords, and additionally returns the
+// list of operations that were executed on the zone, in order, with the
+// state of the affected records before and after each of them.
+func (p *Provider) SetRecordsWithAudit(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, []AuditEntry, error) {
+	ctx, cancel := p.withOperationTimeout(ctx)
+	defer cancel()
+	ctx = withOperation(ctx)
+
+	if p.ReadOnly {
+		return nil, nil, ErrReadOnly
+	}
+
+	zone = normalizeZone(zone)
+	if err := p.checkZone(zone); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateRecords(records); err != nil {
+		return nil, nil, err
+	}
+
+	defer p.lockZone(zone)()
+	ctx = p.withRetryBudget(ctx)
 
-	c := UseClient(p.AuthId, p.SubAuthId, p.AuthPassword)
-	upstreamRecords, err := c.GetClouDNSRecords(ctx, zone)
+	c, err := p.client()
 	if err != nil {
-		return nil, fmt.Errorf("Could not get records for zone %q: %w", zone, err)
+		return nil, nil, err
+	}
+	// Only the RRsets to set are loaded, with the legacy SPF records that
+	// are handled as TXT records
+	filters := rrsetFilters(records)
+	if p.SPFAsTXT {
+		for _, filter := range filters {
+			if canonicalRecordType(filter.Type) == "TXT" {
+				filters = append(filters, RecordFilter{Host: filter.Host, Type: "SPF"})
+			}
+		}
+	}
+	upstreamRecords, err := loadRRsets(ctx, c, zone, filters)
+	if errors.Is(err, ErrZoneNotFound) && p.RegisterMissingZones {
+		err = p.registerZone(ctx, c, zone)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not get records for zone %q: %w", zone, err)
 	}
 
-	ret := make([]libdns.Record, 0, cap(records))
-	var retErr error
+	ttls, err := availableTTLs(ctx, c, zone)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkRecordTypes(ctx, c, zone, records); err != nil {
+		return nil, nil, err
+	}
 	existing := clouDNSRecordsToMap(upstreamRecords)
-	rrsets := libdnsRecordsToMap(records)
-	oplist := makeOperationList(rrsets, existing)
+	if p.SPFAsTXT {
+		mergeSPFIntoTXT(existing)
+	}
+	rrsets := libdnsRecordsToMap(dedupeRecords(records, ttls))
+	oplist := makeOperationList(rrsets, existing, ttls)
 
-	for _, op := range oplist {
-		rec, err := p.processOperation(ctx, c, zone, op)
-		retErr = errors.Join(retErr, err)
-		if rec != nil {
-			ret = append(ret, rec)
-		}
+	ret, audit, err := p.applyOperations(ctx, c, zone, oplist, upstreamRecords)
+	if err == nil && p.OnZoneSynced != nil && !p.DryRun {
+		p.OnZoneSynced(ctx, zone, ret)
 	}
 
-	return ret, retErr
+	return ret, audit, err
+}
+
+// applyOperations makes the changes of the operation list to the zone, with
+// up to Concurrency of them at the same time, and returns the records that
+// were set along with an audit entry per change. The upstream records are
+// the state of the zone before, to describe the changes. All changes are
+// attempted, even if an error is encountered.
+func (p *Provider) applyOperations(ctx context.Context, c API, zone string, oplist []operationEntry, upstreamRecords []ApiDnsRecord) ([]libdns.Record, []AuditEntry, error) {
+	ret := make([]libdns.Record, 0, len(oplist))
+	var retErr error
+	existingById := make(map[string]ApiDnsRecord, len(upstreamRecords))
+	for _, rec := range upstreamRecords {
+		existingById[rec.Id] = rec
+	}
+	audit := make([]AuditEntry, 0, len(oplist))
+
+	// The changes to different names are independent, so they are made
+	// concurrently, while the ones to the same name keep their order
+	groups := [][]operationEntry{oplist}
+	limit := max(p.Concurrency, 1)
+	if limit > 1 {
+		groups = groupOperations(oplist)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		applied  int
+		canceled bool
+		started  atomic.Int64
+	)
+	sem := make(chan struct{}, limit)
+	for _, group := range groups {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			for _, op := range group {
+				p.paceMutation(ctx, int(started.Add(1)-1))
+				if ctx.Err() != nil {
+					mu.Lock()
+					canceled = true
+					mu.Unlock()
+					return
+				}
+
+				rec, err := p.processOperation(ctx, c, zone, op)
+				entry := newAuditEntry(op, existingById, rec, err)
+				if p.auditSink != nil {
+					attempted, _ := op.record.toLibdnsRecord()
+					p.audit(ctx, zone, entry, attempted)
+				}
+
+				mu.Lock()
+				retErr = errors.Join(retErr, err)
+				if rec != nil {
+					ret = append(ret, rec)
+				}
+				audit = append(audit, entry)
+				if err == nil {
+					applied++
+				}
+				mu.Unlock()
+
+				if err == nil {
+					p.notifyChange(ctx, zone, entry)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if canceled {
+		return ret, audit, errors.Join(retErr, canceledError(ctx, applied))
+	}
+
+	return ret, audit, retErr
 }
 
 func matchDeleteTarget(target, matched libdns.Record) bool {
 	matchedRR := matched.RR()
 	targetRR := target.RR()
 
-	if targetRR.Type != "" && targetRR.Type != matchedRR.Type {
+	if targetRR.Type != "" && canonicalRecordType(targetRR.Type) != canonicalRecordType(matchedRR.Type) {
 		return false
 	}
 
@@ -164,12 +742,89 @@ func matchDeleteTarget(target, matched libdns.Record) bool {
 	return true
 }
 
+// maxTargetedLookups is the number of RRsets up to which the provider gets
+// the records of a zone with a filtered request per RRset, instead of getting
+// all the records of the zone at once.
+const maxTargetedLookups = 10
+
+// rrsetFilters returns a filter for each distinct name and type of the
+// records, in order.
+func rrsetFilters(records []libdns.Record) []RecordFilter {
+	var filters []RecordFilter
+	seen := make(map[nameAndType]bool, len(records))
+	for _, record := range records {
+		rr := record.RR()
+		key := newNameAndType(rr.Name, rr.Type)
+		if rr.Type == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		filters = append(filters, RecordFilter{Host: rr.Name, Type: rr.Type})
+	}
+
+	return filters
+}
+
+// loadRRsets returns the records of the zone in the RRsets selected by the
+// filters. Only these are requested from ClouDNS, unless there are more than
+// maxTargetedLookups of them.
+func loadRRsets(ctx context.Context, c API, zone string, filters []RecordFilter) ([]ApiDnsRecord, error) {
+	if len(filters) > maxTargetedLookups {
+		upstreamRecords, err := c.GetClouDNSRecords(ctx, zone)
+		if err != nil {
+			return nil, err
+		}
+
+		return slices.DeleteFunc(slices.Clone(upstreamRecords), func(record ApiDnsRecord) bool {
+			return !slices.ContainsFunc(filters, func(filter RecordFilter) bool {
+				return filter.matches(record)
+			})
+		}), nil
+	}
+
+	var upstreamRecords []ApiDnsRecord
+	ids := make(map[string]bool)
+	for _, filter := range filters {
+		filtered, err := c.GetFilteredClouDNSRecords(ctx, zone, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		// Filters for the whole zone may overlap with the others
+		for _, record := range filtered {
+			if !ids[record.Id] {
+				ids[record.Id] = true
+				upstreamRecords = append(upstreamRecords, record)
+			}
+		}
+	}
+
+	return upstreamRecords, nil
+}
+
 // DeleteRecords deletes the records from the zone. It returns the records that were deleted.
 func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	zone = strings.TrimSuffix(zone, ".")
+	ctx, cancel := p.withOperationTimeout(ctx)
+	defer cancel()
+	ctx = withOperation(ctx)
+
+	if p.ReadOnly {
+		return nil, ErrReadOnly
+	}
 
-	c := UseClient(p.AuthId, p.SubAuthId, p.AuthPassword)
-	upstreamRecords, err := c.GetClouDNSRecords(ctx, zone)
+	zone = normalizeZone(zone)
+	if err := p.checkZone(zone); err != nil {
+		return nil, err
+	}
+
+	defer p.lockZone(zone)()
+	ctx = p.withRetryBudget(ctx)
+
+	c, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+	upstreamRecords, err := loadRRsets(ctx, c, zone, rrsetFilters(records))
 	if err != nil {
 		return nil, fmt.Errorf("Could not get records for zone %q: %w", zone, err)
 	}
@@ -179,7 +834,7 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 	var deletedRecords []libdns.Record
 	for _, record := range records {
 		rr := record.RR()
-		matchingRecords := keyedRecords[nameAndType{name: rr.Name, type_: rr.Type}]
+		matchingRecords := keyedRecords[newNameAndType(rr.Name, rr.Type)]
 		for _, matchingRecord := range matchingRecords {
 			matchedLibdnsRecord, err := matchingRecord.toLibdnsRecord()
 			if err != nil {
@@ -190,21 +845,280 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 				continue
 			}
 
+			p.paceMutation(ctx, len(deletedRecords))
+			if ctx.Err() != nil {
+				return deletedRecords, canceledError(ctx, len(deletedRecords))
+			}
+
 			// Use retry mechanism for the DeleteRecord operation
-			err = RetryWithBackoff(ctx, func() error {
+			err = p.retry(ctx, func() error {
 				return c.DeleteRecord(ctx, zone, matchingRecord.Id)
-			}, p.getOperationRetries(), p.getInitialBackoff(), p.getMaxBackoff())
+			})
+			p.audit(ctx, zone, AuditEntry{Kind: OperationDelete, Before: matchedLibdnsRecord, Err: err}, nil)
+			if err != nil && ctx.Err() != nil {
+				return deletedRecords, canceledError(ctx, len(deletedRecords))
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to delete record %q: %w", matchingRecord.Host, err)
 			}
 
 			deletedRecords = append(deletedRecords, matchedLibdnsRecord)
+			if p.OnRecordDeleted != nil && !p.DryRun {
+				p.OnRecordDeleted(ctx, zone, matchedLibdnsRecord)
+			}
 		}
 	}
 
 	return deletedRecords, nil
 }
 
+// ListZones returns the zones of the account, as fully qualified names. Only
+// the AllowedZones are returned, if set.
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	ctx, cancel := p.withOperationTimeout(ctx)
+	defer cancel()
+	ctx = withOperation(ctx)
+
+	var zones []Zone
+	err := p.retry(ctx, func() error {
+		c, e := p.client()
+		if e != nil {
+			return e
+		}
+
+		zones, e = c.ListZones(ctx, ListZonesOptions{})
+
+		return e
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+
+	ret := make([]libdns.Zone, 0, len(zones))
+	for _, zone := range zones {
+		if p.checkZone(zone.Name) != nil {
+			continue
+		}
+
+		ret = append(ret, libdns.Zone{Name: toUnicode(zone.Name) + "."})
+	}
+
+	return ret, nil
+}
+
+// registerZone registers a missing zone as a new master zone.
+func (p *Provider) registerZone(ctx context.Context, c API, zone string) error {
+	err := p.retry(ctx, func() error {
+		return c.CreateZone(ctx, zone, CreateZoneOptions{Type: ZoneTypeMaster})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register zone %q: %w", zone, err)
+	}
+
+	return nil
+}
+
+// FindZone returns the zone of the account that encloses the given domain
+// name, e.g. "example.co.uk." for "_acme-challenge.www.example.co.uk.".
+// Internationalized names are converted to punycode, and so is the returned zone.
+// A zone that is not one of the AllowedZones is reported as ErrZoneNotAllowed.
+// See Client.FindZone for details.
+func (p *Provider) FindZone(ctx context.Context, fqdn string) (string, error) {
+	var zone string
+	err := p.retry(ctx, func() error {
+		c, e := p.client()
+		if e != nil {
+			return e
+		}
+
+		zone, e = c.FindZone(ctx, toASCII(fqdn))
+
+		return e
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.checkZone(normalizeZone(zone)); err != nil {
+		return "", err
+	}
+
+	return zone, nil
+}
+
+// WaitForPropagation blocks until all the ClouDNS nameservers serve the latest
+// version of the zone, e.g. so that an ACME challenge record can be validated.
+// The zone is polled with the provider's backoff settings until it is up to
+// date or the context expires. Transient errors are ignored while polling.
+func (p *Provider) WaitForPropagation(ctx context.Context, zone string) error {
+	zone = normalizeZone(zone)
+	if err := p.checkZone(zone); err != nil {
+		return err
+	}
+
+	c, err := p.client()
+	if err != nil {
+		return err
+	}
+	backoff := p.getInitialBackoff()
+	for {
+		updated, err := c.IsUpdated(ctx, zone)
+		if err != nil && !p.isRetryable(err) {
+			return fmt.Errorf("failed to check zone %q: %w", zone, err)
+		}
+		if updated {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("zone %q is not up to date on all nameservers: %w", zone, ctx.Err())
+		case <-p.getAfter()(backoff):
+			backoff = min(backoff*2, p.getMaxBackoff())
+		}
+	}
+}
+
+// Close releases the resources held by the provider: the idle connections of
+// the HTTP client set with WithHTTPClient, and the cached zone settings. The
+// connections of http.DefaultClient are shared with the rest of the process
+// and left open. Operations started afterwards fail with ErrProviderClosed.
+// Close is meant for applications reloading their configuration, and may be
+// called more than once.
+func (p *Provider) Close() error {
+	p.closed.Store(true)
+
+	if p.httpClient != nil {
+		p.httpClient.CloseIdleConnections()
+	}
+	p.zoneCache().clear()
+
+	return nil
+}
+
+// withOperationTimeout returns a context bounded by the OperationTimeout of
+// the provider, unless the given context already has a deadline.
+func (p *Provider) withOperationTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || p.OperationTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, p.OperationTimeout)
+}
+
+// paceMutation waits the MutationDelay of the provider before a change to a
+// zone, unless it is the first change of the operation. It returns early
+// when the context is done.
+func (p *Provider) paceMutation(ctx context.Context, changes int) {
+	if changes == 0 || p.MutationDelay <= 0 {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-p.getAfter()(p.MutationDelay):
+	}
+}
+
+// retryBudgetKey is the context key of the retry budget of an operation.
+type retryBudgetKey struct{}
+
+// retryBudget is the number of retries left to the requests of an operation.
+type retryBudget struct {
+	left atomic.Int64
+}
+
+// withRetryBudget returns a context carrying a new retry budget for a top
+// level operation, if the provider has a RetryBudget.
+func (p *Provider) withRetryBudget(ctx context.Context) context.Context {
+	if p.RetryBudget <= 0 {
+		return ctx
+	}
+
+	budget := &retryBudget{}
+	budget.left.Store(int64(p.RetryBudget))
+	return context.WithValue(ctx, retryBudgetKey{}, budget)
+}
+
+// retry runs the operation with the retry settings of the provider. Every
+// retry is taken from the retry budget of the context, if any, and the
+// operation fails with ErrRetryBudgetExhausted once it is used up.
+func (p *Provider) retry(ctx context.Context, operation func() error) error {
+	maxRetries := p.getOperationRetries()
+	if budget, ok := ctx.Value(retryBudgetKey{}).(*retryBudget); ok {
+		attempt := 0
+		unbudgeted := operation
+		operation = func() error {
+			// Once the budget is used up, the remaining requests fail fast
+			if budget.left.Load() < 0 {
+				return ErrRetryBudgetExhausted
+			}
+
+			attempt++
+			err := unbudgeted()
+			if err != nil && p.isRetryable(err) && attempt < maxRetries && budget.left.Add(-1) < 0 {
+				return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+			}
+
+			return err
+		}
+	}
+
+	// Every failed attempt is reported, and tagged with the operation ID
+	attempt := 0
+	untraced := operation
+	operation = func() error {
+		attempt++
+		err := untraced()
+		if err == nil {
+			return nil
+		}
+
+		id := OperationID(ctx)
+		if id != "" {
+			err = &operationError{id: id, err: err}
+		}
+
+		retrying := p.isRetryable(err) && attempt < maxRetries && ctx.Err() == nil
+		if p.logger != nil {
+			p.logger.DebugContext(ctx, "ClouDNS operation attempt failed", "operation", id, "attempt", attempt, "retrying", retrying, "error", err)
+		}
+		if p.metrics != nil {
+			endpoint, zone := lastRequestOf(ctx)
+			if errors.Is(err, ErrRateLimited) {
+				p.metrics.ObserveRateLimited(endpoint, zone)
+			}
+			if retrying {
+				p.metrics.ObserveRetry(endpoint, zone)
+			}
+		}
+
+		return err
+	}
+
+	policy := retryPolicy{
+		maxRetries:        maxRetries,
+		initialBackoff:    p.getInitialBackoff(),
+		maxBackoff:        p.getMaxBackoff(),
+		rateLimitCooldown: p.getRateLimitCooldown(),
+		zoneLockedPause:   p.getZoneLockedPause(),
+		after:             p.getAfter(),
+		retryable:         p.isRetryable,
+	}
+
+	return policy.do(ctx, operation)
+}
+
+// isRetryable reports whether an operation that failed with err may succeed
+// when attempted again, according to the RetryableStatusDescriptions.
+func (p *Provider) isRetryable(err error) bool {
+	if len(p.RetryableStatusDescriptions) == 0 {
+		return isRetryable(err)
+	}
+
+	return isRetryableWith(err, p.RetryableStatusDescriptions)
+}
+
 // Helper methods to get configuration values with defaults
 
 // getOperationRetries returns the configured operation retries or the default value
@@ -223,6 +1137,32 @@ func (p *Provider) getInitialBackoff() time.Duration {
 	return p.InitialBackoff
 }
 
+// getZoneLockedPause returns the configured pause after locked zone errors
+// or the default value
+func (p *Provider) getZoneLockedPause() time.Duration {
+	if p.ZoneLockedPause <= 0 {
+		return DefaultZoneLockedPause
+	}
+	return p.ZoneLockedPause
+}
+
+// getRateLimitCooldown returns the configured rate limit cool-off or the default value
+func (p *Provider) getRateLimitCooldown() time.Duration {
+	if p.RateLimitCooldown <= 0 {
+		return DefaultRateLimitCooldown
+	}
+	return p.RateLimitCooldown
+}
+
+// getAfter returns the function waiting for a duration set with WithClock,
+// or time.After
+func (p *Provider) getAfter() func(time.Duration) <-chan time.Time {
+	if p.after == nil {
+		return time.After
+	}
+	return p.after
+}
+
 // getMaxBackoff returns the configured max backoff or the default value
 func (p *Provider) getMaxBackoff() time.Duration {
 	if p.MaxBackoff <= 0 {
@@ -237,4 +1177,6 @@ var (
 	_ libdns.RecordAppender = (*Provider)(nil)
 	_ libdns.RecordSetter   = (*Provider)(nil)
 	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
+	_ io.Closer             = (*Provider)(nil)
 )
@@ -2,22 +2,121 @@ package cloudns
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"iter"
+	"net/http"
 	"net/netip"
+	"os"
+	"path/filepath"
 	"reflect"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/libdns/libdns"
 )
 
-var (
-	TAuthId       = ""
-	TSubAuthId    = ""
-	TAuthPassword = ""
-	TZone         = ""
-)
+// liveConfig is the ClouDNS account the live tests run against, taken from
+// the CLOUDNS_TEST_* environment variables.
+type liveConfig struct {
+	authId       string
+	subAuthId    string
+	authPassword string
+	zone         string
+}
+
+// loadLiveConfig reads the live test account from the environment, and
+// reports whether it is complete.
+func loadLiveConfig() (liveConfig, bool) {
+	cfg := liveConfig{
+		authId:       os.Getenv("CLOUDNS_TEST_AUTH_ID"),
+		subAuthId:    os.Getenv("CLOUDNS_TEST_SUB_AUTH_ID"),
+		authPassword: os.Getenv("CLOUDNS_TEST_AUTH_PASSWORD"),
+		zone:         os.Getenv("CLOUDNS_TEST_ZONE"),
+	}
+
+	return cfg, (cfg.authId != "" || cfg.subAuthId != "") && cfg.authPassword != "" && cfg.zone != ""
+}
+
+// credentials returns the option authenticating a provider with the account.
+func (cfg liveConfig) credentials() ProviderOption {
+	if cfg.subAuthId != "" {
+		return WithSubUserCredentials(cfg.subAuthId, cfg.authPassword)
+	}
+
+	return WithCredentials(cfg.authId, cfg.authPassword)
+}
+
+// liveProvider returns a provider for the live test account and its zone,
+// skipping the test if the account is not configured.
+func liveProvider(t *testing.T) (*Provider, string) {
+	t.Helper()
+
+	cfg, ok := loadLiveConfig()
+	if !ok {
+		t.Skip("Set CLOUDNS_TEST_AUTH_ID or CLOUDNS_TEST_SUB_AUTH_ID, CLOUDNS_TEST_AUTH_PASSWORD and CLOUDNS_TEST_ZONE to run live tests")
+	}
+
+	provider, err := NewProvider(cfg.credentials(), WithOperationTimeout(30*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	return provider, cfg.zone
+}
+
+// uniqueName returns a record name that is unique to the test run, so that
+// live tests neither collide with each other nor with existing records.
+func uniqueName(t *testing.T, prefix string) string {
+	t.Helper()
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatalf("Failed to generate record name: %v", err)
+	}
+
+	return prefix + "-" + hex.EncodeToString(suffix)
+}
+
+// cleanupRecords deletes all the records with the given names, and the
+// service records below them, from the zone once the test is done, even if
+// it failed.
+func cleanupRecords(t *testing.T, provider *Provider, zone string, names ...string) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		records, err := provider.GetRecords(ctx, zone)
+		if err != nil {
+			t.Errorf("Failed to get records to clean up: %v", err)
+			return
+		}
+
+		var leftovers []libdns.Record
+		for _, record := range records {
+			name := record.RR().Name
+			if slices.ContainsFunc(names, func(n string) bool { return name == n || strings.HasSuffix(name, "."+n) }) {
+				leftovers = append(leftovers, record)
+			}
+		}
+		if len(leftovers) == 0 {
+			return
+		}
+
+		if _, err := provider.DeleteRecords(ctx, zone, leftovers); err != nil {
+			t.Errorf("Failed to clean up records: %v", err)
+		}
+	})
+}
 
 func zip[T any, U any](first iter.Seq[T], second iter.Seq[U]) iter.Seq2[T, U] {
 	return func(yield func(T, U) bool) {
@@ -46,15 +145,9 @@ func zip[T any, U any](first iter.Seq[T], second iter.Seq[U]) iter.Seq2[T, U] {
 }
 
 func TestGetRecords(t *testing.T) {
-	provider := &Provider{
-		AuthId:       TAuthId,
-		SubAuthId:    TSubAuthId,
-		AuthPassword: TAuthPassword,
-	}
-	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
-	defer cancel()
+	provider, zone := liveProvider(t)
 
-	records, err := provider.GetRecords(ctx, TZone)
+	records, err := provider.GetRecords(t.Context(), zone)
 	if err != nil {
 		t.Fatalf("Failed to get records: %s", err)
 	}
@@ -72,52 +165,53 @@ func TestGetRecords(t *testing.T) {
 }
 
 func TestAppendRecords(t *testing.T) {
-	provider := &Provider{
-		AuthId:       TAuthId,
-		SubAuthId:    TSubAuthId,
-		AuthPassword: TAuthPassword,
-	}
-	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
-	defer cancel()
+	provider, zone := liveProvider(t)
+
+	name := uniqueName(t, "test")
+	cname := uniqueName(t, "test-cname")
+	mx := uniqueName(t, "test-mx")
+	ns := uniqueName(t, "test-ns")
+	cleanupRecords(t, provider, zone, name, cname, mx, ns)
+
 	// Prepare a record to append
 	records := []libdns.Record{
 		libdns.Address{
-			Name: "test",
+			Name: name,
 			TTL:  300 * time.Second,
 			IP:   netip.MustParseAddr("127.0.0.1"),
 		},
 		libdns.Address{
-			Name: "test",
+			Name: name,
 			TTL:  300 * time.Second,
 			IP:   netip.MustParseAddr("::1"),
 		},
 		libdns.CAA{
-			Name:  "test",
+			Name:  name,
 			TTL:   300 * time.Second,
 			Flags: 0,
 			Tag:   "issue",
 			Value: "bar",
 		},
 		libdns.CNAME{
-			Name:   "test-cname",
+			Name:   cname,
 			TTL:    300 * time.Second,
 			Target: "example.com",
 		},
 		libdns.MX{
-			Name:       "test-mx",
+			Name:       mx,
 			TTL:        300 * time.Second,
 			Preference: 1,
 			Target:     "example.com",
 		},
 		libdns.NS{
-			Name:   "test-ns",
+			Name:   ns,
 			TTL:    300 * time.Second,
 			Target: "example.com",
 		},
 		libdns.SRV{
 			Service:   "http",
 			Transport: "tcp",
-			Name:      "test",
+			Name:      name,
 			TTL:       300 * time.Second,
 			Priority:  1,
 			Weight:    1,
@@ -125,14 +219,14 @@ func TestAppendRecords(t *testing.T) {
 			Target:    "example.com",
 		},
 		libdns.TXT{
-			Name: "test",
+			Name: name,
 			TTL:  300 * time.Second,
 			Text: "test-value",
 		},
 	}
 
 	// Append the record
-	addedRecords, err := provider.AppendRecords(ctx, TZone, records)
+	addedRecords, err := provider.AppendRecords(t.Context(), zone, records)
 	if err != nil {
 		t.Fatalf("Failed to append records: %s", err)
 	}
@@ -148,32 +242,30 @@ func TestAppendRecords(t *testing.T) {
 		}
 	}
 
-	// Clean up the added record
-	_, err = provider.DeleteRecords(ctx, TZone, addedRecords)
+	deletedRecords, err := provider.DeleteRecords(t.Context(), zone, addedRecords)
 	if err != nil {
-		t.Errorf("Failed to clean up added record: %s", err)
+		t.Errorf("Failed to delete added records: %s", err)
+	}
+	if len(deletedRecords) != len(addedRecords) {
+		t.Errorf("Expected %d records to be deleted, got %d", len(addedRecords), len(deletedRecords))
 	}
 }
 
 func TestSetRecords(t *testing.T) {
-	provider := &Provider{
-		AuthId:       TAuthId,
-		SubAuthId:    TSubAuthId,
-		AuthPassword: TAuthPassword,
-	}
+	provider, zone := liveProvider(t)
 
-	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
-	defer cancel()
+	name := uniqueName(t, "test-set")
+	cleanupRecords(t, provider, zone, name)
 
 	// Prepare a record to set
 	record := libdns.TXT{
-		Name: "test-set",
+		Name: name,
 		Text: "test-value",
 		TTL:  300 * time.Second,
 	}
 
 	// Append the record to set
-	addedRecords, err := provider.AppendRecords(ctx, TZone, []libdns.Record{record})
+	addedRecords, err := provider.AppendRecords(t.Context(), zone, []libdns.Record{record})
 	if err != nil {
 		t.Fatalf("Failed to append records: %s", err)
 	}
@@ -187,7 +279,7 @@ func TestSetRecords(t *testing.T) {
 
 	updatedRecord.Text = updatedValue
 
-	setRecords, err := provider.SetRecords(ctx, TZone, []libdns.Record{updatedRecord})
+	setRecords, err := provider.SetRecords(t.Context(), zone, []libdns.Record{updatedRecord})
 	if err != nil {
 		t.Fatalf("Failed to set records: %s", err)
 	}
@@ -201,10 +293,752 @@ func TestSetRecords(t *testing.T) {
 	if !reflect.DeepEqual(setRecord.RR(), updatedRecord.RR()) {
 		t.Errorf("Record data mismatch: expected %+v, got %+v", updatedRecord, setRecord)
 	}
+}
+
+func TestAllowedZones(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/list-zones.json":
+			fmt.Fprint(w, `[{"name":"example.com","type":"master","status":"1"},{"name":"example.net","type":"master","status":"1"}]`)
+		case "/dns/get-zone-info.json":
+			if r.URL.Query().Get("domain-name") != "example.net" {
+				fmt.Fprint(w, `{"status":"Failed","statusDescription":"Missing domain-name"}`)
+				return
+			}
+			fmt.Fprint(w, `{"name":"example.net","type":"master","status":"1"}`)
+		case "/dns/records.json":
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", AllowedZones: []string{"Example.com."}}
+	if _, err := provider.GetRecords(t.Context(), "example.com."); err != nil {
+		t.Errorf("Expected an allowed zone to be accessible, got %v", err)
+	}
+
+	records := []libdns.Record{libdns.TXT{Name: "www", Text: "hello"}}
+	if _, err := provider.SetRecords(t.Context(), "example.net", records); !errors.Is(err, ErrZoneNotAllowed) {
+		t.Errorf("Expected ErrZoneNotAllowed from SetRecords, got %v", err)
+	}
+	if _, err := provider.DeleteRecords(t.Context(), "example.org.", records); !errors.Is(err, ErrZoneNotAllowed) {
+		t.Errorf("Expected ErrZoneNotAllowed from DeleteRecords, got %v", err)
+	}
+	if _, err := provider.FindZone(t.Context(), "www.example.net."); !errors.Is(err, ErrZoneNotAllowed) {
+		t.Errorf("Expected ErrZoneNotAllowed from FindZone, got %v", err)
+	}
+
+	zones, err := provider.ListZones(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to list zones: %v", err)
+	}
+	if len(zones) != 1 || zones[0].Name != "example.com." {
+		t.Errorf("Expected only the allowed zone, got %+v", zones)
+	}
+}
+
+func TestSharedClient(t *testing.T) {
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+	first, err := provider.client()
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	second, _ := provider.client()
+	if first != second {
+		t.Errorf("Expected the operations to share a client")
+	}
+
+	provider.SetCredentials("id", "", "rotated")
+	rotated, _ := provider.client()
+	if rotated == first || rotated.(*Client).AuthPassword != "rotated" || first.(*Client).AuthPassword != "password" {
+		t.Errorf("Expected a new client for the rotated credentials, keeping the previous one intact")
+	}
+}
+
+func TestSetCredentials(t *testing.T) {
+	var mu sync.Mutex
+	passwords := map[string]int{}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		passwords[r.URL.Query().Get("auth-password")]++
+		mu.Unlock()
+		fmt.Fprint(w, `{}`)
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "old"}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+				t.Errorf("Failed to get records: %v", err)
+			}
+		}()
+	}
+	provider.SetCredentials("id", "", "new")
+	wg.Wait()
+
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+	if passwords["new"] == 0 || passwords["old"]+passwords["new"] != 5 {
+		t.Errorf("Expected requests to switch to the rotated password, got %v", passwords)
+	}
+}
+
+func TestProviderJSON(t *testing.T) {
+	config := `{"auth_id":"id","auth_password":"secret","fallback_auth_password":"other","skip_inactive":true}`
+
+	var provider Provider
+	if err := json.Unmarshal([]byte(config), &provider); err != nil {
+		t.Fatalf("Failed to unmarshal provider: %v", err)
+	}
+	if provider.AuthPassword != "secret" || provider.FallbackAuthPassword != "other" || !provider.SkipInactive {
+		t.Errorf("Unexpected provider %+v", &provider)
+	}
+
+	data, err := json.Marshal(&provider)
+	if err != nil {
+		t.Fatalf("Failed to marshal provider: %v", err)
+	}
+	assertJSON(t, data, `{"auth_id":"id","auth_password":"REDACTED","fallback_auth_password":"REDACTED","skip_inactive":true}`)
+
+	provider.MarshalSecrets = true
+	data, err = json.Marshal(&provider)
+	if err != nil {
+		t.Fatalf("Failed to marshal provider: %v", err)
+	}
+	assertJSON(t, data, config)
+}
+
+// assertJSON compares JSON documents regardless of the order of their fields.
+func assertJSON(t *testing.T, actual []byte, expected string) {
+	t.Helper()
+
+	var actualValue, expectedValue any
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		t.Fatalf("Failed to decode %s: %v", actual, err)
+	}
+	if err := json.Unmarshal([]byte(expected), &expectedValue); err != nil {
+		t.Fatalf("Failed to decode %s: %v", expected, err)
+	}
+	if !reflect.DeepEqual(actualValue, expectedValue) {
+		t.Errorf("actual: %s\n\nexpected: %s", actual, expected)
+	}
+}
+
+func TestAuthPasswordFile(t *testing.T) {
+	var passwords []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		passwords = append(passwords, r.URL.Query().Get("auth-password"))
+		fmt.Fprint(w, `{}`)
+	})
+
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write password file: %v", err)
+	}
+
+	provider := &Provider{AuthId: "id", AuthPasswordFile: path}
+	if err := provider.Validate(); err != nil {
+		t.Errorf("Expected a password file to be a valid configuration, got %v", err)
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+
+	// Rotate the secret the way Kubernetes does, by replacing the file
+	rotated := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(rotated, []byte("second\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write password file: %v", err)
+	}
+	if err := os.Chtimes(rotated, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Failed to touch password file: %v", err)
+	}
+	if err := os.Rename(rotated, path); err != nil {
+		t.Fatalf("Failed to replace password file: %v", err)
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+
+	if !reflect.DeepEqual(passwords, []string{"first", "second"}) {
+		t.Errorf("Expected the rotated password to be picked up, got %v", passwords)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove password file: %v", err)
+	}
+	provider.OperationRetries = 1
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err == nil {
+		t.Errorf("Expected an error for a missing password file")
+	}
+}
+
+func TestClose(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	provider, err := NewProvider(WithCredentials("id", "password"), WithHTTPClient(&http.Client{}), WithCache(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+
+	for range 2 {
+		if err := provider.Close(); err != nil {
+			t.Errorf("Failed to close provider: %v", err)
+		}
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); !errors.Is(err, ErrProviderClosed) {
+		t.Errorf("Expected ErrProviderClosed, got %v", err)
+	}
+}
+
+// fakeZone is a stateful stand-in for the record endpoints of a single zone.
+type fakeZone struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	nextId  int
+	records map[string]ApiDnsRecord
+}
+
+func (z *fakeZone) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/dns/records.json":
+		// Leave room for concurrent operations to interleave
+		time.Sleep(time.Millisecond)
+		if err := json.NewEncoder(w).Encode(z.records); err != nil {
+			z.t.Errorf("Failed to encode records: %v", err)
+		}
+	case "/dns/get-available-ttl.json":
+		fmt.Fprint(w, `[60,300,3600]`)
+	case "/dns/get-zone-info.json":
+		fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+	case "/dns/get-available-record-types.json":
+		fmt.Fprint(w, `["A","AAAA","CNAME","TXT"]`)
+	case "/dns/add-record.json", "/dns/mod-record.json":
+		id := query.Get("record-id")
+		if id == "" {
+			z.nextId++
+			id = strconv.Itoa(z.nextId)
+		}
+		z.records[id] = ApiDnsRecord{Id: id, Type: query.Get("record-type"), Host: query.Get("host"), Record: query.Get("record"), Ttl: query.Get("ttl"), Status: 1}
+		fmt.Fprintf(w, `{"status":"Success","statusDescription":"The record was saved successfully.","data":{"id":%s}}`, id)
+	case "/dns/delete-record.json":
+		delete(z.records, query.Get("record-id"))
+		fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was deleted successfully."}`)
+	default:
+		z.t.Errorf("Unexpected path %q", r.URL.Path)
+	}
+}
+
+func TestConcurrentOperations(t *testing.T) {
+	zone := &fakeZone{t: t, records: map[string]ApiDnsRecord{}}
+	useTestServer(t, zone.ServeHTTP)
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+
+	var wg sync.WaitGroup
+	for idx := range 8 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			records := []libdns.Record{libdns.TXT{Name: "shared", TTL: time.Minute, Text: strconv.Itoa(idx)}}
+			if _, err := provider.SetRecords(t.Context(), "example.com", records); err != nil {
+				t.Errorf("Failed to set records: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			records := []libdns.Record{libdns.TXT{Name: "append" + strconv.Itoa(idx), TTL: time.Minute, Text: "hello"}}
+			if _, err := provider.AppendRecords(t.Context(), "example.com.", records); err != nil {
+				t.Errorf("Failed to append records: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			records := []libdns.Record{libdns.TXT{Name: "shared"}}
+			if _, err := provider.DeleteRecords(t.Context(), "Example.com", records); err != nil {
+				t.Errorf("Failed to delete records: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	shared := 0
+	for _, record := range zone.records {
+		if record.Host == "shared" {
+			shared++
+		}
+	}
+	if shared > 1 || len(zone.records) != 8+shared {
+		t.Errorf("Expected the operations to be serialized, got records %+v", zone.records)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	clock := &fakeClock{}
+	provider, err := NewProvider(WithCredentials("id", "password"), WithBackoff(time.Minute, time.Hour), WithClock(clock.After))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+
+	if expected := []time.Duration{time.Minute, 2 * time.Minute}; !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf("Expected waits %v, got %v", expected, clock.waits)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	additions := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","AAAA","CNAME","TXT"]`)
+		case "/dns/add-record.json":
+			additions++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
 
-	// Clean up the added record
-	_, err = provider.DeleteRecords(ctx, TZone, addedRecords)
+	clock := &fakeClock{}
+	provider, err := NewProvider(WithCredentials("id", "password"), WithRetryBudget(3), WithClock(clock.After))
 	if err != nil {
-		t.Errorf("Failed to clean up added record: %s", err)
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	var records []libdns.Record
+	for idx := range 10 {
+		records = append(records, libdns.TXT{Name: "www", TTL: time.Minute, Text: strconv.Itoa(idx)})
+	}
+	_, err = provider.SetRecords(t.Context(), "example.com", records)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Errorf("Expected ErrRetryBudgetExhausted, got %v", err)
+	}
+
+	// The first addition is attempted 1+3 times, the others not at all
+	if additions != 4 || len(clock.waits) != 3 {
+		t.Errorf("Expected 4 attempts and 3 retries, got %d attempts and %d retries", additions, len(clock.waits))
+	}
+}
+
+func TestCanceledPartially(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	zone := &fakeZone{t: t, records: map[string]ApiDnsRecord{}}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// Cancel while the third record is being added
+		if r.URL.Path == "/dns/add-record.json" && len(zone.records) == 2 {
+			cancel()
+			return
+		}
+		zone.ServeHTTP(w, r)
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+
+	var records []libdns.Record
+	for idx := range 5 {
+		records = append(records, libdns.TXT{Name: "www", TTL: time.Minute, Text: strconv.Itoa(idx)})
+	}
+	applied, err := provider.SetRecords(ctx, "example.com", records)
+	if !errors.Is(err, ErrCanceledPartially) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ErrCanceledPartially, got %v", err)
+	}
+	if len(applied) != 2 || len(zone.records) != 2 {
+		t.Errorf("Expected 2 records to be applied, got %d of %d", len(applied), len(zone.records))
+	}
+}
+
+func TestOperationTimeout(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	provider, err := NewProvider(WithCredentials("id", "password"), WithOperationTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := provider.GetRecords(context.Background(), "example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// A deadline set by the caller takes precedence
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	ctx, release := provider.withOperationTimeout(ctx)
+	defer release()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) < time.Minute {
+		t.Errorf("Expected the deadline of the caller to be kept, got %s", deadline)
+	}
+}
+
+// mockAPI records the changes a Provider makes. Calls to methods it does not
+// implement panic through the nil embedded API.
+type mockAPI struct {
+	API
+
+	records  []ApiDnsRecord
+	failures int
+	updated  []ApiDnsRecord
+	added    []ApiDnsRecord
+	deleted  []string
+	lookups  []RecordFilter
+}
+
+func (m *mockAPI) GetClouDNSRecords(ctx context.Context, zone string) ([]ApiDnsRecord, error) {
+	return m.records, nil
+}
+
+func (m *mockAPI) GetFilteredClouDNSRecords(ctx context.Context, zone string, filter RecordFilter) ([]ApiDnsRecord, error) {
+	m.lookups = append(m.lookups, filter)
+	return slices.DeleteFunc(slices.Clone(m.records), func(record ApiDnsRecord) bool {
+		return !filter.matches(record)
+	}), nil
+}
+
+func (m *mockAPI) GetAvailableTTLs(ctx context.Context, zone string) ([]int, error) {
+	return []int{60, 300, 3600}, nil
+}
+
+func (m *mockAPI) GetAvailableRecordTypes(ctx context.Context, zone string) ([]string, error) {
+	return []string{"A", "TXT"}, nil
+}
+
+func (m *mockAPI) AddClouDNSRecord(ctx context.Context, zone string, record ApiDnsRecord) (ApiDnsRecord, error) {
+	m.added = append(m.added, record)
+	record.Id = strconv.Itoa(100 + len(m.added))
+	return record, nil
+}
+
+func (m *mockAPI) UpdateRecord(ctx context.Context, zone string, record ApiDnsRecord) (libdns.Record, error) {
+	if m.failures > 0 {
+		m.failures--
+		return nil, newAPIError("The zone is updating, try again later.")
+	}
+
+	m.updated = append(m.updated, record)
+	return record.toLibdnsRecord()
+}
+
+func (m *mockAPI) DeleteRecord(ctx context.Context, zone string, recordId string) error {
+	m.deleted = append(m.deleted, recordId)
+	return nil
+}
+
+func TestWithAPI(t *testing.T) {
+	api := &mockAPI{
+		records: []ApiDnsRecord{
+			{Id: "1", Type: "TXT", Host: "www", Record: "first", Ttl: "300", Status: 1},
+			{Id: "2", Type: "TXT", Host: "www", Record: "second", Ttl: "300", Status: 1},
+			{Id: "3", Type: "TXT", Host: "www", Record: "third", Ttl: "300", Status: 1},
+		},
+		failures: 1,
+	}
+
+	clock := &fakeClock{}
+	provider, err := NewProvider(WithAPI(api), WithClock(clock.After))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = provider.SetRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "www", TTL: 5 * time.Minute, Text: "second"},
+		libdns.TXT{Name: "www", TTL: 5 * time.Minute, Text: "fourth"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+
+	// The matching record is kept, one other one is modified after a retry,
+	// and the last one deleted
+	if len(api.updated) != 1 || api.updated[0].Id != "1" || len(clock.waits) != 1 {
+		t.Errorf("Expected record 1 to be updated after a retry, got %+v after %d retries", api.updated, len(clock.waits))
+	}
+	if len(api.added) != 0 || !slices.Equal(api.deleted, []string{"3"}) {
+		t.Errorf("Expected record 3 to be deleted only, got additions %+v and deletions %v", api.added, api.deleted)
+	}
+}
+
+func TestMutationDelay(t *testing.T) {
+	api := &mockAPI{
+		records: []ApiDnsRecord{
+			{Id: "1", Type: "TXT", Host: "www", Record: "first", Ttl: "300", Status: 1},
+			{Id: "2", Type: "TXT", Host: "www", Record: "second", Ttl: "300", Status: 1},
+			{Id: "3", Type: "TXT", Host: "www", Record: "third", Ttl: "300", Status: 1},
+		},
+		failures: 1,
+	}
+
+	clock := &fakeClock{}
+	provider, err := NewProvider(WithAPI(api), WithClock(clock.After), WithMutationDelay(time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	provider.ZoneLockedPause = 10 * time.Second
+
+	_, err = provider.SetRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "www", TTL: 5 * time.Minute, Text: "second"},
+		libdns.TXT{Name: "www", TTL: 5 * time.Minute, Text: "fourth"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+
+	// The second change is delayed, and paused again after the zone was
+	// reported as updating
+	expected := []time.Duration{time.Second, 10 * time.Second}
+	if !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf("Expected waits %v, got %v", expected, clock.waits)
+	}
+
+	clock.waits = nil
+	_, err = provider.AppendRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "a", TTL: 5 * time.Minute, Text: "a"},
+		libdns.TXT{Name: "b", TTL: 5 * time.Minute, Text: "b"},
+		libdns.TXT{Name: "c", TTL: 5 * time.Minute, Text: "c"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to append records: %v", err)
+	}
+	if expected := []time.Duration{time.Second, time.Second}; !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf("Expected a delay between the additions, got waits %v", clock.waits)
+	}
+
+	provider.MutationDelay = -time.Second
+	if err := provider.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected a negative delay to be rejected, got %v", err)
+	}
+}
+
+func TestSetRecordsConcurrency(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		changes  []string
+		release  = make(chan struct{})
+	)
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{
+				"1": {"id": "1", "type": "TXT", "host": "a", "record": "x", "ttl": "60", "status": 1},
+				"2": {"id": "2", "type": "TXT", "host": "a", "record": "y", "ttl": "60", "status": 1}
+			}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","TXT"]`)
+		case "/dns/add-record.json", "/dns/mod-record.json", "/dns/delete-record.json":
+			mu.Lock()
+			changes = append(changes, r.URL.Path+" "+r.URL.Query().Get("host"))
+			inFlight++
+			if inFlight == 2 {
+				close(release)
+			}
+			mu.Unlock()
+
+			// The first two changes wait for each other
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+				t.Errorf("Expected two concurrent changes")
+			}
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was changed successfully.","data":{"id":3}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	provider, err := NewProvider(WithCredentials("id", "password"), WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	set, err := provider.SetRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.RR{Name: "a", TTL: time.Minute, Type: "TXT", Data: "z"},
+		libdns.RR{Name: "b", TTL: time.Minute, Type: "TXT", Data: "b"},
+		libdns.RR{Name: "c", TTL: time.Minute, Type: "TXT", Data: "c"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+	if len(set) != 3 || len(changes) != 4 {
+		t.Fatalf("Expected 3 records to be set with 4 changes, got %+v after %v", set, changes)
+	}
+
+	// The changes to the same name are still made in order, deletions first
+	deleted := slices.Index(changes, "/dns/delete-record.json ")
+	modified := slices.Index(changes, "/dns/mod-record.json a")
+	if deleted < 0 || modified < deleted {
+		t.Errorf("Expected the deletion before the modification, got %v", changes)
+	}
+}
+
+func TestDeleteRecordsLookups(t *testing.T) {
+	api := &mockAPI{
+		records: []ApiDnsRecord{
+			{Id: "1", Type: "TXT", Host: "www", Record: "first", Ttl: "300", Status: 1},
+			{Id: "2", Type: "A", Host: "www", Record: "192.0.2.1", Ttl: "300", Status: 1},
+			{Id: "3", Type: "TXT", Host: "mail", Record: "second", Ttl: "300", Status: 1},
+		},
+	}
+
+	provider, err := NewProvider(WithAPI(api))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	deleted, err := provider.DeleteRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "www", Text: "first"},
+		libdns.TXT{Name: "WWW", Text: "other"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if len(deleted) != 1 || !slices.Equal(api.deleted, []string{"1"}) {
+		t.Errorf("Expected record 1 to be deleted, got %+v", deleted)
+	}
+	if expected := []RecordFilter{{Host: "www", Type: "TXT"}}; !reflect.DeepEqual(api.lookups, expected) {
+		t.Errorf("Expected the records to be looked up with %+v, got %+v", expected, api.lookups)
+	}
+
+	// Too many names are looked up with a single request for the whole zone
+	api.lookups = nil
+	var records []libdns.Record
+	for i := range maxTargetedLookups + 1 {
+		records = append(records, libdns.TXT{Name: fmt.Sprintf("host%d", i), Text: "value"})
+	}
+	if _, err := provider.DeleteRecords(t.Context(), "example.com", records); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if len(api.lookups) != 0 {
+		t.Errorf("Expected no filtered lookups, got %+v", api.lookups)
+	}
+}
+
+func TestSetRecordsLookups(t *testing.T) {
+	api := &mockAPI{
+		records: []ApiDnsRecord{
+			{Id: "1", Type: "TXT", Host: "www", Record: "first", Ttl: "300", Status: 1},
+			{Id: "2", Type: "SPF", Host: "www", Record: "v=spf1 -all", Ttl: "300", Status: 1},
+			{Id: "3", Type: "TXT", Host: "mail", Record: "second", Ttl: "300", Status: 1},
+		},
+	}
+
+	provider, err := NewProvider(WithAPI(api))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	provider.SPFAsTXT = true
+
+	_, err = provider.SetRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "www", TTL: 5 * time.Minute, Text: "v=spf1 -all"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+
+	// Only the RRset to set is loaded, along with its legacy SPF records
+	expected := []RecordFilter{{Host: "www", Type: "TXT"}, {Host: "www", Type: "SPF"}}
+	if !reflect.DeepEqual(api.lookups, expected) {
+		t.Errorf("Expected the records to be looked up with %+v, got %+v", expected, api.lookups)
+	}
+	if slices.Contains(api.deleted, "3") {
+		t.Errorf("Expected the other RRsets to be left alone, got deletions %v", api.deleted)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	api := &mockAPI{
+		records: []ApiDnsRecord{
+			{Id: "1", Type: "TXT", Host: "www", Record: "first", Ttl: "300", Status: 1},
+			{Id: "2", Type: "TXT", Host: "www", Record: "second", Ttl: "300", Status: 1},
+		},
+	}
+
+	var events []string
+	provider, err := NewProvider(WithAPI(api))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	provider.OnRecordAdded = func(ctx context.Context, zone string, record libdns.Record) {
+		events = append(events, fmt.Sprintf("added %s %s", zone, record.RR().Data))
+	}
+	provider.OnRecordModified = func(ctx context.Context, zone string, before, after libdns.Record) {
+		events = append(events, fmt.Sprintf("modified %s %s -> %s", zone, before.RR().Data, after.RR().Data))
+	}
+	provider.OnRecordDeleted = func(ctx context.Context, zone string, record libdns.Record) {
+		events = append(events, fmt.Sprintf("deleted %s %s", zone, record.RR().Data))
+	}
+	provider.OnZoneSynced = func(ctx context.Context, zone string, records []libdns.Record) {
+		events = append(events, fmt.Sprintf("synced %s %d", zone, len(records)))
+	}
+
+	if _, err := provider.SetRecords(t.Context(), "example.com.", []libdns.Record{
+		libdns.TXT{Name: "www", TTL: 5 * time.Minute, Text: "third"},
+	}); err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+	if _, err := provider.AppendRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "api", TTL: 5 * time.Minute, Text: "fourth"},
+	}); err != nil {
+		t.Fatalf("Failed to append records: %v", err)
+	}
+	if _, err := provider.DeleteRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "www", Text: "first"},
+	}); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+
+	expected := []string{
+		"deleted example.com second",
+		"modified example.com first -> third",
+		"synced example.com 1",
+		"added example.com fourth",
+		"deleted example.com first",
+	}
+	if !slices.Equal(events, expected) {
+		t.Errorf("Expected events %q, got %q", expected, events)
 	}
 }
@@ -0,0 +1,221 @@
+package cloudns
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// rdataField describes a field of the data of a record type that ClouDNS
+// takes as a separate parameter, and returns as a separate key of
+// records.json.
+type rdataField struct {
+	// param is the name of the add-record.json and mod-record.json parameter
+	param string
+
+	// key is the name of the records.json key
+	key string
+
+	// quoted marks character strings, which are quoted in presentation format
+	quoted bool
+}
+
+// genericRDATA lists the fields, in presentation order, of the record types
+// without a dedicated libdns type that ClouDNS splits into several
+// parameters. Records of these types carry their data in presentation format
+// in the Record field of ApiDnsRecord, which is split into the parameters on
+// write and assembled from the records.json keys on read, so that they can be
+// written back verbatim.
+var genericRDATA = map[string][]rdataField{
+	"NAPTR": {
+		{param: "order", key: "order"},
+		{param: "pref", key: "pref"},
+		{param: "flag", key: "flag", quoted: true},
+		{param: "params", key: "params", quoted: true},
+		{param: "regexp", key: "regexp", quoted: true},
+		{param: "replace", key: "replace"},
+	},
+	"RP": {
+		{param: "mail", key: "mail"},
+		{param: "txt", key: "txt"},
+	},
+	"LOC": {
+		{param: "lat-deg", key: "lat_deg"},
+		{param: "lat-min", key: "lat_min"},
+		{param: "lat-sec", key: "lat_sec"},
+		{param: "lat-dir", key: "lat_dir"},
+		{param: "long-deg", key: "long_deg"},
+		{param: "long-min", key: "long_min"},
+		{param: "long-sec", key: "long_sec"},
+		{param: "long-dir", key: "long_dir"},
+		{param: "altitude", key: "altitude"},
+		{param: "size", key: "size"},
+		{param: "h-precision", key: "h_precision"},
+		{param: "v-precision", key: "v_precision"},
+	},
+	"SMIMEA": {
+		{param: "smimea-usage", key: "smimea_usage"},
+		{param: "smimea-selector", key: "smimea_selector"},
+		{param: "smimea-matching-type", key: "smimea_matching_type"},
+		{param: "record", key: "record"},
+	},
+}
+
+// UnmarshalJSON decodes a record returned by records.json. The numeric
+// fields are accepted both as JSON numbers and as strings, as their encoding
+// varies across endpoints and zone types, see unmarshalTolerant. The type is
+// canonicalized, and the data of the record types listed in genericRDATA is
+// assembled into the Record field.
+func (r *ApiDnsRecord) UnmarshalJSON(data []byte) error {
+	type plain ApiDnsRecord
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		*r = ApiDnsRecord{}
+		if err := r.unmarshalTolerant(data); err != nil {
+			return err
+		}
+	}
+	r.Type = canonicalRecordType(r.Type)
+
+	fields, ok := genericRDATA[r.Type]
+	if !ok {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		var value flexString
+		if rawValue, ok := raw[field.key]; ok {
+			if err := json.Unmarshal(rawValue, &value); err != nil {
+				return err
+			}
+		}
+
+		if field.quoted {
+			tokens = append(tokens, quoteTXTString(string(value)))
+		} else {
+			tokens = append(tokens, string(value))
+		}
+	}
+	r.Record = strings.Join(tokens, " ")
+
+	return nil
+}
+
+// unmarshalTolerant decodes a record whose numeric fields are encoded
+// differently than declared on ApiDnsRecord, i.e. numbers as strings or the
+// other way round. It is only used once the regular decoding failed, as
+// decoding every field on its own is about twice as slow.
+func (r *ApiDnsRecord) unmarshalTolerant(data []byte) error {
+	type plain ApiDnsRecord
+	record := struct {
+		*plain
+		Id               flexString `json:"id"`
+		Ttl              flexString `json:"ttl"`
+		Status           flexInt    `json:"status"`
+		CAAFlag          flexUint8  `json:"caa_flag"`
+		Priority         flexUint16 `json:"priority"`
+		Port             flexUint16 `json:"port"`
+		Weight           flexUint16 `json:"weight"`
+		Algorithm        flexUint8  `json:"algorithm"`
+		FpType           flexUint8  `json:"fptype"`
+		KeyTag           flexUint16 `json:"key_tag"`
+		DigestType       flexUint8  `json:"digest_type"`
+		TLSAUsage        flexUint8  `json:"tlsa_usage"`
+		TLSASelector     flexUint8  `json:"tlsa_selector"`
+		TLSAMatchingType flexUint8  `json:"tlsa_matching_type"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	r.Id, r.Ttl, r.Status = string(record.Id), string(record.Ttl), int(record.Status)
+	r.CAAFlag = uint8(record.CAAFlag)
+	r.Priority, r.Port, r.Weight = uint16(record.Priority), uint16(record.Port), uint16(record.Weight)
+	r.Algorithm, r.FpType, r.KeyTag, r.DigestType = uint8(record.Algorithm), uint8(record.FpType), uint16(record.KeyTag), uint8(record.DigestType)
+	r.TLSAUsage, r.TLSASelector, r.TLSAMatchingType = uint8(record.TLSAUsage), uint8(record.TLSASelector), uint8(record.TLSAMatchingType)
+
+	return nil
+}
+
+// rdataParameters splits the presentation format data of a record type listed
+// in genericRDATA into its parameters. It returns false for the other types,
+// and for malformed data, which is then sent as is for ClouDNS to reject it.
+func rdataParameters(type_ string, data string) (map[string]string, bool) {
+	fields, ok := genericRDATA[canonicalRecordType(type_)]
+	if !ok {
+		return nil, false
+	}
+
+	tokens, ok := tokenizeRDATA(data)
+	if !ok || len(tokens) != len(fields) {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(fields))
+	for idx, field := range fields {
+		params[field.param] = tokens[idx].value
+	}
+
+	return params, true
+}
+
+// rdataToken is a field of record data in presentation format.
+type rdataToken struct {
+	// value is the unescaped content of the field, without quotes
+	value  string
+	quoted bool
+}
+
+// tokenizeRDATA splits record data in presentation format into its fields,
+// separated by spaces. Quoted fields may contain spaces, and both `\"` style
+// and `\034` style escapes are supported in them. It returns false for
+// malformed data.
+func tokenizeRDATA(data string) ([]rdataToken, bool) {
+	var tokens []rdataToken
+	rest := strings.TrimSpace(data)
+	for rest != "" {
+		if rest[0] != '"' {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			tokens = append(tokens, rdataToken{value: rest[:end]})
+			rest = strings.TrimLeft(rest[end:], " \t")
+			continue
+		}
+
+		var value strings.Builder
+		idx := 1
+		for ; idx < len(rest) && rest[idx] != '"'; idx++ {
+			if rest[idx] != '\\' {
+				value.WriteByte(rest[idx])
+				continue
+			}
+
+			idx++
+			if idx >= len(rest) {
+				return nil, false
+			}
+			if idx+3 <= len(rest) {
+				if code, err := strconv.ParseUint(rest[idx:idx+3], 10, 8); err == nil {
+					value.WriteByte(byte(code))
+					idx += 2
+					continue
+				}
+			}
+			value.WriteByte(rest[idx])
+		}
+		if idx >= len(rest) {
+			return nil, false
+		}
+
+		tokens = append(tokens, rdataToken{value: value.String(), quoted: true})
+		rest = strings.TrimLeft(rest[idx+1:], " \t")
+	}
+
+	return tokens, true
+}
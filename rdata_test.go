@@ -0,0 +1,80 @@
+package cloudns
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestGenericRDATARoundTrip(t *testing.T) {
+	data := []byte(`{"id":"1","type":"NAPTR","host":"sip","ttl":"3600","status":1,"order":"100","pref":"10","flag":"S","params":"SIP+D2U","regexp":"","replace":"_sip._udp.example.com"}`)
+
+	var record ApiDnsRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+
+	rec, err := record.toLibdnsRecord()
+	if err != nil {
+		t.Fatalf("Failed to convert record: %v", err)
+	}
+	expected := `100 10 "S" "SIP+D2U" "" _sip._udp.example.com`
+	if rr := rec.RR(); rr.Type != "NAPTR" || rr.Data != expected {
+		t.Errorf("Expected NAPTR data %q, got %+v", expected, rr)
+	}
+
+	params := fromLibdnsRecord(rec, "1", nil).toParameters()
+	expectedParams := map[string]string{
+		"record-id":   "1",
+		"record-type": "NAPTR",
+		"host":        "sip",
+		"ttl":         "3600",
+		"order":       "100",
+		"pref":        "10",
+		"flag":        "S",
+		"params":      "SIP+D2U",
+		"regexp":      "",
+		"replace":     "_sip._udp.example.com",
+	}
+	if !maps.Equal(params, expectedParams) {
+		t.Errorf("Expected parameters %v, got %v", expectedParams, params)
+	}
+}
+
+func TestGenericRDATAMatching(t *testing.T) {
+	existing := ApiDnsRecord{Id: "1", Type: "RP", Host: "www", Record: "admin.example.com. info.example.com.", Ttl: "3600"}
+	desired := libdns.RR{Name: "www", TTL: time.Hour, Type: "RP", Data: "admin.example.com.   info.example.com."}
+
+	if _, ok := pairOperation(existing, desired, nil); ok {
+		t.Errorf("Expected no operation for the same data with different spacing")
+	}
+
+	malformed := libdns.RR{Name: "@", TTL: time.Hour, Type: "RP", Data: "admin.example.com."}
+	if err := validateRecord(malformed); err == nil {
+		t.Errorf("Expected RP record with a missing field to be rejected")
+	}
+}
+
+func TestTokenizeRDATA(t *testing.T) {
+	tokens, ok := tokenizeRDATA(`10 "quoted value" "esc\"aped" bare`)
+	if !ok {
+		t.Fatalf("Failed to tokenize data")
+	}
+
+	expected := []rdataToken{{value: "10"}, {value: "quoted value", quoted: true}, {value: `esc"aped`, quoted: true}, {value: "bare"}}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, tokens)
+	}
+	for idx := range tokens {
+		if tokens[idx] != expected[idx] {
+			t.Errorf("Expected token %v, got %v", expected[idx], tokens[idx])
+		}
+	}
+
+	if _, ok := tokenizeRDATA(`"unterminated`); ok {
+		t.Errorf("Expected unterminated quotes to be rejected")
+	}
+}
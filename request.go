@@ -0,0 +1,220 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// defaultRequestTTL is the TTL of the records added through the typed
+// requests, unless set with their TTL method.
+const defaultRequestTTL = time.Hour
+
+// recordRequest holds what is common to the typed requests adding a record.
+// The required fields of each record type are arguments of the function
+// creating the request, so they cannot be left out.
+type recordRequest struct {
+	client *Client
+	zone   string
+	type_  string
+	ttl    time.Duration
+	build  func(ttl time.Duration) libdns.Record
+}
+
+func newRecordRequest(c *Client, zone string, type_ string, build func(ttl time.Duration) libdns.Record) recordRequest {
+	return recordRequest{client: c, zone: zone, type_: type_, ttl: defaultRequestTTL, build: build}
+}
+
+// Do validates the record and adds it to the zone. The TTL is rounded to the
+// next value accepted for the zone.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//
+// Returns:
+//   - libdns.Record: The record that was added
+//   - error: Any error that occurred during the operation, wrapping ErrInvalidRecord if the record was rejected
+func (r *recordRequest) Do(ctx context.Context) (libdns.Record, error) {
+	rec := r.build(r.ttl)
+	if type_ := rec.RR().Type; type_ != r.type_ {
+		return nil, fmt.Errorf("%w %s %q: data is for a %s record", ErrInvalidRecord, r.type_, rec.RR().Name, type_)
+	}
+	if err := validateRecord(rec); err != nil {
+		return nil, err
+	}
+
+	ttls, err := availableTTLs(ctx, r.client, r.zone)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.client.AddRecord(ctx, r.zone, fromLibdnsRecord(rec, "", ttls))
+}
+
+// AddARecordRequest adds an A record, see Client.NewAddARecordRequest.
+type AddARecordRequest struct{ recordRequest }
+
+// NewAddARecordRequest prepares the addition of an A record pointing host to
+// an IPv4 address. Call Do to send it.
+func (c *Client) NewAddARecordRequest(zone string, host string, ip netip.Addr) *AddARecordRequest {
+	return &AddARecordRequest{newRecordRequest(c, zone, "A", func(ttl time.Duration) libdns.Record {
+		return libdns.Address{Name: host, TTL: ttl, IP: ip}
+	})}
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddARecordRequest) TTL(ttl time.Duration) *AddARecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// AddAAAARecordRequest adds an AAAA record, see Client.NewAddAAAARecordRequest.
+type AddAAAARecordRequest struct{ recordRequest }
+
+// NewAddAAAARecordRequest prepares the addition of an AAAA record pointing
+// host to an IPv6 address. Call Do to send it.
+func (c *Client) NewAddAAAARecordRequest(zone string, host string, ip netip.Addr) *AddAAAARecordRequest {
+	return &AddAAAARecordRequest{newRecordRequest(c, zone, "AAAA", func(ttl time.Duration) libdns.Record {
+		return libdns.Address{Name: host, TTL: ttl, IP: ip}
+	})}
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddAAAARecordRequest) TTL(ttl time.Duration) *AddAAAARecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// AddCNAMERecordRequest adds a CNAME record, see Client.NewAddCNAMERecordRequest.
+type AddCNAMERecordRequest struct{ recordRequest }
+
+// NewAddCNAMERecordRequest prepares the addition of a CNAME record making
+// host an alias of target. Call Do to send it.
+func (c *Client) NewAddCNAMERecordRequest(zone string, host string, target string) *AddCNAMERecordRequest {
+	return &AddCNAMERecordRequest{newRecordRequest(c, zone, "CNAME", func(ttl time.Duration) libdns.Record {
+		return libdns.CNAME{Name: host, TTL: ttl, Target: target}
+	})}
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddCNAMERecordRequest) TTL(ttl time.Duration) *AddCNAMERecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// AddMXRecordRequest adds an MX record, see Client.NewAddMXRecordRequest.
+type AddMXRecordRequest struct{ recordRequest }
+
+// NewAddMXRecordRequest prepares the addition of an MX record delivering the
+// mail of host to target, with the given preference. Call Do to send it.
+func (c *Client) NewAddMXRecordRequest(zone string, host string, preference uint16, target string) *AddMXRecordRequest {
+	return &AddMXRecordRequest{newRecordRequest(c, zone, "MX", func(ttl time.Duration) libdns.Record {
+		return libdns.MX{Name: host, TTL: ttl, Preference: preference, Target: target}
+	})}
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddMXRecordRequest) TTL(ttl time.Duration) *AddMXRecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// AddNSRecordRequest adds an NS record, see Client.NewAddNSRecordRequest.
+type AddNSRecordRequest struct{ recordRequest }
+
+// NewAddNSRecordRequest prepares the addition of an NS record delegating host
+// to the target nameserver. Call Do to send it.
+func (c *Client) NewAddNSRecordRequest(zone string, host string, target string) *AddNSRecordRequest {
+	return &AddNSRecordRequest{newRecordRequest(c, zone, "NS", func(ttl time.Duration) libdns.Record {
+		return libdns.NS{Name: host, TTL: ttl, Target: target}
+	})}
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddNSRecordRequest) TTL(ttl time.Duration) *AddNSRecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// AddTXTRecordRequest adds a TXT record, see Client.NewAddTXTRecordRequest.
+type AddTXTRecordRequest struct{ recordRequest }
+
+// NewAddTXTRecordRequest prepares the addition of a TXT record holding text,
+// which is quoted and split as needed. Call Do to send it.
+func (c *Client) NewAddTXTRecordRequest(zone string, host string, text string) *AddTXTRecordRequest {
+	return &AddTXTRecordRequest{newRecordRequest(c, zone, "TXT", func(ttl time.Duration) libdns.Record {
+		return libdns.TXT{Name: host, TTL: ttl, Text: text}
+	})}
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddTXTRecordRequest) TTL(ttl time.Duration) *AddTXTRecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// AddSRVRecordRequest adds an SRV record, see Client.NewAddSRVRecordRequest.
+type AddSRVRecordRequest struct {
+	recordRequest
+	weight uint16
+}
+
+// NewAddSRVRecordRequest prepares the addition of an SRV record publishing the
+// service, e.g. "sip", over the transport, e.g. "tcp", for name, "@" being
+// the zone apex. The weight is 0 unless set with Weight. Call Do to send it.
+func (c *Client) NewAddSRVRecordRequest(zone string, service string, transport string, name string, priority uint16, port uint16, target string) *AddSRVRecordRequest {
+	req := &AddSRVRecordRequest{}
+	req.recordRequest = newRecordRequest(c, zone, "SRV", func(ttl time.Duration) libdns.Record {
+		return libdns.SRV{Service: service, Transport: transport, Name: name, TTL: ttl, Priority: priority, Weight: req.weight, Port: port, Target: target}
+	})
+
+	return req
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddSRVRecordRequest) TTL(ttl time.Duration) *AddSRVRecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// Weight sets the weight of the record among the records of the same priority.
+func (r *AddSRVRecordRequest) Weight(weight uint16) *AddSRVRecordRequest {
+	r.weight = weight
+	return r
+}
+
+// AddCAARecordRequest adds a CAA record, see Client.NewAddCAARecordRequest.
+type AddCAARecordRequest struct {
+	recordRequest
+	critical bool
+}
+
+// NewAddCAARecordRequest prepares the addition of a CAA record with the given
+// tag, "issue", "issuewild" or "iodef", and value. Call Do to send it.
+func (c *Client) NewAddCAARecordRequest(zone string, host string, tag string, value string) *AddCAARecordRequest {
+	req := &AddCAARecordRequest{}
+	req.recordRequest = newRecordRequest(c, zone, "CAA", func(ttl time.Duration) libdns.Record {
+		var flags uint8
+		if req.critical {
+			flags = caaCriticalFlag
+		}
+		return libdns.CAA{Name: host, TTL: ttl, Flags: flags, Tag: tag, Value: value}
+	})
+
+	return req
+}
+
+// TTL sets the TTL of the record, one hour by default.
+func (r *AddCAARecordRequest) TTL(ttl time.Duration) *AddCAARecordRequest {
+	r.ttl = ttl
+	return r
+}
+
+// Critical sets the issuer critical flag of the record.
+func (r *AddCAARecordRequest) Critical() *AddCAARecordRequest {
+	r.critical = true
+	return r
+}
@@ -0,0 +1,69 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestAddRecordRequests(t *testing.T) {
+	var params []map[string]string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/add-record.json":
+			query := r.URL.Query()
+			params = append(params, map[string]string{
+				"record-type": query.Get("record-type"),
+				"host":        query.Get("host"),
+				"ttl":         query.Get("ttl"),
+				"weight":      query.Get("weight"),
+				"caa_flag":    query.Get("caa_flag"),
+			})
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":1}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	client := UseClient("id", "", "password")
+	ctx := t.Context()
+
+	srv, err := client.NewAddSRVRecordRequest("example.com", "sip", "tcp", "@", 10, 5060, "sip.example.com").Weight(5).TTL(5 * time.Minute).Do(ctx)
+	if err != nil {
+		t.Fatalf("Failed to add SRV record: %v", err)
+	}
+	if id, ok := RecordID(srv); !ok || id != "1" {
+		t.Errorf("Expected the ID assigned by ClouDNS, got %q", id)
+	}
+	if _, err := client.NewAddCAARecordRequest("example.com", "@", "issue", "letsencrypt.org").Critical().Do(ctx); err != nil {
+		t.Fatalf("Failed to add CAA record: %v", err)
+	}
+
+	if len(params) != 2 {
+		t.Fatalf("Expected 2 records to be added, got %v", params)
+	}
+	if p := params[0]; p["record-type"] != "SRV" || p["host"] != "_sip._tcp" || p["ttl"] != "300" || p["weight"] != "5" {
+		t.Errorf("Unexpected SRV parameters %v", p)
+	}
+	if p := params[1]; p["record-type"] != "CAA" || p["ttl"] != "3600" || p["caa_flag"] != "128" {
+		t.Errorf("Unexpected CAA parameters %v", p)
+	}
+
+	// Records of the wrong type or failing validation are not sent
+	_, err = client.NewAddARecordRequest("example.com", "www", netip.MustParseAddr("2001:db8::1")).Do(ctx)
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Errorf("Expected ErrInvalidRecord for an IPv6 address in an A record, got %v", err)
+	}
+	_, err = client.NewAddMXRecordRequest("example.com", "@", 10, "mail..example.com").Do(ctx)
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Errorf("Expected ErrInvalidRecord for an invalid MX target, got %v", err)
+	}
+	if len(params) != 2 {
+		t.Errorf("Expected invalid records not to be sent, got %v", params)
+	}
+}
@@ -0,0 +1,152 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/libdns/libdns"
+)
+
+// CopyRecords copies the records matching the filter to a new name within the
+// same zone, e.g. from "blue" to "green" for blue/green cutovers. The copies
+// keep the TTL, GeoDNS location and status of the original records. A filter
+// without a host would copy the whole zone, and is rejected.
+//
+// SRV records keep their service and transport labels, so copying
+// "_sip._tcp.blue" to "green" creates "_sip._tcp.green". A name that has the
+// labels already, like "_sip._tcp.green", is used as is.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the records
+//   - from: The name, and optionally the type, of the records to copy
+//   - to: The name of the copies, relative to the zone
+//
+// Returns:
+//   - []libdns.Record: The records that were created
+//   - error: Any error that occurred during the operation
+func (c *Client) CopyRecords(ctx context.Context, zone string, from RecordFilter, to string) ([]libdns.Record, error) {
+	if from.Host == "" {
+		return nil, errors.New("no host to copy the records from")
+	}
+
+	records, err := c.GetFilteredClouDNSRecords(ctx, zone, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get records: %w", err)
+	}
+
+	host := normalizeHost(to)
+	if host == "@" {
+		host = ""
+	}
+
+	created := make([]libdns.Record, 0, len(records))
+	for _, record := range records {
+		active := record.Active()
+
+		record.Id = ""
+		record.Host, err = copiedHost(record, host)
+		if err != nil {
+			return created, fmt.Errorf("failed to copy record to %q: %w", to, err)
+		}
+		record, err = c.AddClouDNSRecord(ctx, zone, record)
+		if err != nil {
+			return created, fmt.Errorf("failed to copy record to %q: %w", to, err)
+		}
+
+		if !active {
+			if err := c.DeactivateRecord(ctx, zone, record.Id); err != nil {
+				return created, fmt.Errorf("failed to deactivate record %s: %w", record.Id, err)
+			}
+			record.Status = 0
+		}
+
+		rec, err := record.toLibdnsRecord()
+		if err != nil {
+			return created, err
+		}
+		created = append(created, rec)
+	}
+
+	return created, nil
+}
+
+// copiedHost returns the host of the copy of the record at the given host.
+// The owner name of an SRV record follows its service and transport labels,
+// which are kept.
+func copiedHost(record ApiDnsRecord, host string) (string, error) {
+	if canonicalRecordType(record.Type) != "SRV" {
+		return host, nil
+	}
+	if _, _, _, err := splitSRVHost(host); err == nil {
+		return host, nil
+	}
+
+	service, transport, _, err := splitSRVHost(record.Host)
+	if err != nil {
+		return "", err
+	}
+	if host == "" {
+		return "_" + service + "._" + transport, nil
+	}
+
+	return "_" + service + "._" + transport + "." + host, nil
+}
+
+// ChangeRecordsStatus enables or disables all the records matching any of the
+// filters, e.g. to put a service in maintenance mode without deleting its
+// records. Records already in the requested state are left untouched. A
+// failure to change a record does not prevent the others from being changed.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) containing the records
+//   - active: Whether to enable or disable the records
+//   - filters: The names and types of the records to change
+//
+// Returns:
+//   - []libdns.Record: The records whose status was changed
+//   - error: Any error that occurred during the operation
+func (c *Client) ChangeRecordsStatus(ctx context.Context, zone string, active bool, filters ...RecordFilter) ([]libdns.Record, error) {
+	seen := make(map[string]bool)
+	var changed []libdns.Record
+	var errs []error
+	for _, filter := range filters {
+		if filter.Host == "" && filter.Type == "" {
+			errs = append(errs, errors.New("empty filter would match the whole zone"))
+			continue
+		}
+
+		records, err := c.GetFilteredClouDNSRecords(ctx, zone, filter)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get records: %w", err))
+			continue
+		}
+
+		for _, record := range records {
+			if seen[record.Id] || record.Active() == active {
+				continue
+			}
+			seen[record.Id] = true
+
+			if err := c.ChangeRecordStatus(ctx, zone, record.Id, active); err != nil {
+				errs = append(errs, fmt.Errorf("failed to change status of record %s: %w", record.Id, err))
+				continue
+			}
+
+			record.Status = 0
+			if active {
+				record.Status = 1
+			}
+			rec, err := record.toLibdnsRecord()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			changed = append(changed, rec)
+		}
+	}
+
+	return changed, errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func TestCopyRecords(t *testing.T) {
+	var calls []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/dns/records.json":
+			if query.Get("host") != "blue" {
+				t.Errorf("Expected host filter, got %v", query)
+			}
+			// ClouDNS also returns the records whose name contains the host
+			fmt.Fprint(w, `{"1":{"id":"1","type":"A","host":"blue","record":"192.0.2.1","ttl":"60","status":1},"2":{"id":"2","type":"A","host":"blue.old","record":"192.0.2.2","ttl":"60","status":1}}`)
+		case "/dns/add-record.json":
+			calls = append(calls, "add "+query.Get("host")+" "+query.Get("record"))
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":10}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	created, err := UseClient("id", "", "password").CopyRecords(t.Context(), "example.com", RecordFilter{Host: "blue"}, "green")
+	if err != nil {
+		t.Fatalf("Failed to copy records: %v", err)
+	}
+
+	expected := []string{"add green 192.0.2.1"}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+	if len(created) != 1 || created[0].RR().Name != "green" {
+		t.Errorf("Unexpected created records %+v", created)
+	}
+}
+
+func TestCopySRVRecords(t *testing.T) {
+	tests := []struct {
+		to   string
+		host string
+		name string
+	}{
+		{to: "green", host: "_sip._tcp.green", name: "green"},
+		{to: "_sip._tcp.green", host: "_sip._tcp.green", name: "green"},
+		{to: "@", host: "_sip._tcp", name: "@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.to, func(t *testing.T) {
+			var hosts []string
+			useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				switch r.URL.Path {
+				case "/dns/records.json":
+					fmt.Fprint(w, `{"1":{"id":"1","type":"SRV","host":"_sip._tcp.blue","record":"sip.example.com","ttl":"60","priority":10,"weight":5,"port":5060,"status":1}}`)
+				case "/dns/add-record.json":
+					hosts = append(hosts, query.Get("host"))
+					fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":10}}`)
+				default:
+					t.Errorf("Unexpected path %q", r.URL.Path)
+				}
+			})
+
+			created, err := UseClient("id", "", "password").CopyRecords(t.Context(), "example.com", RecordFilter{Host: "_sip._tcp.blue", Type: "SRV"}, tt.to)
+			if err != nil {
+				t.Fatalf("Failed to copy records: %v", err)
+			}
+
+			if !slices.Equal(hosts, []string{tt.host}) {
+				t.Errorf("Expected the copy at %q, got %v", tt.host, hosts)
+			}
+			if len(created) != 1 {
+				t.Fatalf("Unexpected created records %+v", created)
+			}
+			srv, ok := created[0].(libdns.SRV)
+			if !ok || srv.Service != "sip" || srv.Transport != "tcp" || srv.Name != tt.name {
+				t.Errorf("Unexpected created record %+v", created[0])
+			}
+		})
+	}
+}
+
+func TestChangeRecordsStatus(t *testing.T) {
+	var calls []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/dns/records.json":
+			switch query.Get("host") {
+			case "api":
+				fmt.Fprint(w, `{"1":{"id":"1","type":"A","host":"api","record":"192.0.2.1","ttl":"60","status":1},"2":{"id":"2","type":"AAAA","host":"api","record":"2001:db8::1","ttl":"60","status":0}}`)
+			case "www":
+				fmt.Fprint(w, `{"3":{"id":"3","type":"CNAME","host":"www","record":"api.example.com","ttl":"60","status":1}}`)
+			default:
+				t.Errorf("Unexpected host filter %q", query.Get("host"))
+			}
+		case "/dns/change-record-status.json":
+			calls = append(calls, query.Get("record-id")+" "+query.Get("status"))
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"Done."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	changed, err := UseClient("id", "", "password").ChangeRecordsStatus(t.Context(), "example.com", false,
+		RecordFilter{Host: "api"}, RecordFilter{Host: "www", Type: "cname"})
+	if err != nil {
+		t.Fatalf("Failed to change records status: %v", err)
+	}
+
+	slices.Sort(calls)
+	expected := []string{"1 0", "3 0"}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+	for _, rec := range changed {
+		if data, ok := recordDataOf(rec); !ok || data.Active {
+			t.Errorf("Expected %+v to be inactive", rec)
+		}
+	}
+}
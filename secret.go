@@ -0,0 +1,53 @@
+package cloudns
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretFile reads a secret from a file, and reads it again only once the
+// file changed. Mounted secrets are usually replaced as a whole, which
+// changes their modification time.
+type secretFile struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	size    int64
+	secret  string
+}
+
+// read returns the secret held by the file at path, without surrounding
+// whitespace such as a trailing newline.
+func (f *secretFile) read(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.path == path && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+		return f.secret, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %q is empty", path)
+	}
+
+	f.path = path
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.secret = secret
+
+	return secret, nil
+}
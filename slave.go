@@ -0,0 +1,138 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// CreateSlaveZone registers the zone as a new slave (secondary) zone in the
+// account, see CreateZone. ClouDNS then transfers the records of the zone
+// from the given master server.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to register
+//   - masterIP: IP address of the master server
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) CreateSlaveZone(ctx context.Context, zone string, masterIP netip.Addr) error {
+	return c.CreateZone(ctx, zone, CreateZoneOptions{Type: ZoneTypeSlave, MasterIP: masterIP})
+}
+
+// ListMasterServers returns the master servers of a slave zone, using
+// master-servers.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The slave zone (domain) to list the master servers of
+//
+// Returns:
+//   - []MasterServer: The master servers of the zone
+//   - error: Any error that occurred during the operation
+func (c *Client) ListMasterServers(ctx context.Context, zone string) ([]MasterServer, error) {
+	endpoint := apiBaseUrl.JoinPath("master-servers.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	// Master servers are returned keyed by their ID, like records
+	var result map[string]MasterServer
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return nil, err
+	}
+
+	servers := slices.Collect(maps.Values(result))
+	slices.SortFunc(servers, func(a, b MasterServer) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	return servers, nil
+}
+
+// AddMasterServer adds a master server to a slave zone, using
+// add-master-server.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The slave zone (domain) to add the master server to
+//   - masterIP: IP address of the master server
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) AddMasterServer(ctx context.Context, zone string, masterIP netip.Addr) error {
+	endpoint := apiBaseUrl.JoinPath("add-master-server.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"master-ip":   masterIP.String(),
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// DeleteMasterServer removes a master server from a slave zone, using
+// delete-master-server.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The slave zone (domain) to remove the master server from
+//   - masterId: ID of the master server, as returned by ListMasterServers
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) DeleteMasterServer(ctx context.Context, zone string, masterId string) error {
+	endpoint := apiBaseUrl.JoinPath("delete-master-server.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"master-id":   masterId,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// ChangeMasterServer makes masterIP the only master server of a slave zone.
+// The new master server is added before the other ones are removed, so the
+// zone keeps a master server should any step fail.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The slave zone (domain) to change the master server of
+//   - masterIP: IP address of the new master server
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) ChangeMasterServer(ctx context.Context, zone string, masterIP netip.Addr) error {
+	servers, err := c.ListMasterServers(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("failed to list master servers: %w", err)
+	}
+
+	found := false
+	for _, server := range servers {
+		if addr, err := netip.ParseAddr(server.Host); err == nil && addr == masterIP {
+			found = true
+		}
+	}
+	if !found {
+		if err := c.AddMasterServer(ctx, zone, masterIP); err != nil {
+			return fmt.Errorf("failed to add master server %s: %w", masterIP, err)
+		}
+	}
+
+	var errs []error
+	for _, server := range servers {
+		if addr, err := netip.ParseAddr(server.Host); err == nil && addr == masterIP {
+			continue
+		}
+		if err := c.DeleteMasterServer(ctx, zone, server.Id); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete master server %s: %w", server.Host, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
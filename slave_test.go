@@ -0,0 +1,38 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestChangeMasterServer(t *testing.T) {
+	var calls []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/dns/master-servers.json":
+			fmt.Fprint(w, `{"1":{"id":"1","host":"192.0.2.1"},"2":{"id":"2","host":"192.0.2.2"}}`)
+			return
+		case "/dns/add-master-server.json":
+			calls = append(calls, "add "+query.Get("master-ip"))
+		case "/dns/delete-master-server.json":
+			calls = append(calls, "delete "+query.Get("master-id"))
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"status":"Success","statusDescription":"Done."}`)
+	})
+
+	err := UseClient("id", "", "password").ChangeMasterServer(t.Context(), "example.com", netip.MustParseAddr("192.0.2.3"))
+	if err != nil {
+		t.Fatalf("Failed to change master server: %v", err)
+	}
+
+	expected := []string{"add 192.0.2.3", "delete 1", "delete 2"}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
@@ -0,0 +1,46 @@
+package cloudns
+
+import "strings"
+
+// spfAsTXT rewrites legacy SPF records as TXT records, which is how SPF
+// policies are published nowadays. SPF records holding the same policy as a
+// TXT record of the same name are dropped, so each policy appears once.
+func spfAsTXT(records []ApiDnsRecord) []ApiDnsRecord {
+	type policy struct {
+		host, data string
+	}
+	txt := make(map[policy]bool)
+	for _, record := range records {
+		if strings.EqualFold(record.Type, "TXT") {
+			txt[policy{strings.ToLower(record.Host), record.Record}] = true
+		}
+	}
+
+	ret := make([]ApiDnsRecord, 0, len(records))
+	for _, record := range records {
+		if strings.EqualFold(record.Type, "SPF") {
+			if txt[policy{strings.ToLower(record.Host), record.Record}] {
+				continue
+			}
+			record.Type = "TXT"
+		}
+		ret = append(ret, record)
+	}
+
+	return ret
+}
+
+// mergeSPFIntoTXT files the existing SPF records under the TXT record set of
+// the same name, so that desired TXT records are matched against them. The
+// records keep their SPF type, so that modifying them does not change it.
+func mergeSPFIntoTXT(existing map[nameAndType][]ApiDnsRecord) {
+	for key, records := range existing {
+		if key.type_ != "SPF" {
+			continue
+		}
+
+		txtKey := nameAndType{name: key.name, type_: "TXT"}
+		existing[txtKey] = append(existing[txtKey], records...)
+		delete(existing, key)
+	}
+}
@@ -0,0 +1,60 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestSPFAsTXT(t *testing.T) {
+	var modified []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/dns/records.json":
+			fmt.Fprint(w, `{
+				"1": {"id": "1", "type": "SPF", "host": "", "record": "v=spf1 mx -all", "ttl": "3600", "status": 1},
+				"2": {"id": "2", "type": "TXT", "host": "", "record": "v=spf1 mx -all", "ttl": "3600", "status": 1},
+				"3": {"id": "3", "type": "SPF", "host": "mail", "record": "v=spf1 a -all", "ttl": "3600", "status": 1}
+			}`)
+		case "/dns/get-available-ttl.json":
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","SPF","TXT"]`)
+		case "/dns/mod-record.json":
+			modified = append(modified, query.Get("record-id")+" "+query.Get("record-type")+" "+query.Get("record"))
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was modified successfully."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", SPFAsTXT: true}
+	records, err := provider.GetRecords(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected the SPF copy of the apex policy to be dropped, got %+v", records)
+	}
+	for _, rec := range records {
+		if _, ok := rec.(libdns.TXT); !ok {
+			t.Errorf("Expected a TXT record, got %+v", rec)
+		}
+	}
+
+	_, err = provider.SetRecords(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "mail", TTL: time.Hour, Text: "v=spf1 a mx -all"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set records: %v", err)
+	}
+	if len(modified) != 1 || modified[0] != "3 SPF v=spf1 a mx -all" {
+		t.Errorf("Expected the SPF record to be modified in place, got %v", modified)
+	}
+}
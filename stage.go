@@ -2,7 +2,9 @@ package cloudns
 
 import (
 	"iter"
+	"maps"
 	"slices"
+	"strings"
 
 	"github.com/libdns/libdns"
 )
@@ -19,41 +21,129 @@ type operation int
 type operationEntry struct {
 	op     operation
 	record ApiDnsRecord
+	// status, if set, is the active state the record is switched to once
+	// the operation is done.
+	status *bool
+}
+
+// desiredStatus returns the active state requested through the RecordData of
+// the desired record, or nil if the caller left the status unspecified.
+func desiredStatus(rec libdns.Record) *bool {
+	data, ok := recordDataOf(rec)
+	if !ok {
+		return nil
+	}
+
+	return &data.Active
 }
 
 func compareIDlessRecord(a ApiDnsRecord, b ApiDnsRecord) bool {
-	return a.Type == b.Type &&
-		a.Host == b.Host &&
-		a.Record == b.Record &&
+	return canonicalRecordType(a.Type) == canonicalRecordType(b.Type) &&
+		strings.EqualFold(a.Host, b.Host) &&
+		sameRecordData(a, b) &&
 		a.Ttl == b.Ttl &&
 		a.CAAFlag == b.CAAFlag &&
 		a.CAAType == b.CAAType &&
 		a.Priority == b.Priority &&
 		a.Port == b.Port &&
-		a.Weight == b.Weight
+		a.Weight == b.Weight &&
+		a.Algorithm == b.Algorithm &&
+		a.FpType == b.FpType &&
+		a.KeyTag == b.KeyTag &&
+		a.DigestType == b.DigestType &&
+		a.TLSAUsage == b.TLSAUsage &&
+		a.TLSASelector == b.TLSASelector &&
+		a.TLSAMatchingType == b.TLSAMatchingType &&
+		a.GeoDNSLocation == b.GeoDNSLocation
+}
+
+// sameRecordData compares the Record field of two records of the same type.
+// TXT data and the data of the types listed in genericRDATA is compared by
+// its logical value, as the same value can be quoted in several ways, and
+// targets regardless of their trailing dot and case.
+func sameRecordData(a ApiDnsRecord, b ApiDnsRecord) bool {
+	switch canonicalRecordType(a.Type) {
+	case "TXT", "SPF":
+		return decodeTXT(a.Record) == decodeTXT(b.Record)
+	case "CNAME", "MX", "NS", "SRV":
+		return strings.EqualFold(normalizeTarget(a.Record), normalizeTarget(b.Record))
+	}
+
+	if aParams, ok := rdataParameters(a.Type, a.Record); ok {
+		bParams, ok := rdataParameters(b.Type, b.Record)
+		return ok && maps.Equal(aParams, bParams)
+	}
+
+	return a.Record == b.Record
+}
+
+// fromDesiredRecord translates a desired record into the upstream API object
+// replacing an existing one. The ClouDNS specific details the caller left
+// unspecified, by not passing a RecordData, are kept from the existing record.
+func fromDesiredRecord(desiredRR libdns.Record, existingRR ApiDnsRecord, ttls []int) ApiDnsRecord {
+	ret := fromLibdnsRecord(desiredRR, existingRR.Id, ttls)
+	ret.Status = existingRR.Status
+	// Legacy SPF records matched against TXT ones keep their type
+	if strings.EqualFold(existingRR.Type, "SPF") && ret.Type == "TXT" {
+		ret.Type = existingRR.Type
+	}
+	if _, ok := recordDataOf(desiredRR); !ok {
+		ret.GeoDNSLocation = existingRR.GeoDNSLocation
+	}
+
+	return ret
 }
 
 // createUpdateOperations processes an existing rrset and a new rrset and comes
-// up with a set of operations to sync them. This could be a lot better,
-// since we'll generate a bunch of update operations if there's a new
-// entry in the middle of the list or if the lists are not sorted.
-func createUpdateOperations(existingRRSet []ApiDnsRecord, desiredRRSet []libdns.RR, deleted map[ApiDnsRecord]bool) []operationEntry {
-	existingIter, existingStop := iter.Pull(slices.Values(existingRRSet))
+// up with a set of operations to sync them. Desired entries are first matched
+// against existing entries with the same content, which are left untouched.
+// Only the remaining entries are paired up and modified, with any leftovers
+// being added or deleted.
+func createUpdateOperations(existingRRSet []ApiDnsRecord, desiredRRSet []libdns.Record, deleted map[ApiDnsRecord]bool, ttls []int) []operationEntry {
+	ret := make([]operationEntry, 0, len(existingRRSet)+len(desiredRRSet))
+
+	// Match entries by content first
+	matched := make([]bool, len(existingRRSet))
+	unmatchedDesired := make([]libdns.Record, 0, len(desiredRRSet))
+	for _, desiredRR := range desiredRRSet {
+		idx := -1
+		for i, existingRR := range existingRRSet {
+			if !matched[i] && compareIDlessRecord(existingRR, fromDesiredRecord(desiredRR, existingRR, ttls)) {
+				idx = i
+				break
+			}
+		}
+
+		if idx < 0 {
+			unmatchedDesired = append(unmatchedDesired, desiredRR)
+			continue
+		}
+
+		matched[idx] = true
+		if entry, ok := pairOperation(existingRRSet[idx], desiredRR, ttls); ok {
+			ret = append(ret, entry)
+		}
+	}
+
+	unmatchedExisting := make([]ApiDnsRecord, 0, len(existingRRSet))
+	for idx, existingRR := range existingRRSet {
+		if !matched[idx] {
+			unmatchedExisting = append(unmatchedExisting, existingRR)
+		}
+	}
+
+	// Then pair up whatever is left
+	existingIter, existingStop := iter.Pull(slices.Values(unmatchedExisting))
 	defer existingStop()
-	desiredIter, desiredStop := iter.Pull(slices.Values(desiredRRSet))
+	desiredIter, desiredStop := iter.Pull(slices.Values(unmatchedDesired))
 	defer desiredStop()
-	ret := make([]operationEntry, 0, max(len(existingRRSet)+len(desiredRRSet)))
 
 	for {
 		existingRR, existingOk := existingIter()
 		desiredRR, desiredOk := desiredIter()
 		if existingOk && desiredOk {
-			modifiedRR := fromLibdnsRecord(desiredRR, existingRR.Id)
-			if !compareIDlessRecord(existingRR, modifiedRR) {
-				ret = append(ret, operationEntry{
-					op:     modifyRecord,
-					record: modifiedRR,
-				})
+			if entry, ok := pairOperation(existingRR, desiredRR, ttls); ok {
+				ret = append(ret, entry)
 			}
 		}
 
@@ -62,10 +152,7 @@ func createUpdateOperations(existingRRSet []ApiDnsRecord, desiredRRSet []libdns.
 		}
 
 		if !existingOk && desiredOk {
-			ret = append(ret, operationEntry{
-				op:     addRecord,
-				record: fromLibdnsRecord(desiredRR, ""),
-			})
+			ret = append(ret, newAddOperation(desiredRR, ttls))
 		}
 
 		if !existingOk && !desiredOk {
@@ -76,7 +163,37 @@ func createUpdateOperations(existingRRSet []ApiDnsRecord, desiredRRSet []libdns.
 	return ret
 }
 
-func makeOperationList(desired map[nameAndType][]libdns.RR, existing map[nameAndType][]ApiDnsRecord) []operationEntry {
+// pairOperation creates the operation turning an existing record into the
+// desired one. It returns false if the record is already as desired.
+func pairOperation(existingRR ApiDnsRecord, desiredRR libdns.Record, ttls []int) (operationEntry, bool) {
+	modifiedRR := fromDesiredRecord(desiredRR, existingRR, ttls)
+
+	entry := operationEntry{op: nop, record: modifiedRR}
+	if !compareIDlessRecord(existingRR, modifiedRR) {
+		entry.op = modifyRecord
+	}
+	if status := desiredStatus(desiredRR); status != nil && *status != existingRR.Active() {
+		entry.status = status
+	}
+
+	return entry, entry.op != nop || entry.status != nil
+}
+
+// newAddOperation creates the operation adding the desired record. Records are
+// always created active, so a disabled record needs a status change afterwards.
+func newAddOperation(desiredRR libdns.Record, ttls []int) operationEntry {
+	entry := operationEntry{
+		op:     addRecord,
+		record: fromLibdnsRecord(desiredRR, "", ttls),
+	}
+	if status := desiredStatus(desiredRR); status != nil && !*status {
+		entry.status = status
+	}
+
+	return entry
+}
+
+func makeOperationList(desired map[nameAndType][]libdns.Record, existing map[nameAndType][]ApiDnsRecord, ttls []int) []operationEntry {
 	ret := make([]operationEntry, 0, len(desired))
 	deleted := make(map[ApiDnsRecord]bool)
 
@@ -86,10 +203,7 @@ func makeOperationList(desired map[nameAndType][]libdns.RR, existing map[nameAnd
 		if len(existingRRSet) == 0 {
 			// create
 			for _, desiredRR := range desiredRRSet {
-				ret = append(ret, operationEntry{
-					op:     addRecord,
-					record: fromLibdnsRecord(desiredRR, ""),
-				})
+				ret = append(ret, newAddOperation(desiredRR, ttls))
 			}
 		} else {
 			// update
@@ -99,6 +213,7 @@ func makeOperationList(desired map[nameAndType][]libdns.RR, existing map[nameAnd
 					existingRRSet,
 					desiredRRSet,
 					deleted,
+					ttls,
 				)...,
 			)
 		}
@@ -115,3 +230,24 @@ func makeOperationList(desired map[nameAndType][]libdns.RR, existing map[nameAnd
 	}
 	return append(ops, ret...)
 }
+
+// groupOperations splits the operation list into the operations on each
+// name, keeping their order. Operations on different names are independent
+// of each other, while e.g. a CNAME must be deleted before other records can
+// be added with its name.
+func groupOperations(ops []operationEntry) [][]operationEntry {
+	var groups [][]operationEntry
+	index := make(map[string]int)
+	for _, op := range ops {
+		name := newNameAndType(op.record.Host, "").name
+		idx, ok := index[name]
+		if !ok {
+			idx = len(groups)
+			index[name] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], op)
+	}
+
+	return groups
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type makeOperationListIn struct {
-	desired  map[nameAndType][]libdns.RR
+	desired  map[nameAndType][]libdns.Record
 	existing map[nameAndType][]ApiDnsRecord
 }
 
@@ -26,9 +26,9 @@ var makeOperationListTests = []struct {
 	{
 		name: "remove rrset entry",
 		in: makeOperationListIn{
-			desired: map[nameAndType][]libdns.RR{
+			desired: map[nameAndType][]libdns.Record{
 				{name: "example.com", type_: "A"}: {
-					{
+					libdns.RR{
 						Name: "example.com",
 						TTL:  time.Duration(60) * time.Second,
 						Data: "192.0.2.3",
@@ -81,7 +81,7 @@ var makeOperationListTests = []struct {
 	{
 		name: "only touch one rrset",
 		in: makeOperationListIn{
-			desired: map[nameAndType][]libdns.RR{
+			desired: map[nameAndType][]libdns.Record{
 				{name: "a.example.com", type_: "AAAA"}: {
 					libdns.RR{
 						Name: "a.example.com",
@@ -154,9 +154,9 @@ var makeOperationListTests = []struct {
 	{
 		name: "add rrset",
 		in: makeOperationListIn{
-			desired: map[nameAndType][]libdns.RR{
+			desired: map[nameAndType][]libdns.Record{
 				{name: "foo.example.com", type_: "A"}: {
-					{
+					libdns.RR{
 						Name: "foo.example.com",
 						TTL:  time.Duration(60) * time.Second,
 						Data: "192.0.2.3",
@@ -195,12 +195,159 @@ var makeOperationListTests = []struct {
 			},
 		},
 	},
+	{
+		name: "insert in the middle of an rrset",
+		in: makeOperationListIn{
+			desired: map[nameAndType][]libdns.Record{
+				{name: "example.com", type_: "A"}: {
+					libdns.RR{
+						Name: "example.com",
+						TTL:  time.Duration(60) * time.Second,
+						Data: "192.0.2.1",
+						Type: "A",
+					},
+					libdns.RR{
+						Name: "example.com",
+						TTL:  time.Duration(60) * time.Second,
+						Data: "192.0.2.2",
+						Type: "A",
+					},
+					libdns.RR{
+						Name: "example.com",
+						TTL:  time.Duration(60) * time.Second,
+						Data: "192.0.2.3",
+						Type: "A",
+					},
+				},
+			},
+			existing: map[nameAndType][]ApiDnsRecord{
+				{name: "example.com", type_: "A"}: {
+					{
+						Id:     "1",
+						Host:   "example.com",
+						Type:   "A",
+						Record: "192.0.2.1",
+						Ttl:    "60",
+					},
+					{
+						Id:     "3",
+						Host:   "example.com",
+						Type:   "A",
+						Record: "192.0.2.3",
+						Ttl:    "60",
+					},
+					{
+						Id:     "4",
+						Host:   "example.com",
+						Type:   "A",
+						Record: "192.0.2.4",
+						Ttl:    "60",
+					},
+				},
+			},
+		},
+		out: []operationEntry{
+			{
+				op: modifyRecord,
+				record: ApiDnsRecord{
+					Id:     "4",
+					Host:   "example.com",
+					Type:   "A",
+					Record: "192.0.2.2",
+					Ttl:    "60",
+				},
+			},
+		},
+	},
+	{
+		name: "deactivate record",
+		in: makeOperationListIn{
+			desired: map[nameAndType][]libdns.Record{
+				{name: "example.com", type_: "TXT"}: {
+					libdns.TXT{
+						Name:         "example.com",
+						TTL:          time.Duration(60) * time.Second,
+						Text:         "hello",
+						ProviderData: RecordData{Active: false},
+					},
+				},
+			},
+			existing: map[nameAndType][]ApiDnsRecord{
+				{name: "example.com", type_: "TXT"}: {
+					{
+						Id:     "1",
+						Host:   "example.com",
+						Type:   "TXT",
+						Record: "hello",
+						Ttl:    "60",
+						Status: 1,
+					},
+				},
+			},
+		},
+		out: []operationEntry{
+			{
+				op: nop,
+				record: ApiDnsRecord{
+					Id:     "1",
+					Host:   "example.com",
+					Type:   "TXT",
+					Record: "hello",
+					Ttl:    "60",
+					Status: 1,
+				},
+				status: new(bool),
+			},
+		},
+	},
+	{
+		name: "add inactive record",
+		in: makeOperationListIn{
+			desired: map[nameAndType][]libdns.Record{
+				{name: "example.com", type_: "TXT"}: {
+					libdns.TXT{
+						Name:         "example.com",
+						TTL:          time.Duration(60) * time.Second,
+						Text:         "hello",
+						ProviderData: RecordData{Active: false},
+					},
+					libdns.TXT{
+						Name:         "example.com",
+						TTL:          time.Duration(60) * time.Second,
+						Text:         "world",
+						ProviderData: RecordData{Active: true},
+					},
+				},
+			},
+		},
+		out: []operationEntry{
+			{
+				op: addRecord,
+				record: ApiDnsRecord{
+					Host:   "example.com",
+					Type:   "TXT",
+					Record: "hello",
+					Ttl:    "60",
+				},
+				status: new(bool),
+			},
+			{
+				op: addRecord,
+				record: ApiDnsRecord{
+					Host:   "example.com",
+					Type:   "TXT",
+					Record: "world",
+					Ttl:    "60",
+				},
+			},
+		},
+	},
 }
 
 func TestMakeOperationList(t *testing.T) {
 	for _, tt := range makeOperationListTests {
 		t.Run(tt.name, func(t *testing.T) {
-			out := makeOperationList(tt.in.desired, tt.in.existing)
+			out := makeOperationList(tt.in.desired, tt.in.existing, nil)
 			if !reflect.DeepEqual(out, tt.out) {
 				t.Errorf("actual: %+v\n\nexpected: %+v", out, tt.out)
 			}
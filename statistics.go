@@ -0,0 +1,140 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// QueryStat is the number of DNS queries a zone received during a period.
+type QueryStat struct {
+	// Start is the beginning of the period, in UTC
+	Start   time.Time
+	Queries int
+}
+
+// GetHourlyQueryStats returns the number of queries the zone received during
+// each hour of the given day, using statistics-hourly.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the statistics of
+//   - day: The day to get the statistics of, only its date is used
+//
+// Returns:
+//   - []QueryStat: The query counts, one per hour, in chronological order
+//   - error: Any error that occurred during the operation
+func (c *Client) GetHourlyQueryStats(ctx context.Context, zone string, day time.Time) ([]QueryStat, error) {
+	year, month, date := day.Date()
+	params := map[string]string{
+		"domain-name": zone,
+		"year":        strconv.Itoa(year),
+		"month":       strconv.Itoa(int(month)),
+		"day":         strconv.Itoa(date),
+	}
+
+	return c.getQueryStats(ctx, "statistics-hourly.json", params, func(hour int) time.Time {
+		return time.Date(year, month, date, hour, 0, 0, 0, time.UTC)
+	})
+}
+
+// GetDailyQueryStats returns the number of queries the zone received during
+// each day of the given month, using statistics-daily.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the statistics of
+//   - year: The year of the month
+//   - month: The month to get the statistics of
+//
+// Returns:
+//   - []QueryStat: The query counts, one per day, in chronological order
+//   - error: Any error that occurred during the operation
+func (c *Client) GetDailyQueryStats(ctx context.Context, zone string, year int, month time.Month) ([]QueryStat, error) {
+	params := map[string]string{
+		"domain-name": zone,
+		"year":        strconv.Itoa(year),
+		"month":       strconv.Itoa(int(month)),
+	}
+
+	return c.getQueryStats(ctx, "statistics-daily.json", params, func(day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	})
+}
+
+// GetMonthlyQueryStats returns the number of queries the zone received during
+// each month of the given year, using statistics-monthly.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the statistics of
+//   - year: The year to get the statistics of
+//
+// Returns:
+//   - []QueryStat: The query counts, one per month, in chronological order
+//   - error: Any error that occurred during the operation
+func (c *Client) GetMonthlyQueryStats(ctx context.Context, zone string, year int) ([]QueryStat, error) {
+	params := map[string]string{
+		"domain-name": zone,
+		"year":        strconv.Itoa(year),
+	}
+
+	return c.getQueryStats(ctx, "statistics-monthly.json", params, func(month int) time.Time {
+		return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	})
+}
+
+// getQueryStats fetches query statistics, which are returned as an object
+// mapping the hour, day or month number to the number of queries. The start
+// function turns that number into the beginning of the period.
+func (c *Client) getQueryStats(ctx context.Context, path string, params map[string]string, start func(int) time.Time) ([]QueryStat, error) {
+	endpoint := apiBaseUrl.JoinPath(path)
+
+	var result map[string]flexInt
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return nil, err
+	}
+
+	stats := make([]QueryStat, 0, len(result))
+	for period, queries := range result {
+		number, err := strconv.Atoi(period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid statistics period %q: %w", period, err)
+		}
+
+		stats = append(stats, QueryStat{Start: start(number), Queries: int(queries)})
+	}
+
+	slices.SortFunc(stats, func(a, b QueryStat) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return stats, nil
+}
+
+// GetAPIUsage returns the number of API requests made with the credentials of
+// the client along with their limit, using get-api-usage.json. Operators can
+// use it to alert before automation starts getting throttled.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//
+// Returns:
+//   - APIUsage: The API usage of the credentials
+//   - error: Any error that occurred during the operation
+func (c *Client) GetAPIUsage(ctx context.Context) (APIUsage, error) {
+	endpoint := apiBaseUrl.JoinPath("get-api-usage.json")
+
+	var result struct {
+		Requests flexInt `json:"requests"`
+		Limit    flexInt `json:"limit"`
+		Period   string  `json:"period"`
+	}
+	if err := c.performGetJSONRequest(ctx, endpoint, nil, &result); err != nil {
+		return APIUsage{}, err
+	}
+
+	return APIUsage{Requests: int(result.Requests), Limit: int(result.Limit), Period: result.Period}, nil
+}
@@ -0,0 +1,52 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetDailyQueryStats(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/statistics-daily.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if year, month := r.URL.Query().Get("year"), r.URL.Query().Get("month"); year != "2025" || month != "2" {
+			t.Errorf("Unexpected period %s-%s", year, month)
+		}
+		fmt.Fprint(w, `{"2":"20","1":10,"10":100}`)
+	})
+
+	stats, err := UseClient("id", "", "password").GetDailyQueryStats(t.Context(), "example.com", 2025, time.February)
+	if err != nil {
+		t.Fatalf("Failed to get statistics: %v", err)
+	}
+
+	expected := []QueryStat{
+		{Start: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), Queries: 10},
+		{Start: time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC), Queries: 20},
+		{Start: time.Date(2025, time.February, 10, 0, 0, 0, 0, time.UTC), Queries: 100},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("actual: %+v\n\nexpected: %+v", stats, expected)
+	}
+}
+
+func TestGetAPIUsage(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/get-api-usage.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"requests":"4200","limit":5000,"period":"hour"}`)
+	})
+
+	usage, err := UseClient("id", "", "password").GetAPIUsage(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get API usage: %v", err)
+	}
+	if usage != (APIUsage{Requests: 4200, Limit: 5000, Period: "hour"}) || usage.Remaining() != 800 {
+		t.Errorf("Unexpected API usage %+v", usage)
+	}
+}
@@ -0,0 +1,105 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// TestStressSharedProvider hammers a single Provider from many goroutines,
+// sharing its cache, rate limiter and HTTP client, while its credentials are
+// rotated. Run it with -race to check the thread-safety of the provider.
+func TestStressSharedProvider(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping stress test in short mode")
+	}
+
+	const workers = 32
+	const iterations = 20
+
+	zone := &fakeZone{t: t, records: map[string]ApiDnsRecord{}}
+	var requests atomic.Int64
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		zone.ServeHTTP(w, r)
+	})
+
+	provider, err := NewProvider(
+		WithCredentials("id", "password"),
+		WithCache(time.Minute),
+		WithRateLimit(100000),
+		WithHTTPClient(&http.Client{Timeout: 10 * time.Second}),
+		WithOperationTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for iteration := range iterations {
+				name := fmt.Sprintf("w%d-%d", worker, iteration)
+				shared := "shared" + strconv.Itoa(worker%4)
+
+				switch iteration % 4 {
+				case 0:
+					records := []libdns.Record{libdns.TXT{Name: name, TTL: time.Minute, Text: "appended"}}
+					if _, err := provider.AppendRecords(t.Context(), "example.com", records); err != nil {
+						t.Errorf("Failed to append records: %v", err)
+					}
+				case 1:
+					records := []libdns.Record{libdns.TXT{Name: shared, TTL: time.Minute, Text: name}}
+					if _, err := provider.SetRecords(t.Context(), "example.com.", records); err != nil {
+						t.Errorf("Failed to set records: %v", err)
+					}
+				case 2:
+					if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+						t.Errorf("Failed to get records: %v", err)
+					}
+				case 3:
+					records := []libdns.Record{libdns.TXT{Name: fmt.Sprintf("w%d-%d", worker, iteration-3)}}
+					if _, err := provider.DeleteRecords(t.Context(), "Example.com", records); err != nil {
+						t.Errorf("Failed to delete records: %v", err)
+					}
+				}
+
+				if iteration%10 == 0 {
+					provider.SetCredentials("id", "", "password")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The appended records were all deleted again, and each shared RRset
+	// holds the record set last
+	zone.mu.Lock()
+	defer zone.mu.Unlock()
+	names := make(map[string]int)
+	for _, record := range zone.records {
+		names[record.Host]++
+	}
+	if len(names) != 4 {
+		t.Errorf("Expected the 4 shared RRsets only, got %v", names)
+	}
+	for name, count := range names {
+		if count != 1 {
+			t.Errorf("Expected a single record named %q, got %d", name, count)
+		}
+	}
+
+	if err := provider.Close(); err != nil {
+		t.Errorf("Failed to close provider: %v", err)
+	}
+	t.Logf("Served %d requests", requests.Load())
+}
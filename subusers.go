@@ -0,0 +1,201 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// subUsersEndpoint returns the URL of an endpoint of the sub-users API, which
+// lives next to the DNS API.
+func subUsersEndpoint(name string) *url.URL {
+	return apiBaseUrl.JoinPath("..", "sub-users", name)
+}
+
+// SubUser is an API sub-user of the account. Its ID is used as the
+// sub-auth-id of the credentials, e.g. through UseClient.
+type SubUser struct {
+	Id     string
+	Active bool
+
+	// ZonesLimit is the number of zones that can be delegated to the sub-user
+	ZonesLimit int
+}
+
+// subUserResult is a sub-user as returned by the API.
+type subUserResult struct {
+	Id     flexString `json:"id"`
+	Status flexInt    `json:"status"`
+	Zones  flexInt    `json:"zones"`
+}
+
+func (u subUserResult) toSubUser() SubUser {
+	return SubUser{Id: string(u.Id), Active: u.Status == 1, ZonesLimit: int(u.Zones)}
+}
+
+// CreateSubUser creates a new API sub-user, using sub-users/add-user.json.
+// The sub-user has no access to any zone until zones are delegated to it with
+// DelegateZone.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - password: The password of the new sub-user
+//   - zonesLimit: The number of zones that can be delegated to the sub-user
+//
+// Returns:
+//   - SubUser: The created sub-user
+//   - error: Any error that occurred during the operation
+func (c *Client) CreateSubUser(ctx context.Context, password string, zonesLimit int) (SubUser, error) {
+	endpoint := subUsersEndpoint("add-user.json")
+	params := map[string]string{
+		"password":      password,
+		"zones":         strconv.Itoa(zonesLimit),
+		"mail-forwards": "0",
+		"status":        "1",
+	}
+
+	id, err := c.performCreateRequest(ctx, endpoint, params)
+	if err != nil {
+		return SubUser{}, err
+	}
+
+	return SubUser{Id: id, Active: true, ZonesLimit: zonesLimit}, nil
+}
+
+// ListSubUsers returns the API sub-users of the account, using
+// sub-users/list-sub-users.json. All pages of results are fetched one after
+// the other.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//
+// Returns:
+//   - []SubUser: The sub-users of the account
+//   - error: Any error that occurred during the operation
+func (c *Client) ListSubUsers(ctx context.Context) ([]SubUser, error) {
+	const rowsPerPage = 100
+
+	endpoint := subUsersEndpoint("list-sub-users.json")
+	params := map[string]string{
+		"rows-per-page": strconv.Itoa(rowsPerPage),
+	}
+
+	var users []SubUser
+	for page := 1; ; page++ {
+		params["page"] = strconv.Itoa(page)
+
+		var result []subUserResult
+		if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+			return nil, fmt.Errorf("failed to list sub-users on page %d: %w", page, err)
+		}
+
+		for _, user := range result {
+			users = append(users, user.toSubUser())
+		}
+
+		// A short page is the last one
+		if len(result) < rowsPerPage {
+			return users, nil
+		}
+	}
+}
+
+// DeleteSubUser deletes an API sub-user, using sub-users/delete.json. Its
+// credentials stop working immediately.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - id: ID of the sub-user to delete
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) DeleteSubUser(ctx context.Context, id string) error {
+	endpoint := subUsersEndpoint("delete.json")
+	params := map[string]string{
+		"id": id,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// ChangeSubUserPassword sets the password of an API sub-user, using
+// sub-users/change-password.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - id: ID of the sub-user
+//   - password: The new password of the sub-user
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) ChangeSubUserPassword(ctx context.Context, id string, password string) error {
+	endpoint := subUsersEndpoint("change-password.json")
+	params := map[string]string{
+		"id":       id,
+		"password": password,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// DelegateZone gives an API sub-user access to a zone of the account, using
+// sub-users/delegate-zone.json. It counts against the zones limit of the
+// sub-user.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - id: ID of the sub-user
+//   - zone: The DNS zone (domain) to delegate
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) DelegateZone(ctx context.Context, id string, zone string) error {
+	endpoint := subUsersEndpoint("delegate-zone.json")
+	params := map[string]string{
+		"id":   id,
+		"zone": zone,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// RemoveZoneDelegation revokes the access of an API sub-user to a zone, using
+// sub-users/remove-zone-delegation.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - id: ID of the sub-user
+//   - zone: The DNS zone (domain) to revoke access to
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) RemoveZoneDelegation(ctx context.Context, id string, zone string) error {
+	endpoint := subUsersEndpoint("remove-zone-delegation.json")
+	params := map[string]string{
+		"id":   id,
+		"zone": zone,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// ChangeSubUserZonesLimit sets the number of zones that can be delegated to
+// an API sub-user, using sub-users/change-zones-limit.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - id: ID of the sub-user
+//   - zonesLimit: The new zones limit of the sub-user
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) ChangeSubUserZonesLimit(ctx context.Context, id string, zonesLimit int) error {
+	endpoint := subUsersEndpoint("change-zones-limit.json")
+	params := map[string]string{
+		"id":    id,
+		"zones": strconv.Itoa(zonesLimit),
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
@@ -0,0 +1,116 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSubUsers(t *testing.T) {
+	users := map[string]string{}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/sub-users/add-user.json":
+			if query.Get("zones") != "5" {
+				t.Errorf("Unexpected zones limit %q", query.Get("zones"))
+			}
+			users["17"] = query.Get("password")
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The sub user was created successfully.","data":{"id":17}}`)
+		case "/sub-users/list-sub-users.json":
+			fmt.Fprint(w, `[{"id":"17","status":"1","zones":"5"},{"id":18,"status":0,"zones":1}]`)
+		case "/sub-users/change-password.json":
+			users[query.Get("id")] = query.Get("password")
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The password was changed successfully."}`)
+		case "/sub-users/delete.json":
+			delete(users, query.Get("id"))
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The sub user was deleted successfully."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	c := UseClient("id", "", "password")
+	user, err := c.CreateSubUser(t.Context(), "initial", 5)
+	if err != nil {
+		t.Fatalf("Failed to create sub-user: %v", err)
+	}
+	if user != (SubUser{Id: "17", Active: true, ZonesLimit: 5}) {
+		t.Errorf("Unexpected sub-user %+v", user)
+	}
+
+	list, err := c.ListSubUsers(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to list sub-users: %v", err)
+	}
+	expected := []SubUser{{Id: "17", Active: true, ZonesLimit: 5}, {Id: "18", ZonesLimit: 1}}
+	if !reflect.DeepEqual(list, expected) {
+		t.Errorf("actual: %+v\n\nexpected: %+v", list, expected)
+	}
+
+	if err := c.ChangeSubUserPassword(t.Context(), user.Id, "rotated"); err != nil {
+		t.Errorf("Failed to change password: %v", err)
+	}
+	if users[user.Id] != "rotated" {
+		t.Errorf("Expected the password to be changed, got %q", users[user.Id])
+	}
+
+	if err := c.DeleteSubUser(t.Context(), user.Id); err != nil {
+		t.Errorf("Failed to delete sub-user: %v", err)
+	}
+	if len(users) != 0 {
+		t.Errorf("Expected the sub-user to be deleted, got %v", users)
+	}
+}
+
+func TestZoneDelegation(t *testing.T) {
+	delegated := map[string]bool{}
+	limit := ""
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("id") != "17" {
+			t.Errorf("Unexpected sub-user %q", query.Get("id"))
+		}
+
+		switch r.URL.Path {
+		case "/sub-users/delegate-zone.json":
+			if len(delegated) >= 1 {
+				fmt.Fprint(w, `{"status":"Failed","statusDescription":"The zones limit of the sub user is reached."}`)
+				return
+			}
+			delegated[query.Get("zone")] = true
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The zone was delegated successfully."}`)
+		case "/sub-users/remove-zone-delegation.json":
+			delete(delegated, query.Get("zone"))
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The delegation was removed successfully."}`)
+		case "/sub-users/change-zones-limit.json":
+			limit = query.Get("zones")
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The zones limit was changed successfully."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	c := UseClient("id", "", "password")
+	if err := c.DelegateZone(t.Context(), "17", "example.com"); err != nil {
+		t.Fatalf("Failed to delegate zone: %v", err)
+	}
+	if err := c.DelegateZone(t.Context(), "17", "example.net"); err == nil {
+		t.Errorf("Expected an error beyond the zones limit")
+	}
+
+	if err := c.ChangeSubUserZonesLimit(t.Context(), "17", 2); err != nil {
+		t.Errorf("Failed to change zones limit: %v", err)
+	}
+	if limit != "2" {
+		t.Errorf("Expected the zones limit to be 2, got %q", limit)
+	}
+
+	if err := c.RemoveZoneDelegation(t.Context(), "17", "example.com"); err != nil {
+		t.Errorf("Failed to remove delegation: %v", err)
+	}
+	if len(delegated) != 0 {
+		t.Errorf("Expected no delegated zones, got %v", delegated)
+	}
+}
@@ -0,0 +1,116 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/libdns/libdns"
+)
+
+// DefaultProtectedTypes are the record types SyncZone leaves alone unless
+// SyncOptions.ProtectedTypes says otherwise. The NS and SOA records of a
+// zone are managed by ClouDNS.
+var DefaultProtectedTypes = []string{"NS", "SOA"}
+
+// SyncOptions tune how SyncZone reconciles a zone.
+type SyncOptions struct {
+	// ProtectedTypes are the record types SyncZone neither adds, changes
+	// nor deletes. Desired records of these types are ignored.
+	// DefaultProtectedTypes if nil, and no type is protected if empty.
+	ProtectedTypes []string
+}
+
+// protects reports whether records of the type are left alone.
+func (o SyncOptions) protects(type_ string) bool {
+	protected := o.ProtectedTypes
+	if protected == nil {
+		protected = DefaultProtectedTypes
+	}
+
+	return slices.ContainsFunc(protected, func(protectedType string) bool {
+		return canonicalRecordType(protectedType) == canonicalRecordType(type_)
+	})
+}
+
+// SyncZone makes the zone hold exactly the desired records, e.g. to manage
+// DNS declaratively from a repository. Unlike SetRecords, which only touches
+// the RRsets it is given, RRsets of the zone missing from the desired records
+// are deleted, except for the ProtectedTypes of the options.
+//
+// As with SetRecords, all changes are attempted even if an error is
+// encountered, and no rollback is attempted. The returned audit entries
+// describe the changes that were made, deletions first.
+func (p *Provider) SyncZone(ctx context.Context, zone string, desired []libdns.Record, opts SyncOptions) ([]AuditEntry, error) {
+	ctx, cancel := p.withOperationTimeout(ctx)
+	defer cancel()
+	ctx = withOperation(ctx)
+
+	if p.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	zone = normalizeZone(zone)
+	if err := p.checkZone(zone); err != nil {
+		return nil, err
+	}
+
+	desired = slices.DeleteFunc(slices.Clone(desired), func(record libdns.Record) bool {
+		return opts.protects(record.RR().Type)
+	})
+	if err := validateRecords(desired); err != nil {
+		return nil, err
+	}
+
+	defer p.lockZone(zone)()
+	ctx = p.withRetryBudget(ctx)
+
+	c, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+	upstreamRecords, err := c.GetClouDNSRecords(ctx, zone)
+	if errors.Is(err, ErrZoneNotFound) && p.RegisterMissingZones {
+		err = p.registerZone(ctx, c, zone)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not get records for zone %q: %w", zone, err)
+	}
+	upstreamRecords = slices.DeleteFunc(slices.Clone(upstreamRecords), func(record ApiDnsRecord) bool {
+		return opts.protects(record.Type)
+	})
+
+	ttls, err := availableTTLs(ctx, c, zone)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRecordTypes(ctx, c, zone, desired); err != nil {
+		return nil, err
+	}
+	existing := clouDNSRecordsToMap(upstreamRecords)
+	if p.SPFAsTXT {
+		mergeSPFIntoTXT(existing)
+	}
+	rrsets := libdnsRecordsToMap(dedupeRecords(desired, ttls))
+
+	// The RRsets missing from the desired records are deleted first, so that
+	// e.g. a CNAME is gone before other records are added with its name
+	var stale []operationEntry
+	for key, records := range existing {
+		if _, ok := rrsets[key]; ok {
+			continue
+		}
+		for _, record := range records {
+			stale = append(stale, operationEntry{op: deleteRecord, record: record})
+		}
+	}
+	oplist := append(stale, makeOperationList(rrsets, existing, ttls)...)
+
+	ret, audit, err := p.applyOperations(ctx, c, zone, oplist, upstreamRecords)
+	if err == nil && p.OnZoneSynced != nil && !p.DryRun {
+		p.OnZoneSynced(ctx, zone, ret)
+	}
+
+	return audit, err
+}
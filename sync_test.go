@@ -0,0 +1,57 @@
+package cloudns
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestSyncZone(t *testing.T) {
+	api := &mockAPI{
+		records: []ApiDnsRecord{
+			{Id: "1", Type: "NS", Host: "", Record: "ns1.cloudns.net", Ttl: "3600", Status: 1},
+			{Id: "2", Type: "TXT", Host: "www", Record: "keep", Ttl: "300", Status: 1},
+			{Id: "3", Type: "TXT", Host: "www", Record: "stale", Ttl: "300", Status: 1},
+			{Id: "4", Type: "CNAME", Host: "old", Record: "example.net", Ttl: "300", Status: 1},
+		},
+	}
+
+	provider, err := NewProvider(WithAPI(api))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	audit, err := provider.SyncZone(t.Context(), "example.com", []libdns.Record{
+		libdns.TXT{Name: "www", TTL: 5 * time.Minute, Text: "keep"},
+		libdns.TXT{Name: "new", TTL: 5 * time.Minute, Text: "hello"},
+		libdns.NS{Name: "@", TTL: time.Hour, Target: "ns2.example.net."},
+	}, SyncOptions{})
+	if err != nil {
+		t.Fatalf("Failed to sync zone: %v", err)
+	}
+
+	// The stale record and the RRset missing from the desired records are
+	// deleted, while the NS records are protected
+	deleted := slices.Sorted(slices.Values(api.deleted))
+	if !reflect.DeepEqual(deleted, []string{"3", "4"}) {
+		t.Errorf("Expected records 3 and 4 to be deleted, got %v", api.deleted)
+	}
+	if len(api.added) != 1 || api.added[0].Host != "new" {
+		t.Errorf("Expected the new record to be added, got %+v", api.added)
+	}
+	if len(audit) != 3 || audit[0].Kind != OperationDelete || audit[2].Kind != OperationAdd {
+		t.Errorf("Expected two deletions before an addition, got %+v", audit)
+	}
+
+	// Without protected types, the NS records are synced as well
+	api.deleted, api.added = nil, nil
+	if _, err := provider.SyncZone(t.Context(), "example.com", nil, SyncOptions{ProtectedTypes: []string{}}); err != nil {
+		t.Fatalf("Failed to sync zone: %v", err)
+	}
+	if !slices.Contains(api.deleted, "1") {
+		t.Errorf("Expected the NS record to be deleted, got %v", api.deleted)
+	}
+}
@@ -0,0 +1,130 @@
+package cloudns
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// requestTrace collects the timings of a request through httptrace, to
+// diagnose slow requests.
+type requestTrace struct {
+	mu    sync.Mutex
+	start time.Time
+
+	dnsStart     time.Time
+	dns          time.Duration
+	connectStart time.Time
+	connect      time.Duration
+	tlsStart     time.Time
+	tls          time.Duration
+	firstByte    time.Duration
+	reused       bool
+	wasIdle      bool
+	idleTime     time.Duration
+	remoteAddr   string
+	err          error
+}
+
+// withTrace returns the request with a trace collecting its timings
+// attached, if the client traces its requests.
+func (c *Client) withTrace(req *http.Request) (*http.Request, *requestTrace) {
+	if !c.TraceRequests || c.Logger == nil {
+		return req, nil
+	}
+
+	tr := &requestTrace{start: time.Now()}
+	clientTrace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.dnsStart = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.dns = time.Since(tr.dnsStart)
+			if info.Err != nil {
+				tr.err = info.Err
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			if tr.connectStart.IsZero() {
+				tr.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.connect = time.Since(tr.connectStart)
+			if err != nil {
+				tr.err = err
+			}
+		},
+		TLSHandshakeStart: func() {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.tls = time.Since(tr.tlsStart)
+			if err != nil {
+				tr.err = err
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.reused = info.Reused
+			tr.wasIdle = info.WasIdle
+			tr.idleTime = info.IdleTime
+			if info.Conn != nil {
+				tr.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		GotFirstResponseByte: func() {
+			tr.mu.Lock()
+			defer tr.mu.Unlock()
+			tr.firstByte = time.Since(tr.start)
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), clientTrace)), tr
+}
+
+// logTrace logs the timings collected for a request to the endpoint, if it
+// was traced.
+func (c *Client) logTrace(ctx context.Context, endpoint string, tr *requestTrace) {
+	if tr == nil {
+		return
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	attrs := []any{
+		"endpoint", endpoint,
+		"operation", OperationID(ctx),
+		"remote_addr", tr.remoteAddr,
+		"reused", tr.reused,
+		"was_idle", tr.wasIdle,
+		"idle_time", tr.idleTime,
+		"dns", tr.dns,
+		"connect", tr.connect,
+		"tls_handshake", tr.tls,
+		"time_to_first_byte", tr.firstByte,
+		"total", time.Since(tr.start),
+	}
+	if tr.err != nil {
+		attrs = append(attrs, "error", tr.err)
+	}
+
+	c.Logger.DebugContext(ctx, "ClouDNS API request trace", attrs...)
+}
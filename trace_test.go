@@ -0,0 +1,66 @@
+package cloudns
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHTTPTrace(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	provider, err := NewProvider(WithCredentials("id", "password"), WithLogger(logger), WithHTTPTrace())
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	for range 2 {
+		if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+			t.Fatalf("Failed to get records: %v", err)
+		}
+	}
+
+	var traces []string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, "ClouDNS API request trace") {
+			traces = append(traces, line)
+		}
+	}
+	if len(traces) != 2 {
+		t.Fatalf("Expected a trace per request, got:\n%s", logs.String())
+	}
+	for _, attr := range []string{"endpoint=/dns/records.json", "time_to_first_byte=", "connect=", "remote_addr=127.0.0.1:"} {
+		if !strings.Contains(traces[0], attr) {
+			t.Errorf("Expected %s in %s", attr, traces[0])
+		}
+	}
+	if !strings.Contains(traces[0], "reused=false") || !strings.Contains(traces[1], "reused=true") {
+		t.Errorf("Expected the connection to be reused by the second request:\n%s", strings.Join(traces, "\n"))
+	}
+}
+
+func TestHTTPTraceDisabled(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	provider, err := NewProvider(WithCredentials("id", "password"), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := provider.GetRecords(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to get records: %v", err)
+	}
+	if strings.Contains(logs.String(), "ClouDNS API request trace") {
+		t.Errorf("Expected no traces without WithHTTPTrace:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,99 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExportZone returns the records of the zone as a BIND zone file (RFC 1035
+// master file format), using records-export.json. It can be used to back up
+// a zone or to migrate it to another provider.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to export
+//
+// Returns:
+//   - string: The zone file contents
+//   - error: Any error that occurred during the operation
+func (c *Client) ExportZone(ctx context.Context, zone string) (string, error) {
+	endpoint := apiBaseUrl.JoinPath("records-export.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	var result struct {
+		Zone string `json:"zone"`
+	}
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return "", err
+	}
+
+	return result.Zone, nil
+}
+
+// ImportFormat is the format of the records passed to ImportZone.
+type ImportFormat string
+
+const (
+	// ImportFormatBIND is the BIND zone file format (RFC 1035 master file)
+	ImportFormatBIND ImportFormat = "bind"
+
+	// ImportFormatTinyDNS is the data file format of djbdns/tinydns
+	ImportFormatTinyDNS ImportFormat = "tinydns"
+)
+
+// ImportZone adds the records described by content to the zone, using
+// records-import.json. Both BIND zone files and tinydns data files are
+// supported, which eases migrating from other DNS servers.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to import the records into
+//   - format: The format of content
+//   - content: The records to import
+//   - deleteExisting: Whether to delete the records already in the zone first
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) ImportZone(ctx context.Context, zone string, format ImportFormat, content string, deleteExisting bool) error {
+	switch format {
+	case ImportFormatBIND, ImportFormatTinyDNS:
+	default:
+		return fmt.Errorf("unsupported import format %q", format)
+	}
+
+	endpoint := apiBaseUrl.JoinPath("records-import.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"format":      string(format),
+		"content":     content,
+	}
+	if deleteExisting {
+		params["delete-existing-records"] = "1"
+	}
+
+	// Zone files easily exceed the length limits of URLs
+	return c.performFormStatusRequest(ctx, endpoint, params)
+}
+
+// ImportZoneAXFR seeds the zone with the records of an external master
+// server through a zone transfer (AXFR), using axfr-import.json. The master
+// server must allow transfers of the zone to the ClouDNS servers.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to import the records into
+//   - server: Hostname or IP address of the master server to transfer from
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) ImportZoneAXFR(ctx context.Context, zone string, server string) error {
+	endpoint := apiBaseUrl.JoinPath("axfr-import.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"server":      server,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
@@ -0,0 +1,68 @@
+package cloudns
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExportZone(t *testing.T) {
+	const zoneFile = "$ORIGIN example.com.\n@\t3600\tIN\tA\t192.0.2.1\n"
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/records-export.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"status":"Success","zone":%q}`, zoneFile)
+	})
+
+	exported, err := UseClient("id", "", "password").ExportZone(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Failed to export zone: %v", err)
+	}
+	if exported != zoneFile {
+		t.Errorf("Expected %q, got %q", zoneFile, exported)
+	}
+}
+
+func TestImportZone(t *testing.T) {
+	content := strings.Repeat("+www.example.com:192.0.2.1:3600\n", 1000)
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// The zone file is sent in the body, along with the credentials
+		if r.Method != http.MethodPost || r.URL.RawQuery != "" {
+			t.Errorf("Expected the parameters in the body, got %s %s", r.Method, r.URL)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		form := r.PostForm
+		if form.Get("auth-id") != "id" || form.Get("format") != "tinydns" || form.Get("content") != content {
+			t.Errorf("Unexpected import parameters %v", form)
+		}
+		if form.Has("delete-existing-records") {
+			t.Errorf("Expected existing records to be kept")
+		}
+		fmt.Fprint(w, `{"status":"Success","statusDescription":"The records were imported successfully."}`)
+	})
+
+	c := UseClient("id", "", "password")
+	if err := c.ImportZone(t.Context(), "example.com", ImportFormatTinyDNS, content, false); err != nil {
+		t.Errorf("Failed to import zone: %v", err)
+	}
+	if err := c.ImportZone(t.Context(), "example.com", "csv", "", false); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
+
+func TestImportZoneAXFR(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/axfr-import.json" || r.URL.Query().Get("server") != "192.0.2.53" {
+			t.Errorf("Unexpected request %v", r.URL)
+		}
+		fmt.Fprint(w, `{"status":"Failed","statusDescription":"The zone transfer was refused."}`)
+	})
+
+	if err := UseClient("id", "", "password").ImportZoneAXFR(t.Context(), "example.com", "192.0.2.53"); err == nil {
+		t.Errorf("Expected the refused transfer to be reported")
+	}
+}
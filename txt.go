@@ -0,0 +1,86 @@
+package cloudns
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxTXTStringLength is the maximum length of a single character string in
+// the data of a TXT record. Longer values, like DKIM keys, are split into
+// several strings that resolvers concatenate.
+const maxTXTStringLength = 255
+
+// encodeTXT brings the logical value of a TXT record into the form sent to
+// ClouDNS. Plain values are sent as is, like the ClouDNS UI does. Values
+// that would be misread unquoted, because they contain quotes, backslashes
+// or semicolons or start or end with spaces, are sent as quoted strings, with
+// quotes and backslashes escaped. Values longer than maxTXTStringLength bytes
+// are split into several quoted strings, without splitting UTF-8 characters.
+func encodeTXT(text string) string {
+	if !needsQuoting(text) {
+		return text
+	}
+
+	var chunks []string
+	for len(text) > maxTXTStringLength {
+		end := maxTXTStringLength
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		chunks = append(chunks, quoteTXTString(text[:end]))
+		text = text[end:]
+	}
+	chunks = append(chunks, quoteTXTString(text))
+
+	return strings.Join(chunks, " ")
+}
+
+func needsQuoting(text string) bool {
+	if len(text) > maxTXTStringLength || strings.ContainsAny(text, `"\;`) {
+		return true
+	}
+
+	trimmed := strings.TrimFunc(text, unicode.IsSpace)
+	return trimmed != text
+}
+
+func quoteTXTString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+
+	return `"` + s + `"`
+}
+
+// decodeTXT returns the logical value of TXT record data returned by
+// ClouDNS. Data made of quoted strings is unescaped, and the strings of
+// values split into several ones are concatenated. Other values are returned
+// as is.
+func decodeTXT(data string) string {
+	chunks, ok := splitTXTStrings(data)
+	if !ok {
+		return data
+	}
+
+	return strings.Join(chunks, "")
+}
+
+// splitTXTStrings splits data made of quoted strings separated by spaces into
+// the unescaped content of these strings. It returns false if data is not in
+// that form.
+func splitTXTStrings(data string) ([]string, bool) {
+	tokens, ok := tokenizeRDATA(data)
+	if !ok || len(tokens) == 0 {
+		return nil, false
+	}
+
+	chunks := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if !token.quoted {
+			return nil, false
+		}
+		chunks = append(chunks, token.value)
+	}
+
+	return chunks, true
+}
@@ -0,0 +1,93 @@
+package cloudns
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestEncodeTXT(t *testing.T) {
+	short := "v=spf1 -all"
+	if encoded := encodeTXT(short); encoded != short {
+		t.Errorf("Expected short text to be sent as is, got %q", encoded)
+	}
+
+	long := strings.Repeat("a", 300)
+	expected := `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`
+	if encoded := encodeTXT(long); encoded != expected {
+		t.Errorf("Expected %q, got %q", expected, encoded)
+	}
+
+	// Multi-byte characters are not split across strings
+	unicode := strings.Repeat("a", 254) + "é"
+	if encoded := encodeTXT(unicode); encoded != `"`+strings.Repeat("a", 254)+`" "é"` {
+		t.Errorf("Unexpected encoding %q", encoded)
+	}
+}
+
+func TestDecodeTXT(t *testing.T) {
+	tests := map[string]string{
+		"hello":                 "hello",
+		"v=DKIM1; k=rsa":        "v=DKIM1; k=rsa",
+		`"hello"`:               "hello",
+		`"hello" "world"`:       "helloworld",
+		`"v=DKIM1; p=AB" "C"`:   "v=DKIM1; p=ABC",
+		`"say \"hi\""`:          `say "hi"`,
+		`"back\\slash"`:         `back\slash`,
+		`"decimal\034escape"`:   `decimal"escape`,
+		`"  padded  "`:          "  padded  ",
+		`"unterminated" "`:      `"unterminated" "`,
+		`"trailing backslash\`:  `"trailing backslash\`,
+		`"quoted" and unquoted`: `"quoted" and unquoted`,
+	}
+
+	for in, expected := range tests {
+		if out := decodeTXT(in); out != expected {
+			t.Errorf("decodeTXT(%q): expected %q, got %q", in, expected, out)
+		}
+	}
+}
+
+func TestLongTXTRoundTrip(t *testing.T) {
+	rec := libdns.TXT{Name: "selector._domainkey", TTL: time.Hour, Text: "v=DKIM1; k=rsa; p=" + strings.Repeat("B", 600)}
+
+	converted, err := fromLibdnsRecord(rec, "1", nil).toLibdnsRecord()
+	if err != nil {
+		t.Fatalf("Failed to convert record: %v", err)
+	}
+	if text := converted.(libdns.TXT).Text; text != rec.Text {
+		t.Errorf("Expected %q, got %q", rec.Text, text)
+	}
+}
+
+func TestTXTQuoting(t *testing.T) {
+	tests := map[string]string{
+		"v=spf1 -all":    "v=spf1 -all",
+		"v=DKIM1; k=rsa": `"v=DKIM1; k=rsa"`,
+		`say "hi"`:       `"say \"hi\""`,
+		`back\slash`:     `"back\\slash"`,
+		" padded ":       `" padded "`,
+	}
+
+	for in, expected := range tests {
+		encoded := encodeTXT(in)
+		if encoded != expected {
+			t.Errorf("encodeTXT(%q): expected %q, got %q", in, expected, encoded)
+		}
+		if decoded := decodeTXT(encoded); decoded != in {
+			t.Errorf("decodeTXT(%q): expected %q, got %q", encoded, in, decoded)
+		}
+	}
+}
+
+func TestTXTMatchingIgnoresQuoting(t *testing.T) {
+	// Records created in the ClouDNS UI are stored unquoted
+	existing := ApiDnsRecord{Id: "1", Type: "TXT", Host: "sel._domainkey", Record: "v=DKIM1; k=rsa; p=ABC", Ttl: "3600"}
+	desired := libdns.TXT{Name: "sel._domainkey", TTL: time.Hour, Text: "v=DKIM1; k=rsa; p=ABC"}
+
+	if _, ok := pairOperation(existing, desired, nil); ok {
+		t.Errorf("Expected no operation for the same value with different quoting")
+	}
+}
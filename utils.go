@@ -2,14 +2,19 @@ package cloudns
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/libdns/libdns"
+	"golang.org/x/net/idna"
 )
 
-// Rounds the given TTL in seconds to the next accepted value.
-// Accepted TTL values are:
+// defaultTTLs is the list of TTL values accepted by ClouDNS on standard plans.
+// It is used whenever the live list from get-available-ttl.json is not
+// available.
 //   - 60 = 1 minute
 //   - 300 = 5 minutes
 //   - 900 = 15 minutes
@@ -25,19 +30,29 @@ import (
 //   - 2592000 = 1 month
 //
 // See https://www.cloudns.net/wiki/article/58/ for details.
-func ttlRounder(ttl time.Duration) int {
+var defaultTTLs = []int{60, 300, 900, 1800, 3600, 21600, 43200, 86400, 172800, 259200, 604800, 1209600, 2592000}
+
+// Rounds the given TTL in seconds to the next accepted value out of valid,
+// which must be sorted in ascending order. If valid is empty, defaultTTLs is
+// used instead. TTLs above the largest accepted value are clamped to it.
+func ttlRounder(ttl time.Duration, valid []int) int {
+	if len(valid) == 0 {
+		valid = defaultTTLs
+	}
+
 	t := int(ttl.Seconds())
-	for _, validTTL := range []int{60, 300, 900, 1800, 3600, 21600, 43200, 86400, 172800, 259200, 604800, 1209600} {
+	for _, validTTL := range valid {
 		if t <= validTTL {
 			return validTTL
 		}
 	}
 
-	return 2592000
+	return valid[len(valid)-1]
 }
 
 // RetryWithBackoff executes the given function with exponential backoff retry logic.
-// It will retry the function until it succeeds or the maximum number of retries is reached.
+// It will retry the function until it succeeds, the maximum number of retries is reached,
+// or it fails with an error that retrying cannot fix, such as ErrZoneNotFound.
 //
 // Parameters:
 //   - ctx: Context for timeout and cancellation
@@ -49,10 +64,72 @@ func ttlRounder(ttl time.Duration) int {
 // Returns:
 //   - error: The last error returned by the operation, or nil if it succeeded
 func RetryWithBackoff(ctx context.Context, operation func() error, maxRetries int, initialBackoff, maxBackoff time.Duration) error {
+	return RetryWithBackoffClock(ctx, operation, maxRetries, initialBackoff, maxBackoff, time.After)
+}
+
+// RetryWithBackoffClock works like RetryWithBackoff, and waits between the
+// attempts on the channels returned by after instead of on real time, so that
+// tests can fast-forward through the backoff deterministically. A nil after
+// defaults to time.After.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - operation: Function to execute
+//   - maxRetries: Maximum number of retry attempts
+//   - initialBackoff: Initial backoff duration
+//   - maxBackoff: Maximum backoff duration
+//   - after: Function returning a channel that delivers once the duration elapsed
+//
+// Returns:
+//   - error: The last error returned by the operation, or nil if it succeeded
+func RetryWithBackoffClock(ctx context.Context, operation func() error, maxRetries int, initialBackoff, maxBackoff time.Duration, after func(time.Duration) <-chan time.Time) error {
+	policy := retryPolicy{
+		maxRetries:        maxRetries,
+		initialBackoff:    initialBackoff,
+		maxBackoff:        maxBackoff,
+		rateLimitCooldown: DefaultRateLimitCooldown,
+		zoneLockedPause:   DefaultZoneLockedPause,
+		after:             after,
+		retryable:         isRetryable,
+	}
+
+	return policy.do(ctx, operation)
+}
+
+// retryPolicy holds the settings of the retry logic of RetryWithBackoff.
+type retryPolicy struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	// rateLimitCooldown is waited instead of the backoff after an attempt
+	// failed with ErrRateLimited
+	rateLimitCooldown time.Duration
+
+	// zoneLockedPause is waited instead of the backoff after an attempt
+	// failed because the zone is locked or updating
+	zoneLockedPause time.Duration
+
+	// after returns a channel that delivers once the duration elapsed,
+	// time.After if nil
+	after func(time.Duration) <-chan time.Time
+
+	// retryable reports whether an error may go away by retrying
+	retryable func(error) bool
+}
+
+// do runs the operation until it succeeds, the maximum number of retries is
+// reached, or it fails with an error that is not retryable.
+func (r retryPolicy) do(ctx context.Context, operation func() error) error {
+	after := r.after
+	if after == nil {
+		after = time.After
+	}
+
 	var err error
-	backoff := initialBackoff
+	backoff := r.initialBackoff
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
+	for attempt := 0; attempt < r.maxRetries; attempt++ {
 		// Check if context is canceled
 		select {
 		case <-ctx.Done():
@@ -66,20 +143,47 @@ func RetryWithBackoff(ctx context.Context, operation func() error, maxRetries in
 			return nil // Operation succeeded
 		}
 
+		// Errors caused by the request itself will not go away by retrying
+		if !r.retryable(err) {
+			return err
+		}
+
 		// If this was the last attempt, return the error
-		if attempt == maxRetries-1 {
-			return fmt.Errorf("operation failed after %d attempts: %w", maxRetries, err)
+		if attempt == r.maxRetries-1 {
+			return fmt.Errorf("operation failed after %d attempts: %w", r.maxRetries, err)
+		}
+
+		// Rate limited requests get a dedicated cool-off, which leaves the
+		// backoff curve untouched
+		if errors.Is(err, ErrRateLimited) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-after(r.rateLimitCooldown):
+			}
+			continue
+		}
+
+		// Locked zones are usually released once ClouDNS applied the
+		// previous changes, which a short pause waits for
+		if r.zoneLockedPause > 0 && isZoneLocked(err) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-after(r.zoneLockedPause):
+			}
+			continue
 		}
 
 		// Wait before retrying with exponential backoff
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(backoff):
+		case <-after(backoff):
 			// Double the backoff for next attempt, but don't exceed maxBackoff
 			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
+			if backoff > r.maxBackoff {
+				backoff = r.maxBackoff
 			}
 		}
 	}
@@ -87,17 +191,109 @@ func RetryWithBackoff(ctx context.Context, operation func() error, maxRetries in
 	return err
 }
 
+// normalizeZone brings a zone name into the form expected by the ClouDNS API,
+// without the trailing dot and with internationalized labels in punycode.
+func normalizeZone(zone string) string {
+	return toASCII(strings.TrimSuffix(zone, "."))
+}
+
+// toASCII converts the internationalized labels of a domain name to punycode,
+// e.g. "bücher.example" to "xn--bcher-kva.example". ASCII labels are kept as
+// is, and so are labels that cannot be converted, leaving them for the API to
+// reject.
+func toASCII(name string) string {
+	labels := strings.Split(name, ".")
+	for idx, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+
+		if encoded, err := idna.Punycode.ToASCII(strings.ToLower(label)); err == nil {
+			labels[idx] = encoded
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// toUnicode converts the punycode labels of a domain name back to Unicode.
+// Names that cannot be converted are returned as is.
+func toUnicode(name string) string {
+	decoded, err := idna.Punycode.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+
+	return decoded
+}
+
+func isASCII(s string) bool {
+	for idx := range len(s) {
+		if s[idx] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
 type nameAndType struct {
 	name  string
 	type_ string
 }
 
+// recordTypeAliases maps alternative spellings of record types to their
+// canonical name. ClouDNS and zone files may refer to types by their RFC 3597
+// generic name, like "TYPE16" for TXT.
+var recordTypeAliases = map[string]string{
+	"TYPE1":   "A",
+	"TYPE2":   "NS",
+	"TYPE5":   "CNAME",
+	"TYPE12":  "PTR",
+	"TYPE13":  "HINFO",
+	"TYPE15":  "MX",
+	"TYPE16":  "TXT",
+	"TYPE17":  "RP",
+	"TYPE28":  "AAAA",
+	"TYPE29":  "LOC",
+	"TYPE33":  "SRV",
+	"TYPE35":  "NAPTR",
+	"TYPE37":  "CERT",
+	"TYPE39":  "DNAME",
+	"TYPE43":  "DS",
+	"TYPE44":  "SSHFP",
+	"TYPE52":  "TLSA",
+	"TYPE53":  "SMIMEA",
+	"TYPE64":  "SVCB",
+	"TYPE65":  "HTTPS",
+	"TYPE99":  "SPF",
+	"TYPE257": "CAA",
+}
+
+// canonicalRecordType returns the canonical, upper case name of a record type,
+// resolving aliases. Record types are case insensitive.
+func canonicalRecordType(type_ string) string {
+	type_ = strings.ToUpper(strings.TrimSpace(type_))
+	if canonical, ok := recordTypeAliases[type_]; ok {
+		return canonical
+	}
+
+	return type_
+}
+
+// newNameAndType creates the key of a record. DNS names and types are case
+// insensitive, so they are normalized to lower case and canonical types,
+// and names are brought into the ClouDNS form with normalizeHost.
+func newNameAndType(name, type_ string) nameAndType {
+	return nameAndType{name: strings.ToLower(normalizeHost(name)), type_: canonicalRecordType(type_)}
+}
+
 // clouDNSRecordsToMap turns a slice of raw upstream results into a map indexed
 // by a the name and type of the record
 func clouDNSRecordsToMap(recs []ApiDnsRecord) map[nameAndType][]ApiDnsRecord {
-	ret := make(map[nameAndType][]ApiDnsRecord)
+	ret := make(map[nameAndType][]ApiDnsRecord, len(recs))
 	for _, res := range recs {
-		k := nameAndType{name: res.Host, type_: res.Type}
+		k := newNameAndType(res.Host, res.Type)
 		if _, ok := ret[k]; !ok {
 			ret[k] = []ApiDnsRecord{res}
 		} else {
@@ -108,15 +304,35 @@ func clouDNSRecordsToMap(recs []ApiDnsRecord) map[nameAndType][]ApiDnsRecord {
 	return ret
 }
 
-func libdnsRecordsToMap(recs []libdns.Record) map[nameAndType][]libdns.RR {
-	ret := make(map[nameAndType][]libdns.RR)
+// dedupeRecords removes records that would end up identical on ClouDNS once
+// converted, i.e. with the same name, type, data and rounded TTL. The first
+// occurrence of each record is kept and the order is otherwise preserved.
+func dedupeRecords(recs []libdns.Record, ttls []int) []libdns.Record {
+	seen := make(map[ApiDnsRecord]bool, len(recs))
+	ret := make([]libdns.Record, 0, len(recs))
+	for _, rec := range recs {
+		key := fromLibdnsRecord(rec, "", ttls)
+		key.Host = strings.ToLower(key.Host)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		ret = append(ret, rec)
+	}
+
+	return ret
+}
+
+func libdnsRecordsToMap(recs []libdns.Record) map[nameAndType][]libdns.Record {
+	ret := make(map[nameAndType][]libdns.Record, len(recs))
 	for _, res := range recs {
 		rr := res.RR()
-		k := nameAndType{name: rr.Name, type_: rr.Type}
+		k := newNameAndType(rr.Name, rr.Type)
 		if _, ok := ret[k]; !ok {
-			ret[k] = []libdns.RR{rr}
+			ret[k] = []libdns.Record{res}
 		} else {
-			ret[k] = append(ret[k], rr)
+			ret[k] = append(ret[k], res)
 		}
 	}
 
@@ -0,0 +1,191 @@
+package cloudns
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestTTLRounder(t *testing.T) {
+	tests := []struct {
+		ttl   int
+		valid []int
+		out   int
+	}{
+		{ttl: 0, valid: nil, out: 60},
+		{ttl: 301, valid: nil, out: 900},
+		{ttl: 99999999, valid: nil, out: 2592000},
+		{ttl: 30, valid: []int{30, 60}, out: 30},
+		{ttl: 120, valid: []int{30, 60}, out: 60},
+	}
+
+	for _, tt := range tests {
+		if out := ttlRounder(time.Duration(tt.ttl)*time.Second, tt.valid); out != tt.out {
+			t.Errorf("ttlRounder(%d, %v): expected %d, got %d", tt.ttl, tt.valid, tt.out, out)
+		}
+	}
+}
+
+func TestDedupeRecords(t *testing.T) {
+	in := []libdns.Record{
+		libdns.TXT{Name: "foo", TTL: 300 * time.Second, Text: "a"},
+		libdns.Address{Name: "foo", TTL: 300 * time.Second, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.TXT{Name: "foo", TTL: 300 * time.Second, Text: "a"},
+		libdns.TXT{Name: "foo", TTL: 200 * time.Second, Text: "a"},
+		libdns.RR{Name: "foo", TTL: 300 * time.Second, Type: "A", Data: "192.0.2.1"},
+		libdns.TXT{Name: "foo", TTL: 300 * time.Second, Text: "b"},
+	}
+	expected := []libdns.Record{in[0], in[1], in[5]}
+
+	if out := dedupeRecords(in, nil); !reflect.DeepEqual(out, expected) {
+		t.Errorf("actual: %+v\n\nexpected: %+v", out, expected)
+	}
+}
+
+func TestRecordsToMapCaseInsensitive(t *testing.T) {
+	upstream := clouDNSRecordsToMap([]ApiDnsRecord{{Id: "1", Host: "test", Type: "TXT", Record: "a", Ttl: "60"}})
+	desired := libdnsRecordsToMap([]libdns.Record{libdns.RR{Name: "Test", Type: "txt", Data: "a", TTL: time.Minute}})
+
+	for key := range desired {
+		if _, ok := upstream[key]; !ok {
+			t.Errorf("Expected key %+v to match upstream keys %v", key, upstream)
+		}
+	}
+
+	ops := makeOperationList(desired, upstream, nil)
+	if len(ops) != 0 {
+		t.Errorf("Expected no operations for records only differing in case, got %+v", ops)
+	}
+}
+
+func TestIDNConversion(t *testing.T) {
+	if zone := normalizeZone("bücher.example."); zone != "xn--bcher-kva.example" {
+		t.Errorf("Expected punycode zone, got %q", zone)
+	}
+
+	rec := libdns.TXT{Name: "_acme-challenge.Bücher", TTL: time.Minute, Text: "token"}
+	upstream := fromLibdnsRecord(rec, "1", nil)
+	if upstream.Host != "_acme-challenge.xn--bcher-kva" {
+		t.Errorf("Expected punycode host, got %q", upstream.Host)
+	}
+
+	back, err := upstream.toLibdnsRecord()
+	if err != nil {
+		t.Fatalf("Failed to convert record: %v", err)
+	}
+	if name := back.RR().Name; name != "_acme-challenge.bücher" {
+		t.Errorf("Expected Unicode name, got %q", name)
+	}
+}
+
+func TestIDNTargetConversion(t *testing.T) {
+	recs := []libdns.Record{
+		libdns.CNAME{Name: "www", TTL: time.Minute, Target: "bücher.example."},
+		libdns.MX{Name: "@", TTL: time.Minute, Preference: 10, Target: "mail.bücher.example."},
+		libdns.NS{Name: "sub", TTL: time.Minute, Target: "ns.bücher.example."},
+		libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", TTL: time.Minute, Port: 5060, Target: "sip.bücher.example."},
+	}
+
+	for _, rec := range recs {
+		if err := validateRecord(rec); err != nil {
+			t.Errorf("Expected the Unicode target to be valid, got %v", err)
+		}
+
+		upstream := fromLibdnsRecord(rec, "1", nil)
+		if !strings.HasSuffix(upstream.Record, "xn--bcher-kva.example") {
+			t.Errorf("Expected punycode target, got %q", upstream.Record)
+		}
+
+		back, err := upstream.toLibdnsRecord()
+		if err != nil {
+			t.Fatalf("Failed to convert record: %v", err)
+		}
+		if data, expected := back.RR().Data, rec.RR().Data; data != strings.TrimSuffix(expected, ".") {
+			t.Errorf("Expected Unicode target %q, got %q", expected, data)
+		}
+	}
+}
+
+func TestCanonicalRecordType(t *testing.T) {
+	tests := map[string]string{
+		"A":       "A",
+		"txt":     "TXT",
+		" Mx ":    "MX",
+		"TYPE16":  "TXT",
+		"type257": "CAA",
+		"WR":      "WR",
+	}
+
+	for in, expected := range tests {
+		if out := canonicalRecordType(in); out != expected {
+			t.Errorf("canonicalRecordType(%q): expected %q, got %q", in, expected, out)
+		}
+	}
+}
+
+func TestTolerantRecordTypes(t *testing.T) {
+	var records map[string]ApiDnsRecord
+	data := `{"1":{"id":"1","type":"txt","host":"www","record":"hello","ttl":"60","status":1},"2":{"id":"2","type":"TYPE28","host":"www","record":"2001:db8::1","ttl":"60","status":1}}`
+	if err := json.Unmarshal([]byte(data), &records); err != nil {
+		t.Fatalf("Failed to decode records: %v", err)
+	}
+
+	if _, ok := mustLibdnsRecord(t, records["1"]).(libdns.TXT); !ok {
+		t.Errorf("Expected a TXT record for type %q", "txt")
+	}
+	if _, ok := mustLibdnsRecord(t, records["2"]).(libdns.Address); !ok {
+		t.Errorf("Expected an address record for type %q", "TYPE28")
+	}
+
+	existing := clouDNSRecordsToMap([]ApiDnsRecord{{Id: "1", Type: "txt", Host: "www", Record: "hello", Ttl: "60"}})
+	desired := []libdns.Record{libdns.TXT{Name: "www", TTL: time.Minute, Text: "hello"}}
+	if ops := makeOperationList(libdnsRecordsToMap(desired), existing, nil); len(ops) != 0 {
+		t.Errorf("Expected types to match regardless of case, got operations %+v", ops)
+	}
+}
+
+func mustLibdnsRecord(t *testing.T, record ApiDnsRecord) libdns.Record {
+	t.Helper()
+
+	rec, err := record.toLibdnsRecord()
+	if err != nil {
+		t.Fatalf("Failed to convert record %+v: %v", record, err)
+	}
+
+	return rec
+}
+
+// fakeClock fires immediately and records the durations it was asked to wait.
+type fakeClock struct {
+	waits []time.Duration
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+func TestRetryWithBackoffClock(t *testing.T) {
+	clock := &fakeClock{}
+	attempts := 0
+	err := RetryWithBackoffClock(t.Context(), func() error {
+		attempts++
+		return errors.New("temporary failure")
+	}, 5, time.Second, 5*time.Second, clock.After)
+
+	if err == nil || attempts != 5 {
+		t.Errorf("Expected 5 failed attempts, got %d: %v", attempts, err)
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	if !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf("Expected waits %v, got %v", expected, clock.waits)
+	}
+}
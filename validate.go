@@ -0,0 +1,269 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// ErrInvalidRecord is returned when a record is rejected by the client-side
+// validation, before any request is sent to ClouDNS.
+var ErrInvalidRecord = errors.New("invalid record")
+
+const (
+	// maxNameLength is the maximum length of a domain name in presentation format
+	maxNameLength = 253
+
+	// maxLabelLength is the maximum length of a single label of a domain name
+	maxLabelLength = 63
+
+	// maxTXTLength is the maximum length of the text of a TXT record. Texts
+	// are split into strings of maxTXTStringLength bytes, each taking one more
+	// byte in the record data, which is limited to 65535 bytes.
+	maxTXTLength = 65535 / (maxTXTStringLength + 1) * maxTXTStringLength
+)
+
+// validCAATags lists the CAA property tags supported by ClouDNS.
+var validCAATags = []string{"issue", "issuewild", "iodef"}
+
+// validSRVTransports lists the transport protocols SRV records can be
+// published for.
+var validSRVTransports = []string{"tcp", "udp", "tls", "sctp"}
+
+// validateRecords checks all the given records and returns the joined errors
+// of the ones that are rejected.
+func validateRecords(recs []libdns.Record) error {
+	var errs []error
+	for _, rec := range recs {
+		if err := validateRecord(rec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateRecord checks a record for mistakes that ClouDNS would reject
+// anyway, so that they can be reported without a round trip to the API.
+// The returned error wraps ErrInvalidRecord.
+func validateRecord(rec libdns.Record) error {
+	rr := rec.RR()
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w %s %q: %s", ErrInvalidRecord, rr.Type, rr.Name, fmt.Sprintf(format, args...))
+	}
+
+	if err := validateName(normalizeHost(rr.Name), true); err != nil {
+		return invalid("bad name: %v", err)
+	}
+
+	// Generic records are parsed, so that they get the same checks as the
+	// typed ones. libdns only parses canonical types, like "A" but not "a".
+	if generic, ok := rec.(libdns.RR); ok {
+		generic.Type = canonicalRecordType(generic.Type)
+		parsed, err := generic.Parse()
+		if err != nil {
+			return invalid("%v", err)
+		}
+		if parsed.RR().Type != generic.Type {
+			return invalid("data %q does not match the record type", generic.Data)
+		}
+		rec = parsed
+	}
+
+	switch impl := rec.(type) {
+	case libdns.Address:
+		if !impl.IP.IsValid() {
+			return invalid("missing IP address")
+		}
+	case libdns.CAA:
+		if impl.Flags != 0 && impl.Flags != caaCriticalFlag {
+			return invalid("unsupported CAA flags %d, expected 0 or %d (critical)", impl.Flags, caaCriticalFlag)
+		}
+		tag := normalizeCAATag(impl.Tag)
+		if !slices.Contains(validCAATags, tag) {
+			return invalid("unsupported CAA tag %q, expected one of %v", impl.Tag, validCAATags)
+		}
+		value := unquoteCAAValue(impl.Value)
+		if value == "" {
+			return invalid(`empty CAA value, use ";" to forbid issuance`)
+		}
+		if tag == "iodef" {
+			if err := validateIodefURL(value); err != nil {
+				return invalid("%v", err)
+			}
+		}
+	case libdns.CNAME:
+		if err := validateName(normalizeTarget(impl.Target), false); err != nil {
+			return invalid("bad target: %v", err)
+		}
+	case libdns.MX:
+		// A single dot is a null MX, stating the domain accepts no mail
+		if impl.Target != "." {
+			if err := validateName(normalizeTarget(impl.Target), false); err != nil {
+				return invalid("bad target: %v", err)
+			}
+		}
+	case libdns.NS:
+		if err := validateName(normalizeTarget(impl.Target), false); err != nil {
+			return invalid("bad target: %v", err)
+		}
+	case libdns.SRV:
+		if impl.Service == "" || impl.Transport == "" {
+			return invalid("missing service or transport")
+		}
+		if err := validateServiceName(impl.Service); err != nil {
+			return invalid("%v", err)
+		}
+		if !slices.Contains(validSRVTransports, strings.ToLower(impl.Transport)) {
+			return invalid("unsupported SRV transport %q, expected one of %v", impl.Transport, validSRVTransports)
+		}
+		// A single dot means the service is not available, in which case the port is irrelevant
+		if impl.Target != "." {
+			if impl.Port == 0 {
+				return invalid("port must be between 1 and 65535")
+			}
+			if err := validateName(normalizeTarget(impl.Target), false); err != nil {
+				return invalid("bad target: %v", err)
+			}
+		}
+	case libdns.RR:
+		if fields, ok := genericRDATA[strings.ToUpper(impl.Type)]; ok {
+			if _, ok := rdataParameters(impl.Type, impl.Data); !ok {
+				return invalid("malformed data %q, expected %d fields", impl.Data, len(fields))
+			}
+		}
+		switch strings.ToUpper(impl.Type) {
+		case "SSHFP":
+			if _, _, _, err := parseSSHFP(impl.Data); err != nil {
+				return invalid("%v", err)
+			}
+		case "DS":
+			if isApex(impl.Name) {
+				return invalid("DS records cannot be placed at the zone apex")
+			}
+			if _, err := parseDSRecord("", impl.Data); err != nil {
+				return invalid("%v", err)
+			}
+		case "TLSA":
+			if _, _, _, _, err := parseTLSA(impl.Data); err != nil {
+				return invalid("%v", err)
+			}
+		}
+	case DSRecord:
+		if isApex(impl.Name) {
+			return invalid("DS records cannot be placed at the zone apex")
+		}
+	case libdns.TXT:
+		if len(impl.Text) > maxTXTLength {
+			return invalid("text is %d characters long, at most %d are allowed", len(impl.Text), maxTXTLength)
+		}
+	}
+
+	return nil
+}
+
+// validateIodefURL checks the value of an iodef CAA record, which is where
+// CAs report invalid certificate requests to: a mailto, http or https URL.
+func validateIodefURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid iodef URL %q: %w", value, err)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "mailto":
+		if u.Opaque == "" {
+			return fmt.Errorf("iodef URL %q has no email address", value)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("iodef URL %q has no host", value)
+		}
+	default:
+		return fmt.Errorf("iodef URL %q must use the mailto, http or https scheme", value)
+	}
+
+	return nil
+}
+
+// validateServiceName checks the service of an SRV record, without its
+// leading underscore, against the service name syntax of RFC 6335.
+func validateServiceName(service string) error {
+	service = strings.TrimPrefix(service, "_")
+	if len(service) > 15 {
+		return fmt.Errorf("service %q is longer than 15 characters", service)
+	}
+	if strings.HasPrefix(service, "-") || strings.HasSuffix(service, "-") || strings.Contains(service, "--") {
+		return fmt.Errorf("service %q has misplaced hyphens", service)
+	}
+
+	for _, ch := range service {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-':
+		default:
+			return fmt.Errorf("service %q contains invalid character %q", service, ch)
+		}
+	}
+
+	return nil
+}
+
+// isApex reports whether a relative record name refers to the zone apex. It is
+// used for DS records, which belong to the parent side of a delegation.
+func isApex(name string) bool {
+	host := normalizeHost(name)
+	return host == "" || host == "@"
+}
+
+// validateName checks the syntax of a domain name. Record names may be empty
+// or "@" for the zone apex and may start with a wildcard label, targets may not.
+func validateName(name string, isOwner bool) error {
+	if isOwner && (name == "" || name == "@") {
+		return nil
+	}
+
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return errors.New("empty name")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("name is longer than %d characters", maxNameLength)
+	}
+
+	for idx, label := range strings.Split(name, ".") {
+		if label == "*" && idx == 0 && isOwner {
+			continue
+		}
+		if err := validateLabel(label); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateLabel(label string) error {
+	if label == "" {
+		return errors.New("empty label")
+	}
+	if len(label) > maxLabelLength {
+		return fmt.Errorf("label %q is longer than %d characters", label, maxLabelLength)
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+
+	for _, ch := range label {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-', ch == '_':
+		default:
+			return fmt.Errorf("label %q contains invalid character %q", label, ch)
+		}
+	}
+
+	return nil
+}
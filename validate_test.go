@@ -0,0 +1,185 @@
+package cloudns
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+var validateRecordTests = []struct {
+	name  string
+	in    libdns.Record
+	valid bool
+}{
+	{
+		name:  "apex address",
+		in:    libdns.Address{Name: "@", TTL: time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		valid: true,
+	},
+	{
+		name:  "wildcard TXT",
+		in:    libdns.TXT{Name: "*.sub", TTL: time.Minute, Text: "hello"},
+		valid: true,
+	},
+	{
+		name:  "underscored name",
+		in:    libdns.TXT{Name: "_acme-challenge", TTL: time.Minute, Text: "token"},
+		valid: true,
+	},
+	{
+		name:  "null MX",
+		in:    libdns.MX{Name: "@", TTL: time.Minute, Target: "."},
+		valid: true,
+	},
+	{
+		name:  "SRV",
+		in:    libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Port: 5060, Target: "sip.example.com."},
+		valid: true,
+	},
+	{
+		name:  "SSHFP",
+		in:    libdns.RR{Name: "ssh", TTL: time.Minute, Type: "SSHFP", Data: "4 2 123456789ABCDEF67890123456789ABCDEF67890123456789ABCDEF123456789"},
+		valid: true,
+	},
+	{
+		name: "SSHFP without fingerprint",
+		in:   libdns.RR{Name: "ssh", TTL: time.Minute, Type: "SSHFP", Data: "4 2"},
+	},
+	{
+		name:  "TLSA",
+		in:    libdns.RR{Name: "_443._tcp.www", TTL: time.Minute, Type: "TLSA", Data: "3 1 1 0C72AC70B745AC19998811B131D662C9AC69DBDBE7CB23E5B514B56664C5D3D6"},
+		valid: true,
+	},
+	{
+		name: "TLSA with invalid usage",
+		in:   libdns.RR{Name: "_443._tcp.www", TTL: time.Minute, Type: "TLSA", Data: "dane 1 1 0C72AC70"},
+	},
+	{
+		name:  "DS",
+		in:    libdns.RR{Name: "child", TTL: time.Hour, Type: "DS", Data: "2371 13 2 1F987CC6583E92DF0890718C42"},
+		valid: true,
+	},
+	{
+		name:  "typed DS",
+		in:    DSRecord{Name: "child", TTL: time.Hour, KeyTag: 2371, Algorithm: 13, DigestType: 2, Digest: "1F987CC6583E92DF0890718C42"},
+		valid: true,
+	},
+	{
+		name: "typed DS at the apex",
+		in:   DSRecord{Name: "@", TTL: time.Hour, KeyTag: 2371, Algorithm: 13, DigestType: 2, Digest: "1F987CC6583E92DF0890718C42"},
+	},
+	{
+		name: "DS at the apex",
+		in:   libdns.RR{Name: "@", TTL: time.Hour, Type: "DS", Data: "2371 13 2 1F987CC6583E92DF0890718C42"},
+	},
+	{
+		name:  "apex SRV",
+		in:    libdns.SRV{Service: "xmpp-client", Transport: "tcp", Name: "@", Priority: 5, Port: 5222, Target: "xmpp.example.com."},
+		valid: true,
+	},
+	{
+		name:  "generic SRV below a subdomain",
+		in:    libdns.RR{Name: "_sip._udp.voip.eu", TTL: time.Minute, Type: "SRV", Data: "10 0 5060 sip.example.com."},
+		valid: true,
+	},
+	{
+		name: "SRV with unknown transport",
+		in:   libdns.SRV{Service: "sip", Transport: "quic", Name: "@", Port: 5060, Target: "sip.example.com."},
+	},
+	{
+		name: "SRV with invalid service",
+		in:   libdns.SRV{Service: "sip_proxy", Transport: "udp", Name: "@", Port: 5060, Target: "sip.example.com."},
+	},
+	{
+		name: "invalid hostname",
+		in:   libdns.TXT{Name: "foo..bar", TTL: time.Minute, Text: "hello"},
+	},
+	{
+		name: "hyphen label",
+		in:   libdns.CNAME{Name: "-foo", TTL: time.Minute, Target: "example.com"},
+	},
+	{
+		name: "IPv6 in A record",
+		in:   libdns.RR{Name: "foo", TTL: time.Minute, Type: "A", Data: "2001:db8::1"},
+	},
+	{
+		name: "lowercase type with unparsable IP",
+		in:   libdns.RR{Name: "foo", TTL: time.Minute, Type: "a", Data: "foo"},
+	},
+	{
+		name:  "lowercase type",
+		in:    libdns.RR{Name: "foo", TTL: time.Minute, Type: "a", Data: "192.0.2.1"},
+		valid: true,
+	},
+	{
+		name: "unparsable IP",
+		in:   libdns.RR{Name: "foo", TTL: time.Minute, Type: "AAAA", Data: "foo"},
+	},
+	{
+		name: "SRV without port",
+		in:   libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", Target: "sip.example.com"},
+	},
+	{
+		name:  "critical CAA with quoted value",
+		in:    libdns.CAA{Name: "@", TTL: time.Minute, Flags: 128, Tag: "ISSUE", Value: `"letsencrypt.org; validationmethods=dns-01"`},
+		valid: true,
+	},
+	{
+		name:  "CAA forbidding issuance",
+		in:    libdns.CAA{Name: "@", TTL: time.Minute, Tag: "issuewild", Value: ";"},
+		valid: true,
+	},
+	{
+		name:  "iodef mailto",
+		in:    libdns.CAA{Name: "@", TTL: time.Minute, Tag: "iodef", Value: "mailto:security@example.com"},
+		valid: true,
+	},
+	{
+		name:  "iodef https",
+		in:    libdns.CAA{Name: "@", TTL: time.Minute, Tag: "iodef", Value: "https://example.com/caa-report"},
+		valid: true,
+	},
+	{
+		name: "iodef without scheme",
+		in:   libdns.CAA{Name: "@", TTL: time.Minute, Tag: "iodef", Value: "security@example.com"},
+	},
+	{
+		name: "CAA with unknown flags",
+		in:   libdns.CAA{Name: "@", TTL: time.Minute, Flags: 1, Tag: "issue", Value: "letsencrypt.org"},
+	},
+	{
+		name: "unknown CAA tag",
+		in:   libdns.CAA{Name: "@", TTL: time.Minute, Tag: "foo", Value: "letsencrypt.org"},
+	},
+	{
+		name: "CNAME without target",
+		in:   libdns.CNAME{Name: "foo", TTL: time.Minute},
+	},
+	{
+		name:  "DKIM key",
+		in:    libdns.TXT{Name: "selector._domainkey", TTL: time.Minute, Text: "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 392)},
+		valid: true,
+	},
+	{
+		name: "TXT exceeding the record data",
+		in:   libdns.TXT{Name: "foo", TTL: time.Minute, Text: strings.Repeat("A", maxTXTLength+1)},
+	},
+}
+
+func TestValidateRecord(t *testing.T) {
+	for _, tt := range validateRecordTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRecord(tt.in)
+			if tt.valid && err != nil {
+				t.Errorf("Expected record to be valid, got: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidRecord) {
+				t.Errorf("Expected ErrInvalidRecord, got: %v", err)
+			}
+		})
+	}
+}
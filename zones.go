@@ -0,0 +1,412 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GetZoneInfo returns the details of the zone, using get-zone-info.json.
+// Callers can use the zone type to tell apart zones whose records cannot be
+// managed through the API, such as slave zones.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the details of
+//
+// Returns:
+//   - Zone: The details of the zone
+//   - error: Any error that occurred during the operation, wrapping ErrZoneNotFound if the zone does not exist
+func (c *Client) GetZoneInfo(ctx context.Context, zone string) (Zone, error) {
+	endpoint := apiBaseUrl.JoinPath("get-zone-info.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	var result zoneResult
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return Zone{}, err
+	}
+	if result.Name == "" {
+		return Zone{}, fmt.Errorf("no details returned for zone %q: %w", zone, ErrZoneNotFound)
+	}
+
+	return result.toZone(), nil
+}
+
+// zoneExists reports whether the zone is managed by the account.
+func (c *Client) zoneExists(ctx context.Context, zone string) (bool, error) {
+	info, err := c.GetZoneInfo(ctx, zone)
+	if errors.Is(err, ErrZoneNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(info.Name, zone), nil
+}
+
+// FindZone returns the zone managed by the account that encloses the given
+// domain name, e.g. "example.co.uk." for "_acme-challenge.www.example.co.uk.".
+// It queries ClouDNS for each parent domain of the name in turn, starting with
+// the name itself, and returns the first one that is a zone of the account.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - fqdn: The fully qualified domain name to find the zone of
+//
+// Returns:
+//   - string: The enclosing zone, with a trailing dot if fqdn had one
+//   - error: Any error that occurred during the operation, wrapping ErrZoneNotFound if no zone matched
+func (c *Client) FindZone(ctx context.Context, fqdn string) (string, error) {
+	suffix := ""
+	if strings.HasSuffix(fqdn, ".") {
+		suffix = "."
+	}
+
+	labels := strings.Split(strings.TrimSuffix(fqdn, "."), ".")
+
+	// Top-level domains cannot be registered as zones, so stop at two labels
+	for idx := 0; idx < len(labels)-1; idx++ {
+		candidate := strings.Join(labels[idx:], ".")
+		exists, err := c.zoneExists(ctx, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up zone %q: %w", candidate, err)
+		}
+		if exists {
+			return candidate + suffix, nil
+		}
+	}
+
+	return "", fmt.Errorf("no zone found for %q: %w", fqdn, ErrZoneNotFound)
+}
+
+// ZoneType is the type of a ClouDNS zone.
+type ZoneType string
+
+const (
+	ZoneTypeMaster  ZoneType = "master"
+	ZoneTypeSlave   ZoneType = "slave"
+	ZoneTypeParked  ZoneType = "parked"
+	ZoneTypeGeoDNS  ZoneType = "geodns"
+	ZoneTypeReverse ZoneType = "reverse"
+)
+
+// CreateZoneOptions configures the zone created by CreateZone.
+type CreateZoneOptions struct {
+	// Type is the type of the zone, ZoneTypeMaster if empty
+	Type ZoneType
+
+	// NameServers are the hostnames of the ClouDNS nameservers to create NS
+	// records for, as returned by GetAvailableNameServers. If empty, the
+	// default nameservers of the account are used.
+	NameServers []string
+
+	// MasterIP is the IP address of the master server, required for slave zones
+	MasterIP netip.Addr
+}
+
+// CreateZone registers a new zone in the account, using register.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to register
+//   - opts: The type and nameservers of the zone
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) CreateZone(ctx context.Context, zone string, opts CreateZoneOptions) error {
+	zoneType := opts.Type
+	if zoneType == "" {
+		zoneType = ZoneTypeMaster
+	}
+	if zoneType == ZoneTypeSlave && !opts.MasterIP.IsValid() {
+		return fmt.Errorf("a master IP is required for slave zone %q", zone)
+	}
+
+	endpoint := apiBaseUrl.JoinPath("register.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"zone-type":   string(zoneType),
+	}
+	for idx, ns := range opts.NameServers {
+		params[fmt.Sprintf("ns[%d]", idx)] = ns
+	}
+	if opts.MasterIP.IsValid() {
+		params["master-ip"] = opts.MasterIP.String()
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// IsUpdated reports whether all the ClouDNS nameservers serve the latest
+// version of the zone, using is-updated.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to check
+//
+// Returns:
+//   - bool: Whether the zone is up to date on all nameservers
+//   - error: Any error that occurred during the operation
+func (c *Client) IsUpdated(ctx context.Context, zone string) (bool, error) {
+	endpoint := apiBaseUrl.JoinPath("is-updated.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	// Perform the API request
+	resp, err := c.performGetRequest(ctx, endpoint, params)
+	if err != nil {
+		return false, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check HTTP status code
+	if resp.StatusCode != http.StatusOK {
+		return false, newHTTPError(resp)
+	}
+
+	// The endpoint returns a plain boolean on success and a status object on failure
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	var updated bool
+	if err := c.decodeJSON(ctx, endpoint.Path, bodyBytes, &updated); err != nil {
+		var resultModel ApiResponse
+		if json.Unmarshal(bodyBytes, &resultModel) == nil && resultModel.Status != "" {
+			return false, newAPIError(resultModel.StatusDescription).from(resp)
+		}
+		return false, fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	return updated, nil
+}
+
+// TriggerZoneUpdate forces ClouDNS to bump the SOA serial of the zone and
+// notify its nameservers, using update-zone.json. This is useful after
+// changes made to the zone by other tooling.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to update
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) TriggerZoneUpdate(ctx context.Context, zone string) error {
+	endpoint := apiBaseUrl.JoinPath("update-zone.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// GetZoneNotes returns the free-form notes attached to the zone, using
+// get-notes.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to get the notes of
+//
+// Returns:
+//   - string: The notes of the zone, empty if there are none
+//   - error: Any error that occurred during the operation
+func (c *Client) GetZoneNotes(ctx context.Context, zone string) (string, error) {
+	endpoint := apiBaseUrl.JoinPath("get-notes.json")
+	params := map[string]string{
+		"domain-name": zone,
+	}
+
+	var result struct {
+		Notes string `json:"notes"`
+	}
+	if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+		return "", err
+	}
+
+	return result.Notes, nil
+}
+
+// SetZoneNotes replaces the free-form notes attached to the zone, using
+// update-notes.json. Automation can use them to record who manages a zone.
+// An empty string clears the notes.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to set the notes of
+//   - notes: The new notes of the zone
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) SetZoneNotes(ctx context.Context, zone string, notes string) error {
+	endpoint := apiBaseUrl.JoinPath("update-notes.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"notes":       notes,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// ListGroups returns the groups the zones of the account can be organized in,
+// using get-groups.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//
+// Returns:
+//   - []ZoneGroup: The groups of the account, sorted by name
+//   - error: Any error that occurred during the operation
+func (c *Client) ListGroups(ctx context.Context) ([]ZoneGroup, error) {
+	endpoint := apiBaseUrl.JoinPath("get-groups.json")
+
+	// Groups are returned keyed by their ID, like records
+	var result map[string]ZoneGroup
+	if err := c.performGetJSONRequest(ctx, endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+
+	groups := slices.Collect(maps.Values(result))
+	slices.SortFunc(groups, func(a, b ZoneGroup) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return groups, nil
+}
+
+// ChangeZoneGroup moves the zone into the group with the given ID, using
+// change-group.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - zone: The DNS zone (domain) to move
+//   - groupId: ID of the destination group
+//
+// Returns:
+//   - error: Any error that occurred during the operation
+func (c *Client) ChangeZoneGroup(ctx context.Context, zone string, groupId string) error {
+	endpoint := apiBaseUrl.JoinPath("change-group.json")
+	params := map[string]string{
+		"domain-name": zone,
+		"group-id":    groupId,
+	}
+
+	return c.performStatusRequest(ctx, endpoint, params)
+}
+
+// GetZoneStats returns the number of zones in the account along with the
+// limit of the ClouDNS plan, using get-zones-stats.json.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//
+// Returns:
+//   - ZoneStats: The zone usage of the account
+//   - error: Any error that occurred during the operation
+func (c *Client) GetZoneStats(ctx context.Context) (ZoneStats, error) {
+	endpoint := apiBaseUrl.JoinPath("get-zones-stats.json")
+
+	var result struct {
+		Count flexInt `json:"count"`
+		Limit flexInt `json:"limit"`
+	}
+	if err := c.performGetJSONRequest(ctx, endpoint, nil, &result); err != nil {
+		return ZoneStats{}, err
+	}
+
+	return ZoneStats{Count: int(result.Count), Limit: int(result.Limit)}, nil
+}
+
+// Zone holds the details of a zone of the account.
+type Zone struct {
+	Name   string
+	Type   ZoneType
+	Active bool
+
+	// Group is the group the zone belongs to, if any
+	Group string
+}
+
+// zoneResult is a zone as returned by the API.
+type zoneResult struct {
+	Name   string     `json:"name"`
+	Type   ZoneType   `json:"type"`
+	Status flexInt    `json:"status"`
+	Group  flexString `json:"group"`
+}
+
+func (z zoneResult) toZone() Zone {
+	return Zone{Name: z.Name, Type: z.Type, Active: z.Status == 1, Group: string(z.Group)}
+}
+
+// ListZonesOptions filters the zones returned by ListZones.
+type ListZonesOptions struct {
+	// Search only returns zones whose name contains the given string
+	Search string
+
+	// GroupId only returns zones of the group with the given ID
+	GroupId string
+
+	// RowsPerPage is the number of zones fetched per request. ClouDNS accepts
+	// 10, 20, 30, 50 and 100, and 100 is used for any other value.
+	RowsPerPage int
+}
+
+// ListZones returns the zones of the account matching the options, using
+// list-zones.json. All pages of results are fetched one after the other.
+//
+// Parameters:
+//   - ctx: Context for timeout and cancellation
+//   - opts: Filters for the zones to return
+//
+// Returns:
+//   - []Zone: The matching zones
+//   - error: Any error that occurred during the operation
+func (c *Client) ListZones(ctx context.Context, opts ListZonesOptions) ([]Zone, error) {
+	rowsPerPage := opts.RowsPerPage
+	if !slices.Contains(validRowsPerPage, rowsPerPage) {
+		rowsPerPage = 100
+	}
+
+	endpoint := apiBaseUrl.JoinPath("list-zones.json")
+	params := map[string]string{
+		"rows-per-page": strconv.Itoa(rowsPerPage),
+	}
+	if opts.Search != "" {
+		params["search"] = opts.Search
+	}
+	if opts.GroupId != "" {
+		params["group-id"] = opts.GroupId
+	}
+
+	var zones []Zone
+	for page := 1; ; page++ {
+		params["page"] = strconv.Itoa(page)
+
+		var result []zoneResult
+		if err := c.performGetJSONRequest(ctx, endpoint, params, &result); err != nil {
+			return nil, fmt.Errorf("failed to list zones on page %d: %w", page, err)
+		}
+
+		for _, zone := range result {
+			zones = append(zones, zone.toZone())
+		}
+
+		// A short page is the last one
+		if len(result) < rowsPerPage {
+			return zones, nil
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestFindZone(t *testing.T) {
+	var queried []string
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		zone := r.URL.Query().Get("domain-name")
+		queried = append(queried, zone)
+		if zone == "example.co.uk" {
+			fmt.Fprint(w, `{"name":"example.co.uk","type":"master","zone":"domain","status":"1"}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":"Failed","statusDescription":"Missing domain-name"}`)
+	})
+
+	c := UseClient("id", "", "password")
+	zone, err := c.FindZone(t.Context(), "_acme-challenge.www.example.co.uk.")
+	if err != nil {
+		t.Fatalf("Failed to find zone: %v", err)
+	}
+	if zone != "example.co.uk." {
+		t.Errorf("Expected zone example.co.uk., got %q", zone)
+	}
+	if len(queried) != 3 {
+		t.Errorf("Expected 3 lookups, got %v", queried)
+	}
+
+	queried = nil
+	if _, err := c.FindZone(t.Context(), "www.example.org"); err == nil {
+		t.Errorf("Expected an error for an unknown zone")
+	}
+	if len(queried) != 2 {
+		t.Errorf("Expected lookups to stop before the TLD, got %v", queried)
+	}
+}
+
+func TestRegisterMissingZones(t *testing.T) {
+	registered := false
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/register.json":
+			if zoneType := r.URL.Query().Get("zone-type"); zoneType != "master" {
+				t.Errorf("Unexpected zone type %q", zoneType)
+			}
+			registered = true
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"Domain zone example.com was created successfully."}`)
+		case "/dns/get-available-ttl.json":
+			if !registered {
+				fmt.Fprint(w, `{"status":"Failed","statusDescription":"Missing domain-name"}`)
+				return
+			}
+			fmt.Fprint(w, `[60,300,3600]`)
+		case "/dns/get-zone-info.json":
+			fmt.Fprint(w, `{"name":"example.com","type":"master","status":"1"}`)
+		case "/dns/get-available-record-types.json":
+			fmt.Fprint(w, `["A","AAAA","CNAME","TXT"]`)
+		case "/dns/add-record.json":
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The record was added successfully.","data":{"id":1}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	record := libdns.TXT{Name: "test", TTL: 300 * time.Second, Text: "hello"}
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password"}
+	if _, err := provider.AppendRecords(t.Context(), "example.com", []libdns.Record{record}); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound without RegisterMissingZones, got %v", err)
+	}
+
+	provider.RegisterMissingZones = true
+	added, err := provider.AppendRecords(t.Context(), "example.com", []libdns.Record{record})
+	if err != nil {
+		t.Fatalf("Failed to append records: %v", err)
+	}
+	if !registered || len(added) != 1 {
+		t.Errorf("Expected the zone to be registered and the record added, got %v", added)
+	}
+}
+
+func TestWaitForPropagation(t *testing.T) {
+	calls := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/dns/is-updated.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprint(w, calls >= 3)
+	})
+
+	provider := &Provider{AuthId: "id", AuthPassword: "password", InitialBackoff: time.Millisecond}
+	if err := provider.WaitForPropagation(t.Context(), "example.com."); err != nil {
+		t.Fatalf("Failed to wait for propagation: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 polls, got %d", calls)
+	}
+}
+
+func TestTriggerZoneUpdate(t *testing.T) {
+	status := `{"status":"Success","statusDescription":"The zone was updated successfully."}`
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/update-zone.json" || r.Method != http.MethodPost {
+			t.Errorf("Unexpected request %s %q", r.Method, r.URL.Path)
+		}
+		if zone := r.URL.Query().Get("domain-name"); zone != "example.com" {
+			t.Errorf("Unexpected zone %q", zone)
+		}
+		fmt.Fprint(w, status)
+	})
+
+	c := UseClient("id", "", "password")
+	if err := c.TriggerZoneUpdate(t.Context(), "example.com"); err != nil {
+		t.Fatalf("Failed to trigger zone update: %v", err)
+	}
+
+	status = `{"status":"Failed","statusDescription":"The zone is updating, try again later."}`
+	err := c.TriggerZoneUpdate(t.Context(), "example.com")
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || apiErr.StatusDescription != "The zone is updating, try again later." {
+		t.Errorf("Expected the failure reported by the API, got %v", err)
+	}
+}
+
+func TestZoneNotes(t *testing.T) {
+	notes := ""
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/get-notes.json":
+			fmt.Fprintf(w, `{"notes":%q}`, notes)
+		case "/dns/update-notes.json":
+			notes = r.URL.Query().Get("notes")
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The notes were updated successfully."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	c := UseClient("id", "", "password")
+	if err := c.SetZoneNotes(t.Context(), "example.com", "owned by team-dns"); err != nil {
+		t.Fatalf("Failed to set notes: %v", err)
+	}
+
+	got, err := c.GetZoneNotes(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	if got != "owned by team-dns" {
+		t.Errorf("Expected notes to round trip, got %q", got)
+	}
+}
+
+func TestZoneGroups(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/get-groups.json":
+			fmt.Fprint(w, `{"2":{"id":"2","name":"customers"},"1":{"id":"1","name":"internal"}}`)
+		case "/dns/change-group.json":
+			if groupId := r.URL.Query().Get("group-id"); groupId != "2" {
+				t.Errorf("Unexpected group ID %q", groupId)
+			}
+			fmt.Fprint(w, `{"status":"Success","statusDescription":"The group was changed successfully."}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+	})
+
+	c := UseClient("id", "", "password")
+	groups, err := c.ListGroups(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to list groups: %v", err)
+	}
+	expected := []ZoneGroup{{Id: "2", Name: "customers"}, {Id: "1", Name: "internal"}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("actual: %+v\n\nexpected: %+v", groups, expected)
+	}
+
+	if err := c.ChangeZoneGroup(t.Context(), "example.com", groups[0].Id); err != nil {
+		t.Errorf("Failed to change group: %v", err)
+	}
+}
+
+func TestGetZoneStats(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"count":"9","limit":10}`)
+	})
+
+	stats, err := UseClient("id", "", "password").GetZoneStats(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get zone stats: %v", err)
+	}
+	if stats != (ZoneStats{Count: 9, Limit: 10}) || stats.Remaining() != 1 {
+		t.Errorf("Unexpected zone stats %+v", stats)
+	}
+}
+
+func TestCreateZone(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("zone-type") != "master" || query.Get("ns[0]") != "pns1.cloudns.net" || query.Get("ns[1]") != "pns2.cloudns.net" {
+			t.Errorf("Unexpected registration parameters %v", query)
+		}
+		fmt.Fprint(w, `{"status":"Success","statusDescription":"Domain zone example.com was created successfully."}`)
+	})
+
+	c := UseClient("id", "", "password")
+	err := c.CreateZone(t.Context(), "example.com", CreateZoneOptions{NameServers: []string{"pns1.cloudns.net", "pns2.cloudns.net"}})
+	if err != nil {
+		t.Errorf("Failed to create zone: %v", err)
+	}
+
+	if err := c.CreateZone(t.Context(), "example.com", CreateZoneOptions{Type: ZoneTypeSlave}); err == nil {
+		t.Errorf("Expected an error for a slave zone without master IP")
+	}
+}
+
+func TestListZones(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("rows-per-page") != "10" || query.Get("search") != "example" {
+			t.Errorf("Unexpected list parameters %v", query)
+		}
+		switch query.Get("page") {
+		case "1":
+			fmt.Fprint(w, `[`)
+			for idx := range 10 {
+				if idx > 0 {
+					fmt.Fprint(w, `,`)
+				}
+				fmt.Fprintf(w, `{"name":"example%d.com","type":"master","zone":"domain","status":"1"}`, idx)
+			}
+			fmt.Fprint(w, `]`)
+		case "2":
+			fmt.Fprint(w, `[{"name":"example.net","type":"slave","zone":"domain","status":"0"}]`)
+		default:
+			t.Errorf("Unexpected page %q", query.Get("page"))
+		}
+	})
+
+	zones, err := UseClient("id", "", "password").ListZones(t.Context(), ListZonesOptions{Search: "example", RowsPerPage: 10})
+	if err != nil {
+		t.Fatalf("Failed to list zones: %v", err)
+	}
+	if len(zones) != 11 {
+		t.Fatalf("Expected 11 zones, got %d", len(zones))
+	}
+	if last := zones[10]; last != (Zone{Name: "example.net", Type: ZoneTypeSlave, Active: false}) || !zones[0].Active {
+		t.Errorf("Unexpected zones %+v", zones)
+	}
+}
+
+func TestListZonesPageSize(t *testing.T) {
+	for _, rowsPerPage := range []int{0, -10, 25, 1000} {
+		t.Run(fmt.Sprint(rowsPerPage), func(t *testing.T) {
+			useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if rows := r.URL.Query().Get("rows-per-page"); rows != "100" {
+					t.Errorf("Expected the default page size, got %q", rows)
+				}
+				fmt.Fprint(w, `[{"name":"example.com","type":"master","zone":"domain","status":"1"}]`)
+			})
+
+			zones, err := UseClient("id", "", "password").ListZones(t.Context(), ListZonesOptions{RowsPerPage: rowsPerPage})
+			if err != nil {
+				t.Fatalf("Failed to list zones: %v", err)
+			}
+			if len(zones) != 1 {
+				t.Errorf("Expected 1 zone, got %+v", zones)
+			}
+		})
+	}
+}
+
+func TestGetZoneInfo(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"example.com","type":"slave","zone":"domain","status":"1","group":7}`)
+	})
+
+	info, err := UseClient("id", "", "password").GetZoneInfo(t.Context(), "example.com")
+	if err != nil {
+		t.Fatalf("Failed to get zone info: %v", err)
+	}
+
+	expected := Zone{Name: "example.com", Type: ZoneTypeSlave, Active: true, Group: "7"}
+	if info != expected {
+		t.Errorf("Expected %+v, got %+v", expected, info)
+	}
+}